@@ -2,11 +2,13 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"github.com/Enthreeka/tg-posting-bot/internal/config"
 	"github.com/Enthreeka/tg-posting-bot/internal/handler/callback"
 	"github.com/Enthreeka/tg-posting-bot/internal/handler/middleware"
 	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot"
 	"github.com/Enthreeka/tg-posting-bot/internal/handler/view"
+	"github.com/Enthreeka/tg-posting-bot/internal/leader"
 	"github.com/Enthreeka/tg-posting-bot/internal/repo"
 	"github.com/Enthreeka/tg-posting-bot/internal/scheduled"
 	"github.com/Enthreeka/tg-posting-bot/internal/usecase"
@@ -23,28 +25,51 @@ const (
 )
 
 type Bot struct {
-	bot              *tgbotapi.BotAPI
-	psql             *postgres.Postgres
-	store            *store.Store
-	cfg              *config.Config
-	log              *logger.Logger
-	tgMsg            *customMsg.TelegramMsg
-	callbackStore    *store.CallbackStorage
-	publicationArray *store.PublicationArray
+	bot           *tgbotapi.BotAPI
+	psql          *postgres.Postgres
+	store         *store.Store
+	cfg           *config.Config
+	log           *logger.Logger
+	tgMsg         *customMsg.TelegramMsg
+	callbackStore *store.CallbackStorage
 
 	userService        service.UserService
 	channelService     service.ChannelService
 	publicationService service.PublicationService
+	jobService         service.JobService
+	leaseService       service.LeaseService
+	seriesService      service.SeriesService
+	windowService      service.PostingWindowService
+	queueService       service.QueueService
+	targetService      service.TargetService
+	groupService       service.GroupService
+	importService      service.ImportService
+	exportService      service.ExportService
 
 	publicationSchedule scheduled.Schedule
+	elector             leader.Elector
 
 	userRepo        repo.UserRepo
 	channelRepo     repo.ChannelRepo
 	publicationRepo repo.PublicationRepo
+	jobRepo         repo.JobRepo
+	leaseRepo       repo.LeaseRepo
+	occurrenceRepo  repo.OccurrenceRepo
+	blackoutRepo    repo.BlackoutRepo
+	targetRepo      repo.TargetRepo
+	importRepo      repo.ImportRepo
 
 	callbackUser        callback.CallbackUser
 	callbackChannel     callback.CallbackChannel
 	callbackPublication callback.PublicationChannel
+	callbackSeries      callback.CallbackSeries
+	callbackWindow      callback.CallbackWindow
+	callbackQueue       callback.CallbackQueue
+	callbackTarget      callback.CallbackTarget
+	callbackGroup       callback.CallbackGroup
+	callbackImport      callback.CallbackImport
+	callbackExport      callback.CallbackExport
+	callbackDatePicker  callback.CallbackDatePicker
 
 	viewGeneral *view.ViewGeneral
 }
@@ -54,7 +79,7 @@ func NewBot() *Bot {
 }
 
 func (b *Bot) initHandler() {
-	b.viewGeneral = view.NewViewGeneral(b.log, b.tgMsg)
+	b.viewGeneral = view.NewViewGeneral(b.log, b.tgMsg, b.leaseService)
 
 	callbackUser, err := callback.NewCallbackUser(b.userService, b.log, b.store, b.tgMsg)
 	if err != nil {
@@ -68,12 +93,60 @@ func (b *Bot) initHandler() {
 	}
 	b.callbackChannel = callbackChannel
 
-	callbackPublication, err := callback.NewCallbackPublication(b.publicationService, b.log, b.tgMsg, b.store, b.channelService, b.publicationArray)
+	callbackPublication, err := callback.NewCallbackPublication(b.publicationService, b.log, b.tgMsg, b.store, b.channelService, b.jobService, b.targetService)
 	if err != nil {
 		b.log.Fatal("callbackPublication: ", err)
 	}
 	b.callbackPublication = callbackPublication
 
+	callbackSeries, err := callback.NewCallbackSeries(b.seriesService, b.log, b.tgMsg, b.store)
+	if err != nil {
+		b.log.Fatal("NewCallbackSeries: ", err)
+	}
+	b.callbackSeries = callbackSeries
+
+	callbackWindow, err := callback.NewCallbackWindow(b.windowService, b.publicationService, b.jobService, b.log, b.tgMsg, b.store)
+	if err != nil {
+		b.log.Fatal("NewCallbackWindow: ", err)
+	}
+	b.callbackWindow = callbackWindow
+
+	callbackQueue, err := callback.NewCallbackQueue(b.queueService, b.log, b.tgMsg, b.store)
+	if err != nil {
+		b.log.Fatal("NewCallbackQueue: ", err)
+	}
+	b.callbackQueue = callbackQueue
+
+	callbackTarget, err := callback.NewCallbackTarget(b.targetService, b.log, b.tgMsg, b.store)
+	if err != nil {
+		b.log.Fatal("NewCallbackTarget: ", err)
+	}
+	b.callbackTarget = callbackTarget
+
+	callbackGroup, err := callback.NewCallbackGroup(b.channelService, b.log, b.tgMsg, b.store)
+	if err != nil {
+		b.log.Fatal("NewCallbackGroup: ", err)
+	}
+	b.callbackGroup = callbackGroup
+
+	callbackImport, err := callback.NewCallbackImport(b.importService, b.log, b.tgMsg, b.store)
+	if err != nil {
+		b.log.Fatal("NewCallbackImport: ", err)
+	}
+	b.callbackImport = callbackImport
+
+	callbackExport, err := callback.NewCallbackExport(b.exportService, b.log, b.tgMsg, b.store)
+	if err != nil {
+		b.log.Fatal("NewCallbackExport: ", err)
+	}
+	b.callbackExport = callbackExport
+
+	callbackDatePicker, err := callback.NewCallbackDatePicker(b.publicationService, b.windowService, b.jobService, b.targetService, b.log, b.tgMsg, b.store)
+	if err != nil {
+		b.log.Fatal("NewCallbackDatePicker: ", err)
+	}
+	b.callbackDatePicker = callbackDatePicker
+
 	b.log.Info("Initializing handler")
 }
 
@@ -96,6 +169,60 @@ func (b *Bot) initUsecase() {
 	}
 	b.publicationService = publicationService
 
+	jobService, err := service.NewJobService(b.jobRepo, b.log)
+	if err != nil {
+		b.log.Fatal("NewJobService:", err)
+	}
+	b.jobService = jobService
+
+	leaseService, err := service.NewLeaseService(b.leaseRepo, b.log)
+	if err != nil {
+		b.log.Fatal("NewLeaseService:", err)
+	}
+	b.leaseService = leaseService
+
+	seriesService, err := service.NewSeriesService(b.publicationRepo, b.occurrenceRepo, b.jobService, b.log)
+	if err != nil {
+		b.log.Fatal("NewSeriesService:", err)
+	}
+	b.seriesService = seriesService
+
+	windowService, err := service.NewPostingWindowService(b.channelRepo, b.blackoutRepo, b.log)
+	if err != nil {
+		b.log.Fatal("NewPostingWindowService:", err)
+	}
+	b.windowService = windowService
+
+	queueService, err := service.NewQueueService(b.channelRepo, b.publicationRepo, b.windowService, b.jobService, b.log)
+	if err != nil {
+		b.log.Fatal("NewQueueService:", err)
+	}
+	b.queueService = queueService
+
+	targetService, err := service.NewTargetService(b.targetRepo, b.publicationRepo, b.channelRepo, b.jobService, b.log)
+	if err != nil {
+		b.log.Fatal("NewTargetService:", err)
+	}
+	b.targetService = targetService
+
+	groupService, err := service.NewGroupService(b.channelRepo, b.publicationRepo, b.targetService, b.jobService, b.log)
+	if err != nil {
+		b.log.Fatal("NewGroupService:", err)
+	}
+	b.groupService = groupService
+
+	importService, err := service.NewImportService(b.importRepo, b.jobService, b.log)
+	if err != nil {
+		b.log.Fatal("NewImportService:", err)
+	}
+	b.importService = importService
+
+	exportService, err := service.NewExportService(b.publicationRepo, b.channelRepo, b.log)
+	if err != nil {
+		b.log.Fatal("NewExportService:", err)
+	}
+	b.exportService = exportService
+
 	b.log.Info("Initializing usecase")
 }
 
@@ -119,11 +246,47 @@ func (b *Bot) initRepo() {
 	}
 	b.publicationRepo = publicationRepo
 
+	jobRepo, err := repo.NewJobRepo(b.psql)
+	if err != nil {
+		b.log.Fatal("NewJobRepo: ", err)
+	}
+	b.jobRepo = jobRepo
+
+	leaseRepo, err := repo.NewLeaseRepo(b.psql)
+	if err != nil {
+		b.log.Fatal("NewLeaseRepo: ", err)
+	}
+	b.leaseRepo = leaseRepo
+
+	occurrenceRepo, err := repo.NewOccurrenceRepo(b.psql)
+	if err != nil {
+		b.log.Fatal("NewOccurrenceRepo: ", err)
+	}
+	b.occurrenceRepo = occurrenceRepo
+
+	blackoutRepo, err := repo.NewBlackoutRepo(b.psql)
+	if err != nil {
+		b.log.Fatal("NewBlackoutRepo: ", err)
+	}
+	b.blackoutRepo = blackoutRepo
+
+	targetRepo, err := repo.NewTargetRepo(b.psql)
+	if err != nil {
+		b.log.Fatal("NewTargetRepo: ", err)
+	}
+	b.targetRepo = targetRepo
+
+	importRepo, err := repo.NewImportRepo(b.psql)
+	if err != nil {
+		b.log.Fatal("NewImportRepo: ", err)
+	}
+	b.importRepo = importRepo
+
 	b.log.Info("Initializing repo")
 }
 
 func (b *Bot) initMessage() {
-	b.tgMsg = customMsg.NewMessageSetting(b.bot, b.log)
+	b.tgMsg = customMsg.NewMessageSetting(b.bot, b.log, customMsg.NewLimiter(), b.cfg.Telegram.StorageChannelID)
 
 	b.log.Info("Initializing message")
 }
@@ -178,25 +341,36 @@ func (b *Bot) initTelegramBot() {
 	b.log.Info("Authorized on account %s", bot.Self.UserName)
 }
 
-func (b *Bot) initScheduled(ctx context.Context) {
-	publicationSchedule, err := scheduled.NewSchedule(b.publicationService, b.tgMsg, b.publicationArray, b.log)
+func (b *Bot) initScheduled() {
+	publicationSchedule, err := scheduled.NewSchedule(b.publicationService, b.userService, b.jobService, b.seriesService, b.windowService, b.targetService,
+		b.tgMsg.WithPriority(customMsg.PriorityHigh), b.log)
 	if err != nil {
 		b.log.Fatal("NewSchedule: %v", err)
 	}
 	b.publicationSchedule = publicationSchedule
 
-	if err = publicationSchedule.LoadDatabaseInPubDelStore(ctx); err != nil {
-		b.log.Fatal("LoadDatabaseInPubDelStore: %v", err)
+	b.log.Info("Initializing scheduled")
+}
+
+// runScheduled - планировщик запускается только на лидере
+func (b *Bot) runScheduled(ctx context.Context) {
+	if err := b.publicationSchedule.CatchUpOverdue(ctx); err != nil {
+		b.log.Error("CatchUpOverdue: %v", err)
 	}
-	go publicationSchedule.StartPub(ctx)
-	go publicationSchedule.StartDel(ctx)
+	go b.publicationSchedule.StartPub(ctx)
+	go b.publicationSchedule.StartDel(ctx)
 
-	b.log.Info("Initializing scheduled")
+	b.log.Info("Running scheduled")
 }
 
-func (b *Bot) initStoreScheduled() {
-	b.publicationArray = store.NewSortPublication(200)
-	b.log.Info("Initializing store scheduled")
+func (b *Bot) initElector() {
+	elector, err := leader.NewElector(b.leaseService, b.log, b.cfg.Leader.InstanceID, b.cfg.Leader.LeaseTTL)
+	if err != nil {
+		b.log.Fatal("NewElector: %v", err)
+	}
+	b.elector = elector
+
+	b.log.Info("Initializing elector, instance %s", elector.InstanceID())
 }
 
 func (b *Bot) initialize(ctx context.Context) {
@@ -204,20 +378,20 @@ func (b *Bot) initialize(ctx context.Context) {
 	b.initConfig()
 	b.initTelegramBot()
 	b.initStore()
-	b.initStoreScheduled()
 	b.initCallbackStorage()
 	b.initPostgres(ctx)
 	b.initMessage()
 	b.initRepo()
 	b.initUsecase()
 	b.initHandler()
-	b.initScheduled(ctx)
+	b.initScheduled()
+	b.initElector()
 }
 
 func (b *Bot) Run(ctx context.Context) {
 	startBot := time.Now()
 	b.initialize(ctx)
-	newBot, err := tgbot.NewBot(b.bot, b.log, b.store, b.tgMsg, b.userService, b.channelService, b.publicationService, b.callbackStore, b.publicationArray)
+	newBot, err := tgbot.NewBot(b.bot, b.log, b.store, b.tgMsg, b.userService, b.channelService, b.publicationService, b.callbackStore, b.jobService, b.seriesService, b.windowService, b.queueService, b.targetService, b.groupService, b.importService, b.exportService)
 	if err != nil {
 		b.log.Fatal("failed go create new bot: ", err)
 	}
@@ -227,6 +401,8 @@ func (b *Bot) Run(ctx context.Context) {
 
 	// user domain
 	newBot.RegisterCommandCallback("main_menu", middleware.AdminMiddleware(b.userService, b.callbackUser.MainMenu()))
+	newBot.RegisterCommandCallback("user_timezone", middleware.AdminMiddleware(b.userService, b.callbackUser.UpdateTimezone()))
+	newBot.RegisterCommandCallback("cluster_status", middleware.AdminMiddleware(b.userService, b.viewGeneral.CallbackClusterStatus()))
 	newBot.RegisterCommandCallback("user_setting", middleware.AdminMiddleware(b.userService, b.callbackUser.AdminRoleSetting()))
 	newBot.RegisterCommandCallback("admin_look_up", middleware.AdminMiddleware(b.userService, b.callbackUser.AdminLookUp()))
 	newBot.RegisterCommandCallback("admin_delete_role", middleware.AdminMiddleware(b.userService, b.callbackUser.AdminDeleteRole()))
@@ -237,6 +413,30 @@ func (b *Bot) Run(ctx context.Context) {
 	newBot.RegisterCommandCallback("channel_get", middleware.AdminMiddleware(b.userService, b.callbackChannel.CallbackGetChannel()))
 	newBot.RegisterCommandCallback("back_setting", middleware.AdminMiddleware(b.userService, b.callbackChannel.CallbackGetChannel()))
 	newBot.RegisterCommandCallback("cancel_create", middleware.AdminMiddleware(b.userService, b.callbackChannel.CallbackCancelCreate()))
+	newBot.RegisterCommandCallback("catchup_update", middleware.AdminMiddleware(b.userService, b.callbackChannel.CallbackUpdateCatchUpPolicy()))
+	newBot.RegisterCommandCallback("lateness_update", middleware.AdminMiddleware(b.userService, b.callbackChannel.CallbackUpdateMaxLateness()))
+	newBot.RegisterCommandCallback("attempts_update", middleware.AdminMiddleware(b.userService, b.callbackChannel.CallbackUpdateMaxAttempts()))
+	newBot.RegisterCommandCallback("timezone_update", middleware.AdminMiddleware(b.userService, b.callbackChannel.CallbackUpdateTimezone()))
+	newBot.RegisterCommandCallback("window_update", middleware.AdminMiddleware(b.userService, b.callbackWindow.CallbackUpdateWindows()))
+	newBot.RegisterCommandCallback("window_accept", middleware.AdminMiddleware(b.userService, b.callbackWindow.CallbackAcceptSlot()))
+	newBot.RegisterCommandCallback("blackout_get", middleware.AdminMiddleware(b.userService, b.callbackWindow.CallbackGetBlackouts()))
+	newBot.RegisterCommandCallback("blackout_add", middleware.AdminMiddleware(b.userService, b.callbackWindow.CallbackAddBlackout()))
+	newBot.RegisterCommandCallback("blackout_delete", middleware.AdminMiddleware(b.userService, b.callbackWindow.CallbackDeleteBlackout()))
+	newBot.RegisterCommandCallback("queue_add", middleware.AdminMiddleware(b.userService, b.callbackQueue.CallbackAddToQueue()))
+	newBot.RegisterCommandCallback("queue_shift", middleware.AdminMiddleware(b.userService, b.callbackQueue.CallbackShiftQueue()))
+	newBot.RegisterCommandCallback("queue_keep", middleware.AdminMiddleware(b.userService, b.callbackQueue.CallbackKeepGap()))
+	newBot.RegisterCommandCallback("target_get", middleware.AdminMiddleware(b.userService, b.callbackTarget.CallbackGetTargets()))
+	newBot.RegisterCommandCallback("target_toggle", middleware.AdminMiddleware(b.userService, b.callbackTarget.CallbackToggleTarget()))
+	newBot.RegisterCommandCallback("target_offset", middleware.AdminMiddleware(b.userService, b.callbackTarget.CallbackUpdateTargetOffset()))
+	newBot.RegisterCommandCallback("group_target", middleware.AdminMiddleware(b.userService, b.callbackTarget.CallbackAddGroup()))
+	newBot.RegisterCommandCallback("group_list", middleware.AdminMiddleware(b.userService, b.callbackGroup.CallbackGetGroups()))
+	newBot.RegisterCommandCallback("group_create", middleware.AdminMiddleware(b.userService, b.callbackGroup.CallbackCreateGroup()))
+	newBot.RegisterCommandCallback("group_get", middleware.AdminMiddleware(b.userService, b.callbackGroup.CallbackGetGroup()))
+	newBot.RegisterCommandCallback("group_delete", middleware.AdminMiddleware(b.userService, b.callbackGroup.CallbackDeleteGroup()))
+	newBot.RegisterCommandCallback("group_member", middleware.AdminMiddleware(b.userService, b.callbackGroup.CallbackToggleMember()))
+	newBot.RegisterCommandCallback("group_broadcast", middleware.AdminMiddleware(b.userService, b.callbackGroup.CallbackBroadcast()))
+	newBot.RegisterCommandCallback("group_reschedule", middleware.AdminMiddleware(b.userService, b.callbackGroup.CallbackReschedule()))
+	newBot.RegisterCommandCallback("slots_update", middleware.AdminMiddleware(b.userService, b.callbackQueue.CallbackUpdateSlots()))
 
 	// publication domain
 	newBot.RegisterCommandCallback("publication_create", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackCreatePublication()))
@@ -244,17 +444,68 @@ func (b *Bot) Run(ctx context.Context) {
 	newBot.RegisterCommandCallback("publication_get", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackGetPublicationGet()))
 	newBot.RegisterCommandCallback("text_update", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackUpdatePublicationText()))
 	newBot.RegisterCommandCallback("image_update", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackUpdatePublicationImage()))
-	newBot.RegisterCommandCallback("buttontext_update", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackUpdatePublicationButtonText()))
-	newBot.RegisterCommandCallback("buttonlink_update", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackUpdatePublicationButtonLink()))
-	newBot.RegisterCommandCallback("sent-date_update", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackUpdatePublicationSentDate()))
-	newBot.RegisterCommandCallback("delete-date_update", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackUpdatePublicationDeleteDate()))
+	newBot.RegisterCommandCallback("buttons_get", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackGetButtons()))
+	newBot.RegisterCommandCallback("buttons_add", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackAddButtons()))
+	newBot.RegisterCommandCallback("button_del", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackDeleteButton()))
+	newBot.RegisterCommandCallback("buttons_up", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackMoveButtonRow()))
+	newBot.RegisterCommandCallback("options_get", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackGetOptions()))
+	newBot.RegisterCommandCallback("opt_silent", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackToggleOption()))
+	newBot.RegisterCommandCallback("opt_protect", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackToggleOption()))
+	newBot.RegisterCommandCallback("opt_spoiler", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackToggleOption()))
+	newBot.RegisterCommandCallback("opt_split", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackToggleOption()))
+	newBot.RegisterCommandCallback("opt_preview", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackToggleOption()))
+	newBot.RegisterCommandCallback("opt_above", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackToggleOption()))
+	newBot.RegisterCommandCallback("opt_size", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackToggleOption()))
+	newBot.RegisterCommandCallback("opt_url", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackUpdatePreviewURL()))
+	newBot.RegisterCommandCallback("buttons_down", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackMoveButtonRow()))
+	newBot.RegisterCommandCallback("sent-date_update", middleware.AdminMiddleware(b.userService, b.callbackDatePicker.CallbackUpdatePublicationSentDate()))
+	newBot.RegisterCommandCallback("delete-date_update", middleware.AdminMiddleware(b.userService, b.callbackDatePicker.CallbackUpdatePublicationDeleteDate()))
+	newBot.RegisterCommandCallback("dp_month", middleware.AdminMiddleware(b.userService, b.callbackDatePicker.CallbackDatePickerMonth()))
+	newBot.RegisterCommandCallback("dp_day", middleware.AdminMiddleware(b.userService, b.callbackDatePicker.CallbackDatePickerDay()))
+	newBot.RegisterCommandCallback("dp_set", middleware.AdminMiddleware(b.userService, b.callbackDatePicker.CallbackDatePickerSet()))
+	newBot.RegisterCommandCallback("dp_none", middleware.AdminMiddleware(b.userService, b.callbackDatePicker.CallbackDatePickerNone()))
 	newBot.RegisterCommandCallback("check_publication", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackCheckPublication()))
+	newBot.RegisterCommandCallback("import_start", middleware.AdminMiddleware(b.userService, b.callbackImport.CallbackStartImport()))
+	newBot.RegisterCommandCallback("import_confirm", middleware.AdminMiddleware(b.userService, b.callbackImport.CallbackConfirmImport()))
+	newBot.RegisterCommandCallback("import_cancel", middleware.AdminMiddleware(b.userService, b.callbackImport.CallbackCancelImport()))
+
+	newBot.RegisterCommandCallback("export_get", middleware.AdminMiddleware(b.userService, b.callbackExport.CallbackGetExport()))
+	newBot.RegisterCommandCallback("export_panel", middleware.AdminMiddleware(b.userService, b.callbackExport.CallbackExportPanel()))
+	newBot.RegisterCommandCallback("export_range", middleware.AdminMiddleware(b.userService, b.callbackExport.CallbackExportRange()))
+	newBot.RegisterCommandCallback("export_status", middleware.AdminMiddleware(b.userService, b.callbackExport.CallbackExportStatus()))
+	newBot.RegisterCommandCallback("export_input", middleware.AdminMiddleware(b.userService, b.callbackExport.CallbackExportInput()))
+	newBot.RegisterCommandCallback("export_file", middleware.AdminMiddleware(b.userService, b.callbackExport.CallbackExportFile()))
 	newBot.RegisterCommandCallback("publication_cancel", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackGetListForCancelPublication()))
 	newBot.RegisterCommandCallback("publication_delete", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackDeletePublication()))
+	newBot.RegisterCommandCallback("pub_list", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackGetPublicationList()))
+	newBot.RegisterCommandCallback("pub_search", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackSearchPublication()))
 	newBot.RegisterCommandCallback("cancel_update", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackCancelUpdate()))
+	newBot.RegisterCommandCallback("overdue_send", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackOverduePublication()))
+	newBot.RegisterCommandCallback("overdue_reschedule", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackOverduePublication()))
+	newBot.RegisterCommandCallback("overdue_drop", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackOverduePublication()))
+	newBot.RegisterCommandCallback("overduedel_send", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackOverdueDelete()))
+	newBot.RegisterCommandCallback("overduedel_drop", middleware.AdminMiddleware(b.userService, b.callbackPublication.CallbackOverdueDelete()))
+
+	// series domain
+	newBot.RegisterCommandCallback("series_get", middleware.AdminMiddleware(b.userService, b.callbackSeries.CallbackGetSeries()))
+	newBot.RegisterCommandCallback("series_rule", middleware.AdminMiddleware(b.userService, b.callbackSeries.CallbackUpdateSeriesRule()))
+	newBot.RegisterCommandCallback("series_pause", middleware.AdminMiddleware(b.userService, b.callbackSeries.CallbackPauseSeries()))
+	newBot.RegisterCommandCallback("series_resume", middleware.AdminMiddleware(b.userService, b.callbackSeries.CallbackResumeSeries()))
+	newBot.RegisterCommandCallback("series_skip", middleware.AdminMiddleware(b.userService, b.callbackSeries.CallbackSkipOccurrence()))
+	newBot.RegisterCommandCallback("series_end", middleware.AdminMiddleware(b.userService, b.callbackSeries.CallbackEndSeries()))
 
 	b.log.Info("Initialize bot took [%f] seconds", time.Since(startBot).Seconds())
-	if err := newBot.Run(ctx); err != nil {
+
+	// только лидер получает обновления и запускает планировщик, остальные экземпляры ждут истечения аренды
+	err = b.elector.Run(ctx, func(leaderCtx context.Context) {
+		b.runScheduled(leaderCtx)
+		if err := newBot.Run(leaderCtx); err != nil && !errors.Is(err, context.Canceled) {
+			b.log.Error("failed to run Telegram Bot: %v", err)
+		}
+	})
+	// получение обновлений нельзя перезапустить в том же процессе, поэтому при потере лидерства
+	// завершаемся и перезапускаемся как резервный экземпляр
+	if errors.Is(err, leader.ErrLeadershipLost) {
 		b.log.Fatal("failed to run Telegram Bot: %v", err)
 	}
 }
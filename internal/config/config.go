@@ -3,12 +3,17 @@ package config
 import (
 	"github.com/joho/godotenv"
 	"os"
+	"strconv"
+	"time"
 )
 
+const defaultLeaseTTL = 30 * time.Second
+
 type (
 	Config struct {
 		Postgres Postgres `create_post.json:"postgres"`
 		Telegram Telegram `create_post.json:"telegram"`
+		Leader   Leader   `create_post.json:"leader"`
 	}
 
 	Postgres struct {
@@ -17,6 +22,13 @@ type (
 
 	Telegram struct {
 		Token string `create_post.json:"token"`
+		// StorageChannelID - приватный канал, в который копируются черновики публикаций, 0 - без хранилища
+		StorageChannelID int64 `create_post.json:"storage_channel_id"`
+	}
+
+	Leader struct {
+		InstanceID string        `create_post.json:"instance_id"`
+		LeaseTTL   time.Duration `create_post.json:"lease_ttl"`
 	}
 )
 
@@ -26,14 +38,55 @@ func New() (*Config, error) {
 		return nil, err
 	}
 
+	leader, err := newLeader()
+	if err != nil {
+		return nil, err
+	}
+
+	var storageChannelID int64
+	if id := os.Getenv("STORAGE_CHANNEL_ID"); id != "" {
+		storageChannelID, err = strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	config := &Config{
 		Postgres: Postgres{
 			URL: os.Getenv("POSTGRES_URL"),
 		},
 		Telegram: Telegram{
-			Token: os.Getenv("TOKEN_TG"),
+			Token:            os.Getenv("TOKEN_TG"),
+			StorageChannelID: storageChannelID,
 		},
+		Leader: leader,
 	}
 
 	return config, nil
 }
+
+// newLeader - INSTANCE_ID по умолчанию имя хоста (имя контейнера), LEADER_LEASE_TTL по умолчанию 30s
+func newLeader() (Leader, error) {
+	leader := Leader{
+		InstanceID: os.Getenv("INSTANCE_ID"),
+		LeaseTTL:   defaultLeaseTTL,
+	}
+
+	if leader.InstanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return leader, err
+		}
+		leader.InstanceID = hostname
+	}
+
+	if ttl := os.Getenv("LEADER_LEASE_TTL"); ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil {
+			return leader, err
+		}
+		leader.LeaseTTL = parsed
+	}
+
+	return leader, nil
+}
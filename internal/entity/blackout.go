@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Blackout - разовый период тишины канала (праздник, день траура), в который ничего не публикуется
+type Blackout struct {
+	ID        int       `json:"id"`
+	ChannelID int       `json:"channel_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    *string   `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Text - описание периода со временем в часовом поясе loc
+func (b Blackout) Text(loc *time.Location) string {
+	text := fmt.Sprintf("%s - %s", FormatTime(&b.StartsAt, loc), FormatTime(&b.EndsAt, loc))
+	if b.Reason != nil {
+		text += " (" + *b.Reason + ")"
+	}
+	return text
+}
+
+// BlackoutsText - нумерованный список периодов тишины для панели управления
+func BlackoutsText(blackouts []Blackout, loc *time.Location) string {
+	if len(blackouts) == 0 {
+		return "Периодов тишины нет."
+	}
+
+	text := "Периоды тишины:"
+	for i, blackout := range blackouts {
+		text += fmt.Sprintf("\n%d. %s", i+1, blackout.Text(loc))
+	}
+	return text
+}
+
+func BlackoutIDs(blackouts []Blackout) []int {
+	ids := make([]int, 0, len(blackouts))
+	for _, blackout := range blackouts {
+		ids = append(ids, blackout.ID)
+	}
+	return ids
+}
@@ -0,0 +1,99 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxButtonsInRow - telegram показывает в ряду не больше 8 кнопок
+	MaxButtonsInRow = 8
+	// MaxButtons - ограничение telegram на количество кнопок в сообщении
+	MaxButtons = 100
+)
+
+// Button - кнопка-ссылка под публикацией
+type Button struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// Buttons - расположение кнопок публикации по рядам
+type Buttons [][]Button
+
+func (b Buttons) Count() int {
+	var count int
+	for _, row := range b {
+		count += len(row)
+	}
+	return count
+}
+
+// Append adds rows to the end of layout
+func (b Buttons) Append(rows Buttons) (Buttons, error) {
+	if b.Count()+rows.Count() > MaxButtons {
+		return b, fmt.Errorf("ошибка: у публикации может быть не больше %d кнопок", MaxButtons)
+	}
+	return append(b, rows...), nil
+}
+
+// Delete removes button, row without buttons is removed too
+func (b Buttons) Delete(row int, col int) (Buttons, bool) {
+	if row < 0 || row >= len(b) || col < 0 || col >= len(b[row]) {
+		return b, false
+	}
+
+	layout := make(Buttons, 0, len(b))
+	for i, buttons := range b {
+		if i != row {
+			layout = append(layout, buttons)
+			continue
+		}
+		buttons = append(append([]Button{}, buttons[:col]...), buttons[col+1:]...)
+		if len(buttons) > 0 {
+			layout = append(layout, buttons)
+		}
+	}
+	return layout, true
+}
+
+// Swap exchanges rows of layout, used to move row up and down
+func (b Buttons) Swap(i int, j int) (Buttons, bool) {
+	if i < 0 || j < 0 || i >= len(b) || j >= len(b) {
+		return b, false
+	}
+
+	layout := append(Buttons{}, b...)
+	layout[i], layout[j] = layout[j], layout[i]
+	return layout, true
+}
+
+// InputText - кнопки в формате добавления кнопок: Текст - ссылка, кнопки ряда через |, ряд на строке
+func (b Buttons) InputText() string {
+	rows := make([]string, 0, len(b))
+	for _, row := range b {
+		buttons := make([]string, 0, len(row))
+		for _, button := range row {
+			buttons = append(buttons, button.Text+" - "+button.URL)
+		}
+		rows = append(rows, strings.Join(buttons, " | "))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// Text - описание кнопок для панели управления
+func (b Buttons) Text() string {
+	if len(b) == 0 {
+		return "Кнопок нет"
+	}
+
+	rows := make([]string, 0, len(b))
+	for i, row := range b {
+		buttons := make([]string, 0, len(row))
+		for _, button := range row {
+			buttons = append(buttons, fmt.Sprintf("%s (%s)", button.Text, button.URL))
+		}
+		rows = append(rows, fmt.Sprintf("Ряд %d: %s", i+1, strings.Join(buttons, " | ")))
+	}
+	return strings.Join(rows, "\n")
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"fmt"
+	"time"
 )
 
 type ChannelStatus string
@@ -29,12 +30,62 @@ func GetChannelStatus(gotStatus string) ChannelStatus {
 	}
 }
 
+// CatchUpPolicy - что делать с публикациями, которые просрочились пока бот был выключен
+type CatchUpPolicy string
+
+const (
+	CatchUpSendLate CatchUpPolicy = "send_late"
+	CatchUpSkip     CatchUpPolicy = "skip"
+	CatchUpAsk      CatchUpPolicy = "ask"
+)
+
+// Next returns policy for cyclic switch from admin panel
+func (c CatchUpPolicy) Next() CatchUpPolicy {
+	switch c {
+	case CatchUpSkip:
+		return CatchUpSendLate
+	case CatchUpSendLate:
+		return CatchUpAsk
+	default:
+		return CatchUpSkip
+	}
+}
+
+func (c CatchUpPolicy) Title() string {
+	switch c {
+	case CatchUpSendLate:
+		return "отправить с опозданием"
+	case CatchUpAsk:
+		return "спросить администраторов"
+	default:
+		return "пропустить и пометить ошибкой"
+	}
+}
+
 type Channel struct {
-	ID            int           `json:"id"`
-	TgID          int64         `json:"tg_id"`
-	ChannelName   string        `json:"channel_name"`
-	ChannelUrl    *string       `json:"channel_url"`
-	ChannelStatus ChannelStatus `json:"channel_status"`
+	ID                 int           `json:"id"`
+	TgID               int64         `json:"tg_id"`
+	ChannelName        string        `json:"channel_name"`
+	ChannelUrl         *string       `json:"channel_url"`
+	ChannelStatus      ChannelStatus `json:"channel_status"`
+	CatchUpPolicy      CatchUpPolicy `json:"catch_up_policy"`
+	MaxLatenessMinutes int           `json:"max_lateness_minutes"`
+	MaxSendAttempts    int           `json:"max_send_attempts"`
+	Timezone           string        `json:"timezone"`
+	// PostingWindows - разрешенные окна публикаций в формате window.Parse, nil - любое время
+	PostingWindows *string `json:"posting_windows"`
+	// QueueSlots - ежедневная сетка слотов очереди публикаций в формате window.ParseSlots
+	QueueSlots *string `json:"queue_slots"`
+}
+
+// Location - часовой пояс аудитории канала, в нем вычисляются правила повторения
+func (c Channel) Location() *time.Location {
+	return LoadLocation(c.Timezone)
+}
+
+// MaxLateness - after this lateness overdue publication can not be sent
+func (c Channel) MaxLateness() time.Duration {
+	return time.Duration(c.MaxLatenessMinutes) * time.Minute
 }
 
 func (c Channel) String() string {
@@ -48,3 +99,19 @@ func (c Channel) String() string {
 	return fmt.Sprintf("(tg_id: %d | channel_name: %s | ChannelURL: %s | Status: %s)",
 		c.TgID, c.ChannelName, url, c.ChannelStatus)
 }
+
+// SettingsText - описание настроек канала для панели управления
+func (c Channel) SettingsText() string {
+	windows := "любое время"
+	if c.PostingWindows != nil {
+		windows = *c.PostingWindows
+	}
+	slots := "не заданы"
+	if c.QueueSlots != nil {
+		slots = *c.QueueSlots
+	}
+
+	return fmt.Sprintf("Канал: %s\n\nЧасовой пояс: %s\nОкна публикаций: %s\nСлоты очереди: %s\nПросроченные публикации: %s\n"+
+		"Максимальное опоздание: %d мин.\nПопыток отправки/удаления: %d",
+		c.ChannelName, c.Location(), windows, slots, c.CatchUpPolicy.Title(), c.MaxLatenessMinutes, c.MaxSendAttempts)
+}
@@ -0,0 +1,184 @@
+package entity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ExportFormat int
+
+const (
+	ExportCSV ExportFormat = iota
+	ExportJSON
+	ExportICS
+)
+
+func (f ExportFormat) Valid() bool {
+	return f >= ExportCSV && f <= ExportICS
+}
+
+func (f ExportFormat) Extension() string {
+	switch f {
+	case ExportJSON:
+		return "json"
+	case ExportICS:
+		return "ics"
+	default:
+		return "csv"
+	}
+}
+
+// ExportStatuses - статусы фильтра выгрузки, в кнопках передается индекс, пустой статус - все публикации
+var ExportStatuses = []PublicationStatus{"", StatusAwaits, StatusSent, StatusErrorOnSending, StatusDeletedByBot, StatusErrorOnDeleting}
+
+// ExportFilter - фильтр выгрузки публикаций, целиком передается в данных кнопок
+type ExportFilter struct {
+	// ChannelID - 0 для выгрузки всех каналов
+	ChannelID int
+	// From, To - границы периода по дате отправки в виде 20240901 включительно, 0 - без границы
+	From int
+	To   int
+	// Status - индекс в ExportStatuses
+	Status int
+}
+
+// ParseExportFilter parses filter from ids of callback: channel, from, to, status
+func ParseExportFilter(ids []int) (ExportFilter, bool) {
+	if len(ids) < 4 {
+		return ExportFilter{}, false
+	}
+
+	filter := ExportFilter{ChannelID: ids[0], From: ids[1], To: ids[2], Status: ids[3]}
+	if filter.Status < 0 || filter.Status >= len(ExportStatuses) {
+		return ExportFilter{}, false
+	}
+	for _, date := range []int{filter.From, filter.To} {
+		if _, ok := ParseDateNumber(date, time.UTC); date != 0 && !ok {
+			return ExportFilter{}, false
+		}
+	}
+	return filter, true
+}
+
+// Data - данные кнопки действия выгрузки: export_{action}_{channel}_{from}_{to}_{status}
+func (f ExportFilter) Data(action string) string {
+	return fmt.Sprintf("export_%s_%d_%d_%d_%d", action, f.ChannelID, f.From, f.To, f.Status)
+}
+
+func (f ExportFilter) PublicationStatus() PublicationStatus {
+	return ExportStatuses[f.Status]
+}
+
+// Period returns bounds of publication date in location loc, end is exclusive
+func (f ExportFilter) Period(loc *time.Location) (from *time.Time, to *time.Time) {
+	if date, ok := ParseDateNumber(f.From, loc); ok {
+		from = &date
+	}
+	if date, ok := ParseDateNumber(f.To, loc); ok {
+		date = date.AddDate(0, 0, 1)
+		to = &date
+	}
+	return from, to
+}
+
+// WithPeriod returns filter with period between dates, dates are taken in their location
+func (f ExportFilter) WithPeriod(from *time.Time, to *time.Time) ExportFilter {
+	f.From, f.To = 0, 0
+	if from != nil {
+		f.From = DateNumber(*from)
+	}
+	if to != nil {
+		f.To = DateNumber(*to)
+	}
+	return f
+}
+
+// PeriodText - описание периода выгрузки
+func (f ExportFilter) PeriodText() string {
+	format := func(date int) string {
+		day, _ := ParseDateNumber(date, time.UTC)
+		return day.Format("02.01.2006")
+	}
+
+	switch {
+	case f.From == 0 && f.To == 0:
+		return "все время"
+	case f.To == 0:
+		return "с " + format(f.From)
+	case f.From == 0:
+		return "по " + format(f.To)
+	default:
+		return format(f.From) + " - " + format(f.To)
+	}
+}
+
+// StatusText - описание статуса фильтра
+func (f ExportFilter) StatusText() string {
+	if f.PublicationStatus() == "" {
+		return "все"
+	}
+	return f.PublicationStatus().Title()
+}
+
+// ExportText - описание панели выгрузки
+func ExportText(title string, count int, filter ExportFilter) string {
+	return fmt.Sprintf("Экспорт публикаций\n\n"+
+		"Каналы: %s\n"+
+		"Период: %s\n"+
+		"Статус: %s\n"+
+		"Публикаций: %d\n\n"+
+		"CSV открывается в таблицах, JSON можно загрузить обратно через импорт, "+
+		"iCalendar добавляет публикации в календарь.", title, filter.PeriodText(), filter.StatusText(), count)
+}
+
+// DateNumber returns date of t in its location as number like 20240901
+func DateNumber(t time.Time) int {
+	return t.Year()*10000 + int(t.Month())*100 + t.Day()
+}
+
+// ParseDateNumber returns start of day in location loc for number like 20240901
+func ParseDateNumber(date int, loc *time.Location) (time.Time, bool) {
+	day, err := time.ParseInLocation("20060102", strconv.Itoa(date), loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day, true
+}
+
+// ChannelRef - канал в формате импорта: @username публичного канала или telegram id
+func (p Publication) ChannelRef() string {
+	if p.ChannelURL != nil {
+		if username, ok := strings.CutPrefix(*p.ChannelURL, "t.me/"); ok && username != "" {
+			return "@" + username
+		}
+	}
+	return strconv.FormatInt(p.TelegramChannelID, 10)
+}
+
+// MessageLinks - ссылки на отправленные сообщения публикации, у приватного канала ссылка открывается только участникам
+func (p Publication) MessageLinks() []string {
+	prefix := "https://t.me/c/" + strings.TrimPrefix(strconv.FormatInt(p.TelegramChannelID, 10), "-100")
+	if p.ChannelURL != nil && strings.HasPrefix(*p.ChannelURL, "t.me/") {
+		prefix = "https://" + *p.ChannelURL
+	}
+
+	links := make([]string, 0, len(p.MessageIDs))
+	for _, messageID := range p.MessageIDs {
+		links = append(links, fmt.Sprintf("%s/%d", prefix, messageID))
+	}
+	return links
+}
+
+// PlannedDeleteDate - время удаления отправленной или ожидающей публикации, false если она не удаляется
+func (p Publication) PlannedDeleteDate() (time.Time, bool) {
+	switch {
+	case p.SentAt != nil:
+		return p.DeleteAt(*p.SentAt)
+	case p.PublicationDate != nil:
+		return p.DeleteAt(*p.PublicationDate)
+	default:
+		return time.Time{}, false
+	}
+}
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChannelGroup - именованная группа каналов для публикации, рассылки и массового переноса публикаций
+type ChannelGroup struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name"`
+	Channels []Channel `json:"channels"`
+}
+
+func (g ChannelGroup) ChannelIDs() []int {
+	ids := make([]int, 0, len(g.Channels))
+	for _, channel := range g.Channels {
+		ids = append(ids, channel.ID)
+	}
+	return ids
+}
+
+// Text - описание группы для панели управления
+func (g ChannelGroup) Text() string {
+	if len(g.Channels) == 0 {
+		return fmt.Sprintf("Группа: %s\n\nКаналов в группе нет. Отметьте каналы, которые входят в группу.", g.Name)
+	}
+
+	names := make([]string, 0, len(g.Channels))
+	for _, channel := range g.Channels {
+		names = append(names, channel.ChannelName)
+	}
+	return fmt.Sprintf("Группа: %s\n\nКаналы: %s", g.Name, strings.Join(names, ", "))
+}
+
+// GroupsText - список групп для панели управления
+func GroupsText(groups []ChannelGroup) string {
+	if len(groups) == 0 {
+		return "Групп каналов нет."
+	}
+
+	text := "Группы каналов:"
+	for _, group := range groups {
+		text += fmt.Sprintf("\n%s - каналов: %d", group.Name, len(group.Channels))
+	}
+	return text
+}
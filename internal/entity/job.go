@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+type JobKind string
+
+const (
+	JobPublish JobKind = "publish"
+	JobDelete  JobKind = "delete"
+)
+
+type JobState string
+
+const (
+	JobPending JobState = "pending"
+	JobHeld    JobState = "held"
+	JobFailed  JobState = "failed"
+)
+
+// HoldReason - почему задача ждет решения администратора
+type HoldReason string
+
+const (
+	// HoldOverdue - задача просрочена к моменту запуска планировщика, политика канала "спросить"
+	HoldOverdue HoldReason = "overdue"
+	// HoldBlackout - в канале начался период тишины
+	HoldBlackout HoldReason = "blackout"
+	// HoldPaused - серия приостановлена администратором
+	HoldPaused HoldReason = "paused"
+)
+
+// Job - задача из таблицы publication_job, единственный источник правды о том, что и когда нужно отправить/удалить
+type Job struct {
+	ID            int        `json:"id"`
+	PublicationID int        `json:"publication_id"`
+	Kind          JobKind    `json:"kind"`
+	State         JobState   `json:"state"`
+	RunAt         time.Time  `json:"run_at"`
+	ChatID        *int64     `json:"chat_id"`
+	MessageIDs    []int64    `json:"message_ids"`
+	Attempts      int        `json:"attempts"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	LastError     *string    `json:"last_error"`
+	// OccurrenceID - delete job of series occurrence, series can have several sent messages waiting for deletion
+	OccurrenceID *int `json:"occurrence_id"`
+	// IgnoreBlackout - администратор решил отправить публикацию несмотря на период тишины канала
+	IgnoreBlackout bool `json:"ignore_blackout"`
+	// TargetID - задача доставки в дополнительный канал публикации
+	TargetID *int `json:"target_id"`
+	// HoldReason - заполнена у задачи в состоянии held
+	HoldReason *HoldReason `json:"hold_reason"`
+
+	// channel table - for join
+	ChannelName        string        `json:"channel_name"`
+	CatchUpPolicy      CatchUpPolicy `json:"catch_up_policy"`
+	MaxLatenessMinutes int           `json:"max_lateness_minutes"`
+	MaxAttempts        int           `json:"max_send_attempts"`
+}
+
+// JobAttempt - неудачная попытка выполнения задачи, хранится для просмотра администраторами
+type JobAttempt struct {
+	ID            int       `json:"id"`
+	PublicationID int       `json:"publication_id"`
+	JobID         *int      `json:"job_id"`
+	TargetID      *int      `json:"target_id"`
+	Kind          JobKind   `json:"kind"`
+	Attempt       int       `json:"attempt"`
+	Error         string    `json:"error"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// TargetChannelName - дополнительный канал попытки, пустой для основного канала
+	TargetChannelName string `json:"target_channel_name"`
+}
+
+func (a JobAttempt) String() string {
+	return a.Text(time.UTC)
+}
+
+// Text - описание попытки со временем в часовом поясе loc
+func (a JobAttempt) Text(loc *time.Location) string {
+	if a.TargetID != nil {
+		return fmt.Sprintf("%s #%d в канал %s (%s): %s", a.Kind, a.Attempt, a.TargetChannelName, FormatTime(&a.CreatedAt, loc), a.Error)
+	}
+	return fmt.Sprintf("%s #%d (%s): %s", a.Kind, a.Attempt, FormatTime(&a.CreatedAt, loc), a.Error)
+}
+
+// MaxLateness - after this lateness overdue job can not be executed
+func (j Job) MaxLateness() time.Duration {
+	return time.Duration(j.MaxLatenessMinutes) * time.Minute
+}
+
+func (j Job) String() string {
+	return fmt.Sprintf("(id: %d | publication_id: %d | kind: %s | state: %s | run_at: %s | attempts: %d | occurrence_id: %v | target_id: %v)",
+		j.ID, j.PublicationID, j.Kind, j.State, j.RunAt, j.Attempts, j.OccurrenceID, j.TargetID)
+}
@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lease - аренда лидерства, только держатель аренды запускает планировщик и получает обновления телеграма
+type Lease struct {
+	Name       string    `json:"name"`
+	Holder     string    `json:"holder"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	RenewedAt  time.Time `json:"renewed_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func (l Lease) IsExpired() bool {
+	return l.ExpiresAt.Before(time.Now())
+}
+
+func (l Lease) String() string {
+	return fmt.Sprintf("(name: %s | holder: %s | acquired_at: %s | renewed_at: %s | expires_at: %s)",
+		l.Name, l.Holder, l.AcquiredAt, l.RenewedAt, l.ExpiresAt)
+}
@@ -0,0 +1,115 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxAlbumSize - telegram отправляет в одной медиагруппе не больше 10 файлов
+const MaxAlbumSize = 10
+
+type MediaType string
+
+const (
+	MediaPhoto     MediaType = "photo"
+	MediaVideo     MediaType = "video"
+	MediaAnimation MediaType = "animation"
+	MediaDocument  MediaType = "document"
+	MediaAudio     MediaType = "audio"
+	MediaVoice     MediaType = "voice"
+	MediaVideoNote MediaType = "video_note"
+)
+
+func (t MediaType) Title() string {
+	switch t {
+	case MediaPhoto:
+		return "фото"
+	case MediaVideo:
+		return "видео"
+	case MediaAnimation:
+		return "GIF"
+	case MediaDocument:
+		return "документ"
+	case MediaAudio:
+		return "аудио"
+	case MediaVoice:
+		return "голосовое сообщение"
+	case MediaVideoNote:
+		return "видеосообщение"
+	default:
+		return string(t)
+	}
+}
+
+func (t MediaType) Valid() bool {
+	switch t {
+	case MediaPhoto, MediaVideo, MediaAnimation, MediaDocument, MediaAudio, MediaVoice, MediaVideoNote:
+		return true
+	default:
+		return false
+	}
+}
+
+// albumKind - telegram группирует в альбом фото с видео, документы или аудио, остальные типы в альбом не входят
+func (t MediaType) albumKind() string {
+	switch t {
+	case MediaPhoto, MediaVideo:
+		return "visual"
+	case MediaDocument, MediaAudio:
+		return string(t)
+	default:
+		return ""
+	}
+}
+
+// AlbumAllowed - вложения можно отправить одним альбомом
+func AlbumAllowed(media []Media) bool {
+	if len(media) < 2 {
+		return true
+	}
+	kind := media[0].Type.albumKind()
+	for _, m := range media {
+		if m.Type.albumKind() == "" || m.Type.albumKind() != kind {
+			return false
+		}
+	}
+	return true
+}
+
+// HasCaption - видеосообщение отправляется без подписи, текст публикации отправляется следом
+func (t MediaType) HasCaption() bool {
+	return t != MediaVideoNote
+}
+
+// Media - вложение публикации, несколько вложений отправляются альбомом (медиагруппой)
+type Media struct {
+	Type   MediaType `json:"type"`
+	FileID string    `json:"file_id"`
+	// MediaGroupID - альбом, из которого администратор прислал файл, пустой для одиночного файла
+	MediaGroupID string `json:"media_group_id"`
+}
+
+// IsAlbum - публикация отправляется медиагруппой
+func (p Publication) IsAlbum() bool {
+	return len(p.Media) > 1
+}
+
+// MediaText - описание вложений для панели управления
+func (p Publication) MediaText() string {
+	switch len(p.Media) {
+	case 0:
+		return "нет"
+	case 1:
+		return p.Media[0].Type.Title()
+	}
+
+	var types []string
+	seen := make(map[MediaType]bool, len(p.Media))
+	for _, media := range p.Media {
+		if !seen[media.Type] {
+			seen[media.Type] = true
+			types = append(types, media.Type.Title())
+		}
+	}
+	return fmt.Sprintf("альбом из %d файлов (%s)", len(p.Media), strings.Join(types, ", "))
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+type OccurrenceStatus string
+
+const (
+	OccurrenceSent            OccurrenceStatus = "sent"
+	OccurrenceSkipped         OccurrenceStatus = "skipped"
+	OccurrenceErrorOnSending  OccurrenceStatus = "error_on_sending"
+	OccurrenceDeletedByBot    OccurrenceStatus = "deleted_by_bot"
+	OccurrenceErrorOnDeleting OccurrenceStatus = "error_on_deleting"
+)
+
+func (s OccurrenceStatus) Title() string {
+	switch s {
+	case OccurrenceSent:
+		return "отправлена"
+	case OccurrenceSkipped:
+		return "пропущена"
+	case OccurrenceErrorOnSending:
+		return "ошибка отправки"
+	case OccurrenceDeletedByBot:
+		return "удалена"
+	case OccurrenceErrorOnDeleting:
+		return "ошибка удаления"
+	default:
+		return string(s)
+	}
+}
+
+// Occurrence - одно вхождение повторяющейся публикации со своей историей отправки и удаления
+type Occurrence struct {
+	ID            int              `json:"id"`
+	PublicationID int              `json:"publication_id"`
+	RunAt         time.Time        `json:"run_at"`
+	Status        OccurrenceStatus `json:"status"`
+	MessageIDs    []int64          `json:"message_ids"`
+	Error         *string          `json:"error"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+func (o Occurrence) String() string {
+	return o.Text(time.UTC)
+}
+
+// Text - описание вхождения со временем в часовом поясе loc
+func (o Occurrence) Text(loc *time.Location) string {
+	text := fmt.Sprintf("%s - %s", FormatTime(&o.RunAt, loc), o.Status.Title())
+	if o.Error != nil {
+		text += ": " + *o.Error
+	}
+	return text
+}
@@ -0,0 +1,136 @@
+package entity
+
+import "strings"
+
+type DeliveryOption string
+
+const (
+	OptionSilent       DeliveryOption = "silent"
+	OptionProtected    DeliveryOption = "protect"
+	OptionSpoiler      DeliveryOption = "spoiler"
+	OptionPreview      DeliveryOption = "preview"
+	OptionPreviewAbove DeliveryOption = "above"
+	OptionPreviewSize  DeliveryOption = "size"
+	OptionPreviewURL   DeliveryOption = "url"
+	OptionSplit        DeliveryOption = "split"
+)
+
+// KeptByDraft - спойлер и превью ссылки копия черновика берет из исходного сообщения,
+// после их изменения публикация собирается из полей
+func (o DeliveryOption) KeptByDraft() bool {
+	switch o {
+	case OptionSpoiler, OptionPreview, OptionPreviewAbove, OptionPreviewSize, OptionPreviewURL:
+		return true
+	default:
+		return false
+	}
+}
+
+type LinkPreviewSize string
+
+const (
+	PreviewSizeAuto  LinkPreviewSize = ""
+	PreviewSizeSmall LinkPreviewSize = "small"
+	PreviewSizeLarge LinkPreviewSize = "large"
+)
+
+func (s LinkPreviewSize) Title() string {
+	switch s {
+	case PreviewSizeSmall:
+		return "мелкое"
+	case PreviewSizeLarge:
+		return "крупное"
+	default:
+		return "авто"
+	}
+}
+
+// next - размер превью переключается по кругу: авто, крупное, мелкое
+func (s LinkPreviewSize) next() LinkPreviewSize {
+	switch s {
+	case PreviewSizeAuto:
+		return PreviewSizeLarge
+	case PreviewSizeLarge:
+		return PreviewSizeSmall
+	default:
+		return PreviewSizeAuto
+	}
+}
+
+// LinkPreview - превью ссылок в тексте публикации, по умолчанию выключено
+type LinkPreview struct {
+	Enabled bool `json:"enabled"`
+	// URL - ссылка для превью, по умолчанию первая ссылка текста
+	URL       string          `json:"url"`
+	AboveText bool            `json:"above_text"`
+	Size      LinkPreviewSize `json:"size"`
+}
+
+// DeliveryOptions - параметры отправки публикации
+type DeliveryOptions struct {
+	// Silent - сообщение приходит подписчикам без звука
+	Silent bool `json:"silent"`
+	// Protected - сообщение нельзя переслать и сохранить
+	Protected bool `json:"protected"`
+	// Spoiler - фото, видео и GIF скрыты под спойлером
+	Spoiler bool        `json:"spoiler"`
+	Preview LinkPreview `json:"preview"`
+	// Split - длинный текст разделяется: начало уходит в подпись или первое сообщение, остаток следом
+	Split bool `json:"split"`
+}
+
+// Toggle switches option, returns false for unknown option
+func (o *DeliveryOptions) Toggle(option DeliveryOption) bool {
+	switch option {
+	case OptionSilent:
+		o.Silent = !o.Silent
+	case OptionProtected:
+		o.Protected = !o.Protected
+	case OptionSpoiler:
+		o.Spoiler = !o.Spoiler
+	case OptionPreview:
+		o.Preview.Enabled = !o.Preview.Enabled
+	case OptionPreviewAbove:
+		o.Preview.AboveText = !o.Preview.AboveText
+	case OptionPreviewSize:
+		o.Preview.Size = o.Preview.Size.next()
+	case OptionSplit:
+		o.Split = !o.Split
+	default:
+		return false
+	}
+	return true
+}
+
+// Text - описание параметров для панели управления
+func (o DeliveryOptions) Text() string {
+	lines := []string{
+		"Без звука: " + onOff(o.Silent),
+		"Защита от пересылки и сохранения: " + onOff(o.Protected),
+		"Спойлер на фото и видео: " + onOff(o.Spoiler),
+		"Разделять длинный текст: " + onOff(o.Split),
+		"Превью ссылок: " + onOff(o.Preview.Enabled),
+	}
+	if o.Preview.Enabled {
+		url := o.Preview.URL
+		if url == "" {
+			url = "первая ссылка текста"
+		}
+		position := "под текстом"
+		if o.Preview.AboveText {
+			position = "над текстом"
+		}
+		lines = append(lines,
+			"Ссылка превью: "+url,
+			"Расположение превью: "+position,
+			"Размер превью: "+o.Preview.Size.Title())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func onOff(value bool) string {
+	if value {
+		return "вкл"
+	}
+	return "выкл"
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/pkg/ttl"
 	"time"
 )
 
@@ -20,20 +21,126 @@ type Publication struct {
 	ChannelID         int64             `json:"channel_id"`
 	PublicationStatus PublicationStatus `json:"publication_status"`
 	Text              string            `json:"text"`
-	Image             *string           `json:"image"`
-	ButtonUrl         *string           `json:"button_url"`
-	ButtonText        *string           `json:"button_text"`
-	PublicationDate   *time.Time        `json:"publication_date"`
-	DeleteDate        *time.Time        `json:"delete_date"`
-	MessageID         int64             `json:"message_id"`
+	// Entities - форматирование текста, отправляется как есть вместо разметки
+	Entities []MessageEntity `json:"entities"`
+	// ParseMode - разметка текста публикаций, созданных до хранения entities, пустая для текста с entities
+	ParseMode string `json:"parse_mode"`
+	// Media - вложения в порядке отправки, несколько вложений отправляются альбомом
+	Media []Media `json:"media"`
+	// StorageMessageIDs - копии черновика в канале-хранилище, публикация отправляется их копированием.
+	// Пустые, если публикация собрана из полей после изменения текста или вложений
+	StorageMessageIDs []int64 `json:"storage_message_ids"`
+	// Buttons - кнопки-ссылки под публикацией по рядам
+	Buttons         Buttons         `json:"buttons"`
+	Options         DeliveryOptions `json:"options"`
+	PublicationDate *time.Time      `json:"publication_date"`
+	DeleteDate      *time.Time      `json:"delete_date"`
+	// DeleteTTL - время жизни сообщения, отсчитывается от фактической отправки, исключает DeleteDate
+	DeleteTTL *time.Duration `json:"delete_ttl"`
+	SentAt    *time.Time     `json:"sent_at"`
+	// MessageIDs - сообщения отправленной публикации, у альбома сообщение на каждый файл
+	MessageIDs   []int64 `json:"message_ids"`
+	Recurrence   *string `json:"recurrence"`
+	SeriesPaused bool    `json:"series_paused"`
+	// Queued - дата отправки назначена очередью канала, а не вручную
+	Queued bool `json:"queued"`
 
 	// channel table - for join
-	TelegramChannelID int64  `json:"tg_id"`
-	ChannelName       string `json:"channel_name"`
+	TelegramChannelID  int64         `json:"tg_id"`
+	ChannelName        string        `json:"channel_name"`
+	ChannelURL         *string       `json:"channel_url"`
+	CatchUpPolicy      CatchUpPolicy `json:"catch_up_policy"`
+	MaxLatenessMinutes int           `json:"max_lateness_minutes"`
+	ChannelTimezone    string        `json:"channel_timezone"`
+}
+
+// Location - часовой пояс канала публикации
+func (p Publication) Location() *time.Location {
+	return LoadLocation(p.ChannelTimezone)
+}
+
+// IsSeries - publication is sent repeatedly by recurrence rule
+func (p Publication) IsSeries() bool {
+	return p.Recurrence != nil
+}
+
+// DeleteAfter - for series delete date is kept relative to publication date and applied to every occurrence
+func (p Publication) DeleteAfter() (time.Duration, bool) {
+	if p.DeleteDate == nil || p.PublicationDate == nil || !p.DeleteDate.After(*p.PublicationDate) {
+		return 0, false
+	}
+	return p.DeleteDate.Sub(*p.PublicationDate), true
+}
+
+// SeriesText - описание серии для панели управления
+func (p Publication) SeriesText(next *time.Time, occurrences []Occurrence, loc *time.Location) string {
+	if !p.IsSeries() {
+		return "Публикация не повторяется.\n\nПравило задается cron-выражением (например 0 10 * * 1 - каждый понедельник в 10:00) " +
+			"или RRULE (например FREQ=MONTHLY;BYMONTHDAY=1 - первого числа каждого месяца). " +
+			"Первое вхождение серии - дата отправки публикации."
+	}
+
+	text := fmt.Sprintf("Правило повторения: %s (часовой пояс канала: %s)\n", *p.Recurrence, p.Location())
+	switch {
+	case p.SeriesPaused:
+		text += "Серия приостановлена\n"
+	case next != nil:
+		text += fmt.Sprintf("Следующая отправка: %s\n", FormatTime(next, loc))
+	}
+
+	if len(occurrences) > 0 {
+		text += "\nПоследние вхождения:"
+		for _, occurrence := range occurrences {
+			text += "\n" + occurrence.Text(loc)
+		}
+	}
+	return text
+}
+
+// DeleteAt returns delete time of message sent at sentAt, false if message must not be deleted
+func (p Publication) DeleteAt(sentAt time.Time) (time.Time, bool) {
+	switch {
+	case p.DeleteTTL != nil:
+		return sentAt.Add(*p.DeleteTTL), true
+	case p.IsSeries():
+		deleteAfter, ok := p.DeleteAfter()
+		return sentAt.Add(deleteAfter), ok
+	case p.DeleteDate != nil:
+		return *p.DeleteDate, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// DeleteText - описание удаления для панели управления
+func (p Publication) DeleteText(loc *time.Location) string {
+	if p.DeleteTTL != nil {
+		return fmt.Sprintf("через %s после отправки", ttl.Format(*p.DeleteTTL))
+	}
+	return FormatTime(p.DeleteDate, loc)
+}
+
+// QueueSlot returns slot of channel queue which publication occupies, false if it is not queued or already due
+func (p Publication) QueueSlot() (time.Time, bool) {
+	if !p.Queued || p.PublicationStatus != StatusAwaits || p.PublicationDate == nil || !p.PublicationDate.After(time.Now()) {
+		return time.Time{}, false
+	}
+	return *p.PublicationDate, true
+}
+
+// QueueGapText - вопрос администратору об освободившемся слоте очереди
+func QueueGapText(slot time.Time, loc *time.Location) string {
+	return fmt.Sprintf("Освободился слот очереди %s. Сдвинуть следующие публикации очереди на слот вперед или оставить пробел?",
+		FormatTime(&slot, loc))
+}
+
+// MaxLateness - after this lateness overdue publication can not be sent
+func (p Publication) MaxLateness() time.Duration {
+	return time.Duration(p.MaxLatenessMinutes) * time.Minute
 }
 
 func (p Publication) String() string {
-	return fmt.Sprintf("(id: %d | channel_id: %d | publication_status: %s | text: %s | image: %v |"+
-		" publication_date: %s | delete_date: %v | button_url: %v | button_text: %v)",
-		p.ID, p.ChannelID, p.PublicationStatus, p.Text, p.Image, p.PublicationDate, p.DeleteDate, p.ButtonUrl, p.ButtonText)
+	return fmt.Sprintf("(id: %d | channel_id: %d | publication_status: %s | text: %s | media: %d |"+
+		" publication_date: %s | delete_date: %v | buttons: %d)",
+		p.ID, p.ChannelID, p.PublicationStatus, p.Text, len(p.Media), p.PublicationDate, p.DeleteDate, p.Buttons.Count())
 }
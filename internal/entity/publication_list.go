@@ -0,0 +1,110 @@
+package entity
+
+import "fmt"
+
+// PublicationListStatus - фильтр списка публикаций по статусу
+type PublicationListStatus int
+
+const (
+	ListAll PublicationListStatus = iota
+	// ListAwaits - ожидают отправки в назначенную дату
+	ListAwaits
+	// ListSent - отправлены, в том числе уже удаленные из канала
+	ListSent
+	// ListFailed - ошибки отправки и удаления
+	ListFailed
+	// ListDrafts - черновики без даты отправки
+	ListDrafts
+)
+
+// ListStatusTitles - подписи кнопок фильтра в порядке PublicationListStatus
+var ListStatusTitles = []string{"Все", "⏱", "✅", "❌", "📝"}
+
+func (s PublicationListStatus) Valid() bool {
+	return s >= ListAll && s <= ListDrafts
+}
+
+// PublicationListMode - что делает нажатие на публикацию в списке
+type PublicationListMode int
+
+const (
+	ListOpen PublicationListMode = iota
+	ListDelete
+)
+
+// Command - действие кнопки публикации: publication_{command}_{id}
+func (m PublicationListMode) Command() string {
+	if m == ListDelete {
+		return "delete"
+	}
+	return "get"
+}
+
+// PublicationList - состояние списка публикаций, целиком передается в данных кнопок
+type PublicationList struct {
+	// ChannelID - 0 для результатов поиска по всем каналам, фраза поиска хранится в режиме поиска
+	ChannelID int
+	Mode      PublicationListMode
+	Status    PublicationListStatus
+	// Asc - сначала ранние даты отправки, по умолчанию сначала поздние, черновики всегда в конце
+	Asc  bool
+	Page int
+}
+
+// ParsePublicationList parses list from ids of callback: channel, mode, status, asc, page
+func ParsePublicationList(ids []int) (PublicationList, bool) {
+	if len(ids) != 5 {
+		return PublicationList{}, false
+	}
+
+	list := PublicationList{
+		ChannelID: ids[0],
+		Mode:      PublicationListMode(ids[1]),
+		Status:    PublicationListStatus(ids[2]),
+		Asc:       ids[3] == 1,
+		Page:      ids[4],
+	}
+	if (list.Mode != ListOpen && list.Mode != ListDelete) || !list.Status.Valid() || ids[3] < 0 || ids[3] > 1 || list.Page < 0 {
+		return PublicationList{}, false
+	}
+	return list, true
+}
+
+// Data - данные кнопки списка: pub_list_{channel}_{mode}_{status}_{asc}_{page}
+func (l PublicationList) Data() string {
+	asc := 0
+	if l.Asc {
+		asc = 1
+	}
+	return fmt.Sprintf("pub_list_%d_%d_%d_%d_%d", l.ChannelID, l.Mode, l.Status, asc, l.Page)
+}
+
+// IsSearch - список результатов поиска по всем каналам
+func (l PublicationList) IsSearch() bool {
+	return l.ChannelID == 0
+}
+
+// WithStatus returns first page of list filtered by status
+func (l PublicationList) WithStatus(status PublicationListStatus) PublicationList {
+	l.Status, l.Page = status, 0
+	return l
+}
+
+// WithPage returns list on page
+func (l PublicationList) WithPage(page int) PublicationList {
+	l.Page = page
+	return l
+}
+
+// Reversed returns first page of list with opposite sorting
+func (l PublicationList) Reversed() PublicationList {
+	l.Asc, l.Page = !l.Asc, 0
+	return l
+}
+
+// PublicationListText - заголовок списка публикаций с обозначениями статусов
+func PublicationListText(title string, total int) string {
+	return fmt.Sprintf("%s\nНайдено публикаций: %d\n\n"+
+		"❌ - ошибка при удалении/ошибка при отправке\n✅ - отправлено\n⏱ - ожидает отправки\n"+
+		"📝 - черновик без даты отправки\n🗑 - удалено из канала", title, total)
+}
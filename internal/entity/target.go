@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+func (s PublicationStatus) Title() string {
+	switch s {
+	case StatusSent:
+		return "отправлена"
+	case StatusAwaits:
+		return "ожидает отправки"
+	case StatusErrorOnSending:
+		return "ошибка отправки"
+	case StatusDeletedByBot:
+		return "удалена"
+	case StatusErrorOnDeleting:
+		return "ошибка удаления"
+	default:
+		return string(s)
+	}
+}
+
+// Target - дополнительный канал публикации (кросспостинг) со своим сообщением и статусом доставки.
+// Основной канал публикации хранится в самой публикации.
+type Target struct {
+	ID            int               `json:"id"`
+	PublicationID int               `json:"publication_id"`
+	ChannelID     int               `json:"channel_id"`
+	Status        PublicationStatus `json:"status"`
+	MessageIDs    []int64           `json:"message_ids"`
+	// SendOffsetMinutes - смещение отправки в канал относительно даты отправки публикации
+	SendOffsetMinutes int `json:"send_offset_minutes"`
+	// DeleteOffsetMinutes - смещение удаления в канале относительно удаления публикации
+	DeleteOffsetMinutes int        `json:"delete_offset_minutes"`
+	SentAt              *time.Time `json:"sent_at"`
+	Error               *string    `json:"error"`
+
+	// channel table - for join
+	TelegramChannelID int64  `json:"tg_id"`
+	ChannelName       string `json:"channel_name"`
+}
+
+func (t Target) SendOffset() time.Duration {
+	return time.Duration(t.SendOffsetMinutes) * time.Minute
+}
+
+func (t Target) DeleteOffset() time.Duration {
+	return time.Duration(t.DeleteOffsetMinutes) * time.Minute
+}
+
+// DeleteAt returns delete time of target message sent at sentAt, false if message must not be deleted
+func (t Target) DeleteAt(publication Publication, sentAt time.Time) (time.Time, bool) {
+	deleteAt, ok := publication.DeleteAt(sentAt)
+	if !ok {
+		return time.Time{}, false
+	}
+	return deleteAt.Add(t.DeleteOffset()), true
+}
+
+// Text - строка статуса доставки в канал
+func (t Target) Text(loc *time.Location) string {
+	text := fmt.Sprintf("%s - %s", t.ChannelName, t.Status.Title())
+	if t.SentAt != nil {
+		text += " " + FormatTime(t.SentAt, loc)
+	}
+	if t.SendOffsetMinutes != 0 || t.DeleteOffsetMinutes != 0 {
+		text += fmt.Sprintf(" (смещение отправки %+d мин, удаления %+d мин)", t.SendOffsetMinutes, t.DeleteOffsetMinutes)
+	}
+	if t.Error != nil {
+		text += ": " + *t.Error
+	}
+	return text
+}
+
+// TargetsText - описание каналов публикации для панели управления
+func TargetsText(publication Publication, targets []Target, loc *time.Location) string {
+	text := fmt.Sprintf("Основной канал: %s - %s\n", publication.ChannelName, publication.PublicationStatus.Title())
+	if len(targets) == 0 {
+		return text + "\nДополнительных каналов нет. Отметьте каналы, в которые публикация будет отправлена вместе с основным."
+	}
+
+	text += "\nДополнительные каналы:"
+	for _, target := range targets {
+		text += "\n" + target.Text(loc)
+	}
+	return text
+}
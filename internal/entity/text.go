@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf16"
+)
+
+// Лимиты telegram на длину текста, считаются в UTF-16 без учета форматирования
+const (
+	MaxTextLength    = 4096
+	MaxCaptionLength = 1024
+)
+
+// MessageEntity - форматирование текста в формате Bot API (жирный, спойлер, цитата, ссылка и т.д.),
+// смещение и длина считаются в UTF-16
+type MessageEntity struct {
+	Type     string `json:"type"`
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+	URL      string `json:"url,omitempty"`
+	Language string `json:"language,omitempty"`
+	// User - пользователь для text_mention, хранится как пришел от telegram
+	User          json.RawMessage `json:"user,omitempty"`
+	CustomEmojiID string          `json:"custom_emoji_id,omitempty"`
+}
+
+// TextLength - длина текста так, как ее считает telegram
+func TextLength(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+// TextLimit - длина текста, которая помещается в первое сообщение публикации: подпись к вложению
+// или отдельное сообщение, если подписи нет или у альбома есть кнопки
+func (p Publication) TextLimit() int {
+	return p.TextLimitFor(p.IsAlbum())
+}
+
+// TextLimitFor - TextLimit с явным признаком альбома: первый файл альбома приходит отдельным
+// сообщением, и до получения остальных публикация еще не альбом
+func (p Publication) TextLimitFor(album bool) int {
+	switch {
+	case len(p.Media) == 0:
+		return MaxTextLength
+	case album:
+		if p.Buttons.Count() > 0 {
+			return MaxTextLength
+		}
+		return MaxCaptionLength
+	case p.Media[0].Type.HasCaption():
+		return MaxCaptionLength
+	default:
+		return MaxTextLength
+	}
+}
+
+// FitsText - текст помещается в публикацию целиком или будет разделен.
+// Текст в MarkdownV2 не разделяется, так как разметка может оказаться разорвана
+func (p Publication) FitsText() bool {
+	return p.FitsLimit(p.TextLimit())
+}
+
+// FitsLimit - FitsText для заданного лимита первого сообщения
+func (p Publication) FitsLimit(limit int) bool {
+	return TextLength(p.Text) <= limit || (p.Options.Split && p.ParseMode == "")
+}
+
+// LengthText - длина текста для панели управления
+func (p Publication) LengthText() string {
+	length, limit := TextLength(p.Text), p.TextLimit()
+	switch {
+	case length <= limit:
+		return fmt.Sprintf("%d из %d, осталось %d", length, limit, limit-length)
+	case p.FitsText():
+		return fmt.Sprintf("%d из %d, будет разделен на несколько сообщений", length, limit)
+	default:
+		return fmt.Sprintf("%d из %d, превышен на %d", length, limit, length-limit)
+	}
+}
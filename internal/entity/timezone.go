@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultTimezone - часовой пояс каналов и администраторов, для которых он не задан
+const DefaultTimezone = "Europe/Moscow"
+
+// DateLayout - формат ввода и отображения дат в панели управления
+const DateLayout = "2006-01-02 15:04"
+
+// LoadLocation returns location by IANA name, unknown names fall back to DefaultTimezone
+func LoadLocation(name string) *time.Location {
+	if loc, err := time.LoadLocation(name); err == nil && name != "" {
+		return loc
+	}
+	if loc, err := time.LoadLocation(DefaultTimezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// FormatTime formats time in location, nil time is shown as not set
+func FormatTime(t *time.Time, loc *time.Location) string {
+	if t == nil {
+		return "не назначено"
+	}
+	return t.In(loc).Format(DateLayout + " MST")
+}
+
+type locationKey struct{}
+
+// ContextWithLocation saves time zone of admin who handles update
+func ContextWithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// LocationFromContext returns time zone of admin, DefaultTimezone if it is not saved in context
+func LocationFromContext(ctx context.Context) *time.Location {
+	if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok && loc != nil {
+		return loc
+	}
+	return LoadLocation(DefaultTimezone)
+}
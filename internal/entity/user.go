@@ -19,9 +19,14 @@ type User struct {
 	CreatedAt   time.Time `json:"created_at,omitempty"`
 	ChannelFrom string    `json:"channel_from,omitempty"`
 	UserRole    UserRole  `json:"user_role,omitempty"`
+	Timezone    string    `json:"timezone,omitempty"`
+}
+
+func (u User) Location() *time.Location {
+	return LoadLocation(u.Timezone)
 }
 
 func (u User) String() string {
-	return fmt.Sprintf("(id: %d | tg_username: %s | channel_from: %v | created_at: %v | role: %s)",
-		u.ID, u.TGUsername, u.ChannelFrom, u.CreatedAt, u.UserRole)
+	return fmt.Sprintf("(id: %d | tg_username: %s | channel_from: %v | created_at: %v | role: %s | timezone: %s)",
+		u.ID, u.TGUsername, u.ChannelFrom, u.CreatedAt, u.UserRole, u.Timezone)
 }
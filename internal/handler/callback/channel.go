@@ -19,6 +19,10 @@ type CallbackChannel interface {
 	CallbackShowAllChannels() tgbot.ViewFunc
 	CallbackGetChannel() tgbot.ViewFunc
 	CallbackCancelCreate() tgbot.ViewFunc
+	CallbackUpdateCatchUpPolicy() tgbot.ViewFunc
+	CallbackUpdateMaxLateness() tgbot.ViewFunc
+	CallbackUpdateMaxAttempts() tgbot.ViewFunc
+	CallbackUpdateTimezone() tgbot.ViewFunc
 }
 
 type callbackChannel struct {
@@ -61,10 +65,10 @@ func NewCallbackChannel(
 	}, nil
 }
 
-// CallbackShowAllChannels - show_channels
+// CallbackShowAllChannels - show_channels/show_channels_{page}
 func (c *callbackChannel) CallbackShowAllChannels() tgbot.ViewFunc {
 	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
-		channelMarkup, err := c.channelService.GetAllAdminChannel(ctx)
+		channelMarkup, err := c.channelService.GetAllAdminChannel(ctx, GetPage(update.CallbackData()))
 		if err != nil {
 			c.log.Error("channelService.GetAllAdminChannel: failed to get channel: %v", err)
 			handler.HandleError(bot, update, err)
@@ -87,25 +91,13 @@ func (c *callbackChannel) CallbackShowAllChannels() tgbot.ViewFunc {
 // CallbackGetChannel - channel_get_{channel_id}/back_setting_{publication_id}
 func (c *callbackChannel) CallbackGetChannel() tgbot.ViewFunc {
 	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
-		var (
-			channelIdOrPublicationId = GetID(update.CallbackData())
-			channelName              string
-		)
+		channelIdOrPublicationId := GetID(update.CallbackData())
 
 		if channelIdOrPublicationId == 0 {
 			c.log.Error("entity.GetID: failed to get id from channel button")
 			return customErr.ErrNotFound
 		}
 
-		if strings.Contains(update.CallbackData(), "channel_get_") {
-
-			channel, err := c.channelService.GetByID(ctx, channelIdOrPublicationId)
-			if err != nil {
-				c.log.Error("ChannelService.GetByID: failed to get channel: %v", err)
-				return err
-			}
-			channelName = channel.ChannelName
-		}
 		if strings.Contains(update.CallbackData(), "back_setting_") {
 
 			publication, err := c.publicationService.GetOnePublicationByID(ctx, channelIdOrPublicationId)
@@ -114,14 +106,19 @@ func (c *callbackChannel) CallbackGetChannel() tgbot.ViewFunc {
 				return err
 			}
 			channelIdOrPublicationId = int(publication.ChannelID)
-			channelName = publication.ChannelName
 		}
 
-		channelSettingMarkup := markup.ChannelSetting(channelIdOrPublicationId)
+		channel, err := c.channelService.GetByID(ctx, channelIdOrPublicationId)
+		if err != nil {
+			c.log.Error("ChannelService.GetByID: failed to get channel: %v", err)
+			return err
+		}
+
+		channelSettingMarkup := markup.ChannelSetting(channel.ID)
 		if _, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
 			update.CallbackQuery.Message.MessageID,
 			&channelSettingMarkup,
-			"Канал: "+channelName); err != nil {
+			channel.SettingsText()); err != nil {
 			return err
 		}
 
@@ -151,10 +148,134 @@ func (c *callbackChannel) CallbackCancelCreate() tgbot.ViewFunc {
 		if _, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
 			update.CallbackQuery.Message.MessageID,
 			&channelSettingMarkup,
-			"Канал: "+channel.ChannelName); err != nil {
+			channel.SettingsText()); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// CallbackUpdateCatchUpPolicy - catchup_update_{channel_id}
+func (c *callbackChannel) CallbackUpdateCatchUpPolicy() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		channelID := GetID(update.CallbackData())
+		if channelID == 0 {
+			c.log.Error("entity.GetID: failed to get id from channel button")
+			return customErr.ErrNotFound
+		}
+
+		channel, err := c.channelService.GetByID(ctx, channelID)
+		if err != nil {
+			c.log.Error("ChannelService.GetByID: failed to get channel: %v", err)
+			return err
+		}
+
+		channel.CatchUpPolicy = channel.CatchUpPolicy.Next()
+		if err = c.channelService.UpdateCatchUpPolicy(ctx, channelID, channel.CatchUpPolicy); err != nil {
+			c.log.Error("ChannelService.UpdateCatchUpPolicy: failed to update channel: %v", err)
+			return err
+		}
+
+		channelSettingMarkup := markup.ChannelSetting(channelID)
+		if _, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&channelSettingMarkup,
+			channel.SettingsText()); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// CallbackUpdateMaxLateness - lateness_update_{channel_id}
+func (c *callbackChannel) CallbackUpdateMaxLateness() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		channelID := GetID(update.CallbackData())
+		if channelID == 0 {
+			c.log.Error("entity.GetID: failed to get id from channel button")
+			return customErr.ErrNotFound
+		}
+
+		text := "Отправьте максимальное опоздание в минутах, после которого просроченная публикация не будет отправлена"
+		cancelCommandMarkup := markup.CancelCommandCreate(channelID)
+		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&cancelCommandMarkup,
+			text)
+		if err != nil {
+			return err
+		}
+
+		c.store.Set(&store.Data{
+			CurrentMsgID:  sentMsg,
+			PreferMsgID:   update.CallbackQuery.Message.MessageID,
+			OperationType: store.ChannelMaxLatenessUpdate,
+			ChannelID:     channelID,
+		}, update.FromChat().ID)
+
+		return nil
+	}
+}
+
+// CallbackUpdateMaxAttempts - attempts_update_{channel_id}
+func (c *callbackChannel) CallbackUpdateMaxAttempts() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		channelID := GetID(update.CallbackData())
+		if channelID == 0 {
+			c.log.Error("entity.GetID: failed to get id from channel button")
+			return customErr.ErrNotFound
+		}
+
+		text := "Отправьте максимальное количество попыток отправки или удаления публикации при временных ошибках Telegram"
+		cancelCommandMarkup := markup.CancelCommandCreate(channelID)
+		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&cancelCommandMarkup,
+			text)
+		if err != nil {
+			return err
+		}
+
+		c.store.Set(&store.Data{
+			CurrentMsgID:  sentMsg,
+			PreferMsgID:   update.CallbackQuery.Message.MessageID,
+			OperationType: store.ChannelMaxAttemptsUpdate,
+			ChannelID:     channelID,
+		}, update.FromChat().ID)
+
+		return nil
+	}
+}
+
+// CallbackUpdateTimezone - timezone_update_{channel_id}
+func (c *callbackChannel) CallbackUpdateTimezone() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		channelID := GetID(update.CallbackData())
+		if channelID == 0 {
+			c.log.Error("entity.GetID: failed to get id from channel button")
+			return customErr.ErrNotFound
+		}
+
+		text := "Отправьте часовой пояс аудитории канала в формате IANA, например Europe/Moscow или Asia/Yekaterinburg. " +
+			"В нем вычисляются правила повторения публикаций"
+		cancelCommandMarkup := markup.CancelCommandCreate(channelID)
+		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&cancelCommandMarkup,
+			text)
+		if err != nil {
 			return err
 		}
 
+		c.store.Set(&store.Data{
+			CurrentMsgID:  sentMsg,
+			PreferMsgID:   update.CallbackQuery.Message.MessageID,
+			OperationType: store.ChannelTimezoneUpdate,
+			ChannelID:     channelID,
+		}, update.FromChat().ID)
+
 		return nil
 	}
 }
@@ -0,0 +1,384 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot"
+	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
+	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
+	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	customMsg "github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
+	"github.com/Enthreeka/tg-posting-bot/pkg/window"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"time"
+)
+
+type CallbackDatePicker interface {
+	CallbackUpdatePublicationSentDate() tgbot.ViewFunc
+	CallbackUpdatePublicationDeleteDate() tgbot.ViewFunc
+	CallbackDatePickerMonth() tgbot.ViewFunc
+	CallbackDatePickerDay() tgbot.ViewFunc
+	CallbackDatePickerSet() tgbot.ViewFunc
+	CallbackDatePickerNone() tgbot.ViewFunc
+}
+
+type callbackDatePicker struct {
+	publicationService service.PublicationService
+	windowService      service.PostingWindowService
+	jobService         service.JobService
+	targetService      service.TargetService
+	log                *logger.Logger
+	tgMsg              customMsg.Message
+	store              store.LocalStorage
+}
+
+func NewCallbackDatePicker(publicationService service.PublicationService,
+	windowService service.PostingWindowService,
+	jobService service.JobService,
+	targetService service.TargetService,
+	log *logger.Logger,
+	tgMsg customMsg.Message,
+	store store.LocalStorage,
+) (CallbackDatePicker, error) {
+	if log == nil {
+		return nil, errors.New("logger is nil")
+	}
+	if publicationService == nil {
+		return nil, errors.New("publicationService is nil")
+	}
+	if windowService == nil {
+		return nil, errors.New("windowService is nil")
+	}
+	if jobService == nil {
+		return nil, errors.New("jobService is nil")
+	}
+	if targetService == nil {
+		return nil, errors.New("targetService is nil")
+	}
+	if tgMsg == nil {
+		return nil, errors.New("tgMsg is nil")
+	}
+	if store == nil {
+		return nil, errors.New("store is nil")
+	}
+
+	return &callbackDatePicker{
+		publicationService: publicationService,
+		windowService:      windowService,
+		jobService:         jobService,
+		targetService:      targetService,
+		log:                log,
+		tgMsg:              tgMsg,
+		store:              store,
+	}, nil
+}
+
+// picker returns date picker of publication. Days before today are disabled, for sent date also days without
+// allowed time in posting windows of channel, for delete date also days before publication date
+func (c *callbackDatePicker) picker(ctx context.Context, kind int, publication *entity.Publication) (markup.DatePicker, error) {
+	loc := entity.LocationFromContext(ctx)
+	now := time.Now().In(loc)
+	picker := markup.DatePicker{
+		Kind:  kind,
+		ID:    publication.ID,
+		Today: now,
+		Back:  fmt.Sprintf("cancel_update_%d", publication.ID),
+	}
+
+	switch kind {
+	case markup.PickSentDate:
+		schedule, err := c.windowService.Schedule(ctx, int(publication.ChannelID), now)
+		if err != nil {
+			return picker, err
+		}
+		picker.Disabled = func(day time.Time) bool {
+			return !dayAllowed(schedule, day, now)
+		}
+	case markup.PickDeleteDate:
+		if publication.PublicationDate != nil {
+			sent := publication.PublicationDate.In(loc)
+			sentDay := time.Date(sent.Year(), sent.Month(), sent.Day(), 0, 0, 0, 0, loc)
+			picker.Disabled = func(day time.Time) bool {
+				return day.Before(sentDay)
+			}
+		}
+	}
+	return picker, nil
+}
+
+// dayAllowed - в дне есть время, разрешенное окнами публикаций и не попадающее в периоды тишины
+func dayAllowed(schedule *window.Schedule, day time.Time, now time.Time) bool {
+	from := day
+	if from.Before(now) {
+		from = now
+	}
+	next, ok := schedule.Next(from)
+	return ok && next.Before(day.AddDate(0, 0, 1))
+}
+
+// dateInputText - подсказка к вводу даты сообщением вместо выбора кнопками
+const dateInputText = "Дату можно отправить и сообщением: 2024-08-27 15:48, 27.08.2024 15:48, 25.12 09:00, " +
+	"завтра 10:00, пн 18:30, через 2 часа или +45m"
+
+func pickerTitle(kind int, publicationID int) string {
+	if kind == markup.PickDeleteDate {
+		return fmt.Sprintf("Дата удаления публикации #%d", publicationID)
+	}
+	return fmt.Sprintf("Дата отправки публикации #%d", publicationID)
+}
+
+// startPicker shows month of current date of publication. Date can still be sent as message, so input is saved in store
+func (c *callbackDatePicker) startPicker(ctx context.Context, update *tgbotapi.Update, kind int, operation store.TypeCommand) error {
+	publicationID := GetID(update.CallbackData())
+	if publicationID == 0 {
+		c.log.Error("entity.GetID: failed to get id from channel button")
+		return customErr.ErrNotFound
+	}
+
+	publication, err := c.publicationService.GetPublicationAndChannel(ctx, publicationID)
+	if err != nil {
+		c.log.Error("failed to GetPublicationAndChannel: %v", err)
+		return err
+	}
+	picker, err := c.picker(ctx, kind, publication)
+	if err != nil {
+		c.log.Error("failed to create date picker: %v", err)
+		return err
+	}
+
+	loc := entity.LocationFromContext(ctx)
+	current := time.Now().In(loc).Truncate(time.Hour).Add(time.Hour)
+	if kind == markup.PickDeleteDate && publication.DeleteDate != nil {
+		current = publication.DeleteDate.In(loc)
+	} else if publication.PublicationDate != nil && publication.PublicationDate.After(time.Now()) {
+		current = publication.PublicationDate.In(loc)
+	}
+
+	text := pickerTitle(kind, publicationID) + "\n\nВыберите день, точкой отмечены недоступные дни. " + dateInputText
+	if kind == markup.PickDeleteDate {
+		text += " или указать время жизни после фактической отправки, например 24h или 1d12h"
+	}
+
+	monthMarkup := picker.Month(current, current.Hour()*60+current.Minute())
+	sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+		update.CallbackQuery.Message.MessageID,
+		&monthMarkup,
+		text)
+	if err != nil {
+		return err
+	}
+
+	c.store.Set(&store.Data{
+		CurrentMsgID:  sentMsg,
+		PreferMsgID:   update.CallbackQuery.Message.MessageID,
+		OperationType: operation,
+		PublicationID: publicationID,
+	}, update.FromChat().ID)
+
+	return nil
+}
+
+// CallbackUpdatePublicationSentDate - sent-date_update_{publication_id}
+func (c *callbackDatePicker) CallbackUpdatePublicationSentDate() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		return c.startPicker(ctx, update, markup.PickSentDate, store.PublicationSentDateUpdate)
+	}
+}
+
+// CallbackUpdatePublicationDeleteDate - delete-date_update_{publication_id}
+func (c *callbackDatePicker) CallbackUpdatePublicationDeleteDate() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		return c.startPicker(ctx, update, markup.PickDeleteDate, store.PublicationDeleteDateUpdate)
+	}
+}
+
+// pickerState returns pressed button of date picker with its publication
+func (c *callbackDatePicker) pickerState(ctx context.Context, update *tgbotapi.Update) (markup.DatePickerState, *entity.Publication, error) {
+	state, ok := markup.ParseDatePicker(update.CallbackData())
+	if !ok || (state.Kind != markup.PickSentDate && state.Kind != markup.PickDeleteDate) {
+		c.log.Error("markup.ParseDatePicker: invalid date picker button %s", update.CallbackData())
+		return state, nil, customErr.ErrNotFound
+	}
+
+	publication, err := c.publicationService.GetPublicationAndChannel(ctx, state.ID)
+	if err != nil {
+		c.log.Error("failed to GetPublicationAndChannel: %v", err)
+		return state, nil, err
+	}
+	return state, publication, nil
+}
+
+// CallbackDatePickerMonth - dp_month_{kind}_{publication_id}_{date}_{minutes}
+func (c *callbackDatePicker) CallbackDatePickerMonth() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		state, publication, err := c.pickerState(ctx, update)
+		if err != nil {
+			return err
+		}
+		picker, err := c.picker(ctx, state.Kind, publication)
+		if err != nil {
+			c.log.Error("failed to create date picker: %v", err)
+			return err
+		}
+
+		monthMarkup := picker.Month(state.Day(entity.LocationFromContext(ctx)), state.Minutes)
+		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&monthMarkup,
+			pickerTitle(state.Kind, state.ID)+"\n\nВыберите день, точкой отмечены недоступные дни.")
+		return err
+	}
+}
+
+// CallbackDatePickerDay - dp_day_{kind}_{publication_id}_{date}_{minutes}
+func (c *callbackDatePicker) CallbackDatePickerDay() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		state, publication, err := c.pickerState(ctx, update)
+		if err != nil {
+			return err
+		}
+		picker, err := c.picker(ctx, state.Kind, publication)
+		if err != nil {
+			c.log.Error("failed to create date picker: %v", err)
+			return err
+		}
+
+		loc := entity.LocationFromContext(ctx)
+		date := state.Time(loc)
+		text := fmt.Sprintf("%s: %s\n\nНастройте время и сохраните.", pickerTitle(state.Kind, state.ID), entity.FormatTime(&date, loc))
+		if state.Kind == markup.PickSentDate {
+			allowed, _, checkErr := c.windowService.Check(ctx, int(publication.ChannelID), date)
+			if checkErr == nil && !allowed {
+				text += "\nЭто время вне окон публикаций канала, при сохранении будет предложено ближайшее доступное."
+			}
+		}
+
+		clockMarkup := picker.Clock(state.Day(loc), state.Minutes)
+		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&clockMarkup,
+			text)
+		return err
+	}
+}
+
+// CallbackDatePickerSet - dp_set_{kind}_{publication_id}_{date}_{minutes}
+func (c *callbackDatePicker) CallbackDatePickerSet() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		state, publication, err := c.pickerState(ctx, update)
+		if err != nil {
+			return err
+		}
+
+		loc := entity.LocationFromContext(ctx)
+		date := state.Time(loc)
+		if state.Kind == markup.PickDeleteDate {
+			err = c.setDeleteDate(ctx, update, publication, date)
+		} else {
+			err = c.setSentDate(ctx, update, publication, date)
+		}
+		if err != nil {
+			return err
+		}
+		// дата выбрана кнопками, ввод сообщением больше не ожидается
+		c.store.Delete(update.FromChat().ID)
+		return nil
+	}
+}
+
+func (c *callbackDatePicker) setSentDate(ctx context.Context, update *tgbotapi.Update, publication *entity.Publication, date time.Time) error {
+	loc := entity.LocationFromContext(ctx)
+	if err := tgbot.PublicationUpdateDateValidation(date, loc); err != nil {
+		return err
+	}
+
+	allowed, next, err := c.windowService.Check(ctx, int(publication.ChannelID), date)
+	if errors.Is(err, window.ErrNoSlot) {
+		return errors.New("ошибка: в окнах публикаций канала нет доступного времени, проверьте настройки канала")
+	}
+	if err != nil {
+		c.log.Error("failed to check posting windows: %v", err)
+		return err
+	}
+	if !allowed {
+		slotMarkup := markup.WindowSlot(publication.ID, next.Unix(), next.In(loc).Format(entity.DateLayout))
+		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID, update.CallbackQuery.Message.MessageID, &slotMarkup,
+			fmt.Sprintf("Время %s вне окон публикаций или в период тишины канала %s.\n\nБлижайшее доступное время: %s",
+				entity.FormatTime(&date, loc), publication.ChannelName, entity.FormatTime(&next, loc)))
+		return err
+	}
+
+	if err = c.publicationService.UpdatePublicationDate(ctx, publication.ID, date); err != nil {
+		c.log.Error("failed to UpdatePublicationDate: %v", err)
+		return err
+	}
+	if err = c.jobService.SchedulePublish(ctx, publication.ID, date); err != nil {
+		c.log.Error("failed to SchedulePublish: %v", err)
+		return err
+	}
+
+	updatePublicationSettingsMarkup := markup.UpdatePublicationSettings(publication.ID)
+	_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+		update.CallbackQuery.Message.MessageID,
+		&updatePublicationSettingsMarkup,
+		fmt.Sprintf("Публикация #%d будет отправлена %s", publication.ID, entity.FormatTime(&date, loc)))
+	if err != nil {
+		return err
+	}
+
+	// перенос публикации из очереди освобождает ее слот
+	if slot, ok := publication.QueueSlot(); ok && !slot.Equal(date) {
+		return sendQueueGap(ctx, c.tgMsg, update, publication.ChannelID, slot)
+	}
+	return nil
+}
+
+func (c *callbackDatePicker) setDeleteDate(ctx context.Context, update *tgbotapi.Update, publication *entity.Publication, date time.Time) error {
+	loc := entity.LocationFromContext(ctx)
+	if err := tgbot.PublicationDeleteDateValidation(date, publication.PublicationDate, loc); err != nil {
+		return err
+	}
+
+	if err := c.publicationService.UpdateDeleteDate(ctx, publication.ID, date); err != nil {
+		c.log.Error("failed to UpdateDeleteDate: %v", err)
+		return err
+	}
+	publication.DeleteDate, publication.DeleteTTL = &date, nil
+
+	// у отправленной публикации переносится уже запланированное удаление
+	if publication.PublicationStatus == entity.StatusSent && len(publication.MessageIDs) > 0 {
+		sentAt := time.Now()
+		if publication.SentAt != nil {
+			sentAt = *publication.SentAt
+		}
+		if deleteAt, ok := publication.DeleteAt(sentAt); ok {
+			if err := c.jobService.ScheduleDelete(ctx, publication.ID, deleteAt,
+				publication.TelegramChannelID, publication.MessageIDs); err != nil {
+				c.log.Error("failed to ScheduleDelete: %v", err)
+				return err
+			}
+		}
+	}
+	if err := c.targetService.RescheduleDeletes(ctx, publication); err != nil {
+		c.log.Error("failed to RescheduleDeletes: %v", err)
+		return err
+	}
+
+	updatePublicationSettingsMarkup := markup.UpdatePublicationSettings(publication.ID)
+	_, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+		update.CallbackQuery.Message.MessageID,
+		&updatePublicationSettingsMarkup,
+		fmt.Sprintf("Публикация #%d будет удалена %s", publication.ID, entity.FormatTime(&date, loc)))
+	return err
+}
+
+// CallbackDatePickerNone - dp_none, заголовки и недоступные дни календаря
+func (c *callbackDatePicker) CallbackDatePickerNone() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		return nil
+	}
+}
@@ -0,0 +1,205 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot"
+	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
+	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
+	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	customMsg "github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"time"
+)
+
+type CallbackExport interface {
+	CallbackGetExport() tgbot.ViewFunc
+	CallbackExportPanel() tgbot.ViewFunc
+	CallbackExportRange() tgbot.ViewFunc
+	CallbackExportStatus() tgbot.ViewFunc
+	CallbackExportInput() tgbot.ViewFunc
+	CallbackExportFile() tgbot.ViewFunc
+}
+
+type callbackExport struct {
+	exportService service.ExportService
+	log           *logger.Logger
+	tgMsg         customMsg.Message
+	store         store.LocalStorage
+}
+
+func NewCallbackExport(exportService service.ExportService,
+	log *logger.Logger,
+	tgMsg customMsg.Message,
+	store store.LocalStorage,
+) (CallbackExport, error) {
+	if log == nil {
+		return nil, errors.New("logger is nil")
+	}
+	if exportService == nil {
+		return nil, errors.New("exportService is nil")
+	}
+	if tgMsg == nil {
+		return nil, errors.New("tgMsg is nil")
+	}
+	if store == nil {
+		return nil, errors.New("store is nil")
+	}
+
+	return &callbackExport{
+		exportService: exportService,
+		log:           log,
+		tgMsg:         tgMsg,
+		store:         store,
+	}, nil
+}
+
+// exportFilter returns filter from callback export_{action}_{channel_id}_{from}_{to}_{status}
+func (c *callbackExport) exportFilter(update *tgbotapi.Update) (entity.ExportFilter, error) {
+	ids, ok := GetIDs(update.CallbackData(), 4)
+	if !ok {
+		c.log.Error("GetIDs: failed to get filter from export button")
+		return entity.ExportFilter{}, customErr.ErrNotFound
+	}
+
+	filter, ok := entity.ParseExportFilter(ids)
+	if !ok {
+		c.log.Error("entity.ParseExportFilter: invalid filter %v", ids)
+		return entity.ExportFilter{}, customErr.ErrNotFound
+	}
+	return filter, nil
+}
+
+func (c *callbackExport) sendPanel(ctx context.Context, update *tgbotapi.Update, filter entity.ExportFilter) error {
+	title, count, err := c.exportService.Preview(ctx, filter, entity.LocationFromContext(ctx))
+	if err != nil {
+		c.log.Error("exportService.Preview: %v", err)
+		return err
+	}
+
+	exportMarkup := markup.Export(filter)
+	_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+		update.CallbackQuery.Message.MessageID,
+		&exportMarkup,
+		entity.ExportText(title, count, filter))
+	return err
+}
+
+// CallbackGetExport - export_get_{channel_id}, 0 - выгрузка всех каналов
+func (c *callbackExport) CallbackGetExport() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		ids, ok := GetIDs(update.CallbackData(), 1)
+		if !ok {
+			c.log.Error("GetIDs: failed to get id from export button")
+			return customErr.ErrNotFound
+		}
+
+		return c.sendPanel(ctx, update, entity.ExportFilter{ChannelID: ids[0]})
+	}
+}
+
+// CallbackExportPanel - export_panel_{channel_id}_{from}_{to}_{status}
+func (c *callbackExport) CallbackExportPanel() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		filter, err := c.exportFilter(update)
+		if err != nil {
+			return err
+		}
+
+		return c.sendPanel(ctx, update, filter)
+	}
+}
+
+// CallbackExportRange - export_range_{channel_id}_{from}_{to}_{status}
+func (c *callbackExport) CallbackExportRange() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		filter, err := c.exportFilter(update)
+		if err != nil {
+			return err
+		}
+
+		rangeMarkup := markup.ExportRange(filter, time.Now().In(entity.LocationFromContext(ctx)))
+		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&rangeMarkup,
+			fmt.Sprintf("Период выгрузки по дате отправки, сейчас: %s", filter.PeriodText()))
+		return err
+	}
+}
+
+// CallbackExportStatus - export_status_{channel_id}_{from}_{to}_{status}
+func (c *callbackExport) CallbackExportStatus() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		filter, err := c.exportFilter(update)
+		if err != nil {
+			return err
+		}
+
+		statusMarkup := markup.ExportStatus(filter)
+		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&statusMarkup,
+			"Выберите статус публикаций для выгрузки")
+		return err
+	}
+}
+
+// CallbackExportInput - export_input_{channel_id}_{from}_{to}_{status}
+func (c *callbackExport) CallbackExportInput() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		filter, err := c.exportFilter(update)
+		if err != nil {
+			return err
+		}
+
+		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&markup.MainMenu,
+			"Отправьте период в формате 2024-09-01 - 2024-09-30 или 01.09.2024 - 30.09.2024, "+
+				"одна дата - выгрузка за день. Даты включаются в период.")
+		if err != nil {
+			return err
+		}
+
+		c.store.Set(&store.Data{
+			Data:          filter,
+			CurrentMsgID:  sentMsg,
+			PreferMsgID:   update.CallbackQuery.Message.MessageID,
+			OperationType: store.PublicationExport,
+			ChannelID:     filter.ChannelID,
+		}, update.FromChat().ID)
+
+		return nil
+	}
+}
+
+// CallbackExportFile - export_file_{channel_id}_{from}_{to}_{status}_{format}
+func (c *callbackExport) CallbackExportFile() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		ids, ok := GetIDs(update.CallbackData(), 5)
+		if !ok {
+			c.log.Error("GetIDs: failed to get filter from export file button")
+			return customErr.ErrNotFound
+		}
+		filter, ok := entity.ParseExportFilter(ids[:4])
+		format := entity.ExportFormat(ids[4])
+		if !ok || !format.Valid() {
+			c.log.Error("entity.ParseExportFilter: invalid filter %v", ids)
+			return customErr.ErrNotFound
+		}
+
+		fileName, data, err := c.exportService.Export(ctx, filter, format, entity.LocationFromContext(ctx))
+		if err != nil {
+			c.log.Error("exportService.Export: %v", err)
+			return err
+		}
+
+		_, err = c.tgMsg.SendDocument(update.FromChat().ID, fileName, &data,
+			fmt.Sprintf("Период: %s, статус: %s", filter.PeriodText(), filter.StatusText()))
+		return err
+	}
+}
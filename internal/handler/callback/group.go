@@ -0,0 +1,226 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot"
+	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
+	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
+	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	customMsg "github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"strconv"
+	"strings"
+)
+
+type CallbackGroup interface {
+	CallbackGetGroups() tgbot.ViewFunc
+	CallbackCreateGroup() tgbot.ViewFunc
+	CallbackGetGroup() tgbot.ViewFunc
+	CallbackDeleteGroup() tgbot.ViewFunc
+	CallbackToggleMember() tgbot.ViewFunc
+	CallbackBroadcast() tgbot.ViewFunc
+	CallbackReschedule() tgbot.ViewFunc
+}
+
+type callbackGroup struct {
+	channelService service.ChannelService
+	log            *logger.Logger
+	tgMsg          customMsg.Message
+	store          store.LocalStorage
+}
+
+func NewCallbackGroup(channelService service.ChannelService,
+	log *logger.Logger,
+	tgMsg customMsg.Message,
+	store store.LocalStorage,
+) (CallbackGroup, error) {
+	if log == nil {
+		return nil, errors.New("logger is nil")
+	}
+	if channelService == nil {
+		return nil, errors.New("channelService is nil")
+	}
+	if tgMsg == nil {
+		return nil, errors.New("tgMsg is nil")
+	}
+	if store == nil {
+		return nil, errors.New("store is nil")
+	}
+
+	return &callbackGroup{
+		channelService: channelService,
+		log:            log,
+		tgMsg:          tgMsg,
+		store:          store,
+	}, nil
+}
+
+// showGroup - редактирует сообщение панелью управления группой
+func (c *callbackGroup) showGroup(ctx context.Context, update *tgbotapi.Update, groupID int, prefix string) error {
+	group, err := c.channelService.GetGroup(ctx, groupID)
+	if err != nil {
+		c.log.Error("channelService.GetGroup: %v", err)
+		return err
+	}
+
+	groupMarkup, err := c.channelService.GroupMarkup(ctx, group)
+	if err != nil {
+		c.log.Error("channelService.GroupMarkup: %v", err)
+		return err
+	}
+
+	_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+		update.CallbackQuery.Message.MessageID,
+		groupMarkup,
+		prefix+group.Text())
+	return err
+}
+
+func (c *callbackGroup) showGroups(ctx context.Context, update *tgbotapi.Update, prefix string) error {
+	groups, err := c.channelService.GetGroups(ctx)
+	if err != nil {
+		c.log.Error("channelService.GetGroups: %v", err)
+		return err
+	}
+
+	_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+		update.CallbackQuery.Message.MessageID,
+		c.channelService.GroupsMarkup(groups),
+		prefix+entity.GroupsText(groups))
+	return err
+}
+
+// prompt - запрашивает у администратора ввод для операции над группой
+func (c *callbackGroup) prompt(update *tgbotapi.Update, operation store.TypeCommand, groupID int, text string) error {
+	sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+		update.CallbackQuery.Message.MessageID,
+		&markup.CancelCommandGroup,
+		text)
+	if err != nil {
+		return err
+	}
+
+	c.store.Set(&store.Data{
+		CurrentMsgID:  sentMsg,
+		PreferMsgID:   update.CallbackQuery.Message.MessageID,
+		OperationType: operation,
+		ChannelID:     groupID,
+	}, update.FromChat().ID)
+
+	return nil
+}
+
+// CallbackGetGroups - group_list
+func (c *callbackGroup) CallbackGetGroups() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		// кнопка также отменяет ввод названия группы, рассылки или сдвига
+		c.store.Delete(update.FromChat().ID)
+
+		return c.showGroups(ctx, update, "")
+	}
+}
+
+// CallbackCreateGroup - group_create
+func (c *callbackGroup) CallbackCreateGroup() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		return c.prompt(update, store.ChannelGroupCreate, 0, "Отправьте название группы, например: Все региональные")
+	}
+}
+
+// CallbackGetGroup - group_get_{group_id}
+func (c *callbackGroup) CallbackGetGroup() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		groupID := GetID(update.CallbackData())
+		if groupID == 0 {
+			c.log.Error("entity.GetID: failed to get id from group button")
+			return customErr.ErrNotFound
+		}
+
+		return c.showGroup(ctx, update, groupID, "")
+	}
+}
+
+// CallbackDeleteGroup - group_delete_{group_id}
+func (c *callbackGroup) CallbackDeleteGroup() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		groupID := GetID(update.CallbackData())
+		if groupID == 0 {
+			c.log.Error("entity.GetID: failed to get id from group button")
+			return customErr.ErrNotFound
+		}
+
+		if err := c.channelService.DeleteGroup(ctx, groupID); err != nil {
+			c.log.Error("channelService.DeleteGroup: %v", err)
+			return err
+		}
+
+		return c.showGroups(ctx, update, "Группа удалена.\n\n")
+	}
+}
+
+// CallbackToggleMember - group_member_{group_id}_{channel_id}
+func (c *callbackGroup) CallbackToggleMember() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		parts := strings.Split(update.CallbackData(), "_")
+		if len(parts) != 4 {
+			return customErr.ErrNotFound
+		}
+		groupID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return customErr.ErrNotFound
+		}
+		channelID, err := strconv.Atoi(parts[3])
+		if err != nil {
+			return customErr.ErrNotFound
+		}
+
+		var prefix string
+		added, err := c.channelService.ToggleGroupMember(ctx, groupID, channelID)
+		switch {
+		case errors.Is(err, service.ErrGroupChannelNotAdmin):
+			prefix = "Бот не является администратором канала.\n\n"
+		case err != nil:
+			c.log.Error("channelService.ToggleGroupMember: %v", err)
+			return err
+		case added:
+			prefix = "Канал добавлен в группу.\n\n"
+		default:
+			prefix = "Канал убран из группы.\n\n"
+		}
+
+		return c.showGroup(ctx, update, groupID, prefix)
+	}
+}
+
+// CallbackBroadcast - group_broadcast_{group_id}
+func (c *callbackGroup) CallbackBroadcast() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		groupID := GetID(update.CallbackData())
+		if groupID == 0 {
+			c.log.Error("entity.GetID: failed to get id from group button")
+			return customErr.ErrNotFound
+		}
+
+		return c.prompt(update, store.ChannelGroupBroadcast, groupID,
+			"Отправьте текст рассылки, он будет сразу опубликован во всех каналах группы")
+	}
+}
+
+// CallbackReschedule - group_reschedule_{group_id}
+func (c *callbackGroup) CallbackReschedule() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		groupID := GetID(update.CallbackData())
+		if groupID == 0 {
+			c.log.Error("entity.GetID: failed to get id from group button")
+			return customErr.ErrNotFound
+		}
+
+		return c.prompt(update, store.ChannelGroupReschedule, groupID,
+			"Отправьте сдвиг для всех ожидающих публикаций каналов группы, например +2h, -30m или 1d\n\n"+
+				"Повторяющиеся публикации не переносятся, публикации не переносятся в прошлое")
+	}
+}
@@ -18,3 +18,31 @@ func GetID(data string) int {
 
 	return id
 }
+
+// GetIDs returns n ids which follow two-part key of callback: key_action_{id}_{id}...
+func GetIDs(data string, n int) ([]int, bool) {
+	parts := strings.Split(data, "_")
+	if len(parts) != n+2 {
+		return nil, false
+	}
+
+	ids := make([]int, 0, n)
+	for _, part := range parts[2:] {
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+// GetPage returns page from last part of callback key_{page}, first page if callback has no page
+func GetPage(data string) int {
+	parts := strings.Split(data, "_")
+	page, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil || page < 0 {
+		return 0
+	}
+	return page
+}
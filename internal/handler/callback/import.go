@@ -0,0 +1,129 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot"
+	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
+	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
+	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	customMsg "github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type CallbackImport interface {
+	CallbackStartImport() tgbot.ViewFunc
+	CallbackConfirmImport() tgbot.ViewFunc
+	CallbackCancelImport() tgbot.ViewFunc
+}
+
+type callbackImport struct {
+	importService service.ImportService
+	log           *logger.Logger
+	tgMsg         customMsg.Message
+	store         store.LocalStorage
+}
+
+func NewCallbackImport(importService service.ImportService,
+	log *logger.Logger,
+	tgMsg customMsg.Message,
+	store store.LocalStorage,
+) (CallbackImport, error) {
+	if log == nil {
+		return nil, errors.New("logger is nil")
+	}
+	if importService == nil {
+		return nil, errors.New("importService is nil")
+	}
+	if tgMsg == nil {
+		return nil, errors.New("tgMsg is nil")
+	}
+	if store == nil {
+		return nil, errors.New("store is nil")
+	}
+
+	return &callbackImport{
+		importService: importService,
+		log:           log,
+		tgMsg:         tgMsg,
+		store:         store,
+	}, nil
+}
+
+// CallbackStartImport - import_start
+func (c *callbackImport) CallbackStartImport() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		text := "Отправьте файл .json или .csv с публикациями.\n\n" +
+			"Поля JSON и колонки CSV: канал (название, @username или id), текст, вложения, " +
+			"дата_публикации, дата_удаления, кнопки. Даты в формате 2024-08-26 21:12 в вашем часовом поясе.\n" +
+			"В CSV вложения перечисляются через пробел как тип:file_id, кнопки - как при добавлении кнопок, " +
+			"ряд на строке.\n\nКаждая публикация будет проверена, создание нужно подтвердить."
+
+		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&markup.MainMenu,
+			text)
+		if err != nil {
+			return err
+		}
+
+		c.store.Set(&store.Data{
+			CurrentMsgID:  sentMsg,
+			PreferMsgID:   update.CallbackQuery.Message.MessageID,
+			OperationType: store.PublicationImport,
+		}, update.FromChat().ID)
+
+		return nil
+	}
+}
+
+// CallbackConfirmImport - import_confirm_{import_id}
+func (c *callbackImport) CallbackConfirmImport() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		importID := GetID(update.CallbackData())
+		if importID == 0 {
+			c.log.Error("entity.GetID: failed to get id from import button")
+			return customErr.ErrNotFound
+		}
+
+		count, err := c.importService.Commit(ctx, importID, update.FromChat().ID)
+		if errors.Is(err, customErr.ErrNoRows) {
+			return errors.New("ошибка: импорт уже подтвержден или отменен")
+		}
+		if err != nil {
+			c.log.Error("importService.Commit: %v", err)
+			return err
+		}
+
+		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&markup.MainMenu,
+			fmt.Sprintf("Импорт завершен, создано публикаций: %d", count))
+		return err
+	}
+}
+
+// CallbackCancelImport - import_cancel_{import_id}
+func (c *callbackImport) CallbackCancelImport() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		importID := GetID(update.CallbackData())
+		if importID == 0 {
+			c.log.Error("entity.GetID: failed to get id from import button")
+			return customErr.ErrNotFound
+		}
+
+		if err := c.importService.Cancel(ctx, importID, update.FromChat().ID); err != nil {
+			c.log.Error("importService.Cancel: %v", err)
+			return err
+		}
+
+		_, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&markup.MainMenu,
+			"Импорт отменен, публикации не созданы")
+		return err
+	}
+}
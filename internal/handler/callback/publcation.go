@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
 	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot"
 	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
 	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
@@ -12,6 +13,8 @@ import (
 	customMsg "github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api"
 	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"strings"
+	"time"
 )
 
 type PublicationChannel interface {
@@ -19,15 +22,22 @@ type PublicationChannel interface {
 	CallbackUpdatePublicationSettings() tgbot.ViewFunc
 	CallbackUpdatePublicationText() tgbot.ViewFunc
 	CallbackUpdatePublicationImage() tgbot.ViewFunc
-	CallbackUpdatePublicationButtonText() tgbot.ViewFunc
-	CallbackUpdatePublicationButtonLink() tgbot.ViewFunc
-	CallbackUpdatePublicationSentDate() tgbot.ViewFunc
-	CallbackUpdatePublicationDeleteDate() tgbot.ViewFunc
+	CallbackGetButtons() tgbot.ViewFunc
+	CallbackAddButtons() tgbot.ViewFunc
+	CallbackDeleteButton() tgbot.ViewFunc
+	CallbackMoveButtonRow() tgbot.ViewFunc
+	CallbackGetOptions() tgbot.ViewFunc
+	CallbackToggleOption() tgbot.ViewFunc
+	CallbackUpdatePreviewURL() tgbot.ViewFunc
 	CallbackCheckPublication() tgbot.ViewFunc
 	CallbackGetPublicationGet() tgbot.ViewFunc
 	CallbackGetListForCancelPublication() tgbot.ViewFunc
+	CallbackGetPublicationList() tgbot.ViewFunc
+	CallbackSearchPublication() tgbot.ViewFunc
 	CallbackDeletePublication() tgbot.ViewFunc
 	CallbackCancelUpdate() tgbot.ViewFunc
+	CallbackOverduePublication() tgbot.ViewFunc
+	CallbackOverdueDelete() tgbot.ViewFunc
 }
 
 type callbackPublication struct {
@@ -36,7 +46,8 @@ type callbackPublication struct {
 	log                *logger.Logger
 	tgMsg              customMsg.Message
 	store              store.LocalStorage
-	publicationArray   *store.PublicationArray
+	jobService         service.JobService
+	targetService      service.TargetService
 }
 
 func NewCallbackPublication(
@@ -45,7 +56,8 @@ func NewCallbackPublication(
 	tgMsg customMsg.Message,
 	store store.LocalStorage,
 	channelService service.ChannelService,
-	publicationArray *store.PublicationArray,
+	jobService service.JobService,
+	targetService service.TargetService,
 ) (PublicationChannel, error) {
 	if log == nil {
 		return nil, errors.New("logger is nil")
@@ -62,8 +74,11 @@ func NewCallbackPublication(
 	if channelService == nil {
 		return nil, errors.New("channelService is nil")
 	}
-	if publicationArray == nil {
-		return nil, errors.New("publicationArray is nil")
+	if jobService == nil {
+		return nil, errors.New("jobService is nil")
+	}
+	if targetService == nil {
+		return nil, errors.New("targetService is nil")
 	}
 
 	return &callbackPublication{
@@ -72,7 +87,8 @@ func NewCallbackPublication(
 		log:                log,
 		tgMsg:              tgMsg,
 		store:              store,
-		publicationArray:   publicationArray,
+		jobService:         jobService,
+		targetService:      targetService,
 	}, nil
 }
 
@@ -85,7 +101,8 @@ func (c *callbackPublication) CallbackCreatePublication() tgbot.ViewFunc {
 			return customErr.ErrNotFound
 		}
 
-		text := "Для создания публикации отправьте сообщение, дальнейшие настройки публикации расположены в <Управлениями публикациями>"
+		text := "Для создания публикации отправьте сообщение: текст, вложение с подписью или альбом. " +
+			"Дальнейшие настройки публикации расположены в <Управлениями публикациями>"
 
 		cancelCommandMarkup := markup.CancelCommandCreate(channelID)
 		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
@@ -122,10 +139,27 @@ func (c *callbackPublication) CallbackGetPublicationGet() tgbot.ViewFunc {
 			return err
 		}
 
+		loc := entity.LocationFromContext(ctx)
 		text := fmt.Sprintf("Изменение публикации\n\n"+
 			"Канал: %s\n"+
-			"Время удаления: %v\n"+
-			"Время отправления: %v", publication.ChannelName, publication.DeleteDate, publication.PublicationDate)
+			"Вложения: %s\n"+
+			"Кнопок: %d\n"+
+			"Символов: %s\n"+
+			"Время удаления: %s\n"+
+			"Время отправления: %s", publication.ChannelName, publication.MediaText(), publication.Buttons.Count(),
+			publication.LengthText(), publication.DeleteText(loc), entity.FormatTime(publication.PublicationDate, loc))
+
+		attempts, err := c.jobService.GetAttempts(ctx, publicationID)
+		if err != nil {
+			c.log.Error("failed to GetAttempts: %v", err)
+		}
+		if len(attempts) > 0 {
+			text += "\n\nПоследние ошибки:"
+			for _, attempt := range attempts {
+				text += "\n" + attempt.Text(loc)
+			}
+		}
+
 		updatePublicationSettingsMarkup := markup.UpdatePublicationSettings(publicationID)
 		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
 			update.CallbackQuery.Message.MessageID,
@@ -148,26 +182,88 @@ func (c *callbackPublication) CallbackUpdatePublicationSettings() tgbot.ViewFunc
 			return customErr.ErrNotFound
 		}
 
-		channel, err := c.channelService.GetByID(ctx, channelID)
-		if err != nil {
+		return c.sendPublicationList(ctx, update, entity.PublicationList{ChannelID: channelID})
+	}
+}
+
+// sendPublicationList - страница списка публикаций канала или результатов поиска вместо сообщения с нажатой кнопкой
+func (c *callbackPublication) sendPublicationList(ctx context.Context, update *tgbotapi.Update, list entity.PublicationList) error {
+	var title, search string
+	if list.IsSearch() {
+		// фраза поиска не помещается в данные кнопок и хранится, пока администратор в режиме поиска
+		storeData, ok := c.store.Read(update.FromChat().ID)
+		if ok && storeData.OperationType == store.PublicationSearch {
+			search, _ = storeData.Data.(string)
+		}
+		if search == "" {
+			_, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+				update.CallbackQuery.Message.MessageID,
+				&markup.PublicationSearch,
+				"Режим поиска завершен, начните новый поиск")
 			return err
 		}
-
-		publicationMarkup, err := c.publicationService.GetAllPublicationsByChannelID(ctx, channelID, "get")
+		title = fmt.Sprintf("Результаты поиска: %s\nОтправьте другую фразу для нового поиска", search)
+	} else {
+		channel, err := c.channelService.GetByID(ctx, list.ChannelID)
 		if err != nil {
 			return err
 		}
+		title = fmt.Sprintf("Публикации для канала: **%s**", channel.ChannelName)
+	}
+	if list.Mode == entity.ListDelete {
+		title += "\nНажмите на публикацию чтобы ее *удалить*"
+	}
 
-		text := fmt.Sprintf("Публикации для канала: **%s**\n\n❌ - ошибка при удалении/ошибка при отправке\n"+
-			"✅ - отправлено\n⏱ - ожидает отправки\n🗑 - удалено из канала", channel.ChannelName)
-		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+	publicationMarkup, total, err := c.publicationService.GetPublicationList(ctx, list, search, entity.LocationFromContext(ctx))
+	if err != nil {
+		c.log.Error("publicationService.GetPublicationList: %v", err)
+		return err
+	}
+
+	_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+		update.CallbackQuery.Message.MessageID,
+		publicationMarkup,
+		entity.PublicationListText(title, total))
+	return err
+}
+
+// CallbackGetPublicationList - pub_list_{channel_id}_{mode}_{status}_{asc}_{page}, страницы, фильтры и сортировка списка
+func (c *callbackPublication) CallbackGetPublicationList() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		ids, ok := GetIDs(update.CallbackData(), 5)
+		if !ok {
+			c.log.Error("GetIDs: failed to get list from publication list button")
+			return customErr.ErrNotFound
+		}
+
+		list, ok := entity.ParsePublicationList(ids)
+		if !ok {
+			c.log.Error("entity.ParsePublicationList: invalid list %v", ids)
+			return customErr.ErrNotFound
+		}
+
+		return c.sendPublicationList(ctx, update, list)
+	}
+}
+
+// CallbackSearchPublication - pub_search, режим поиска: каждое сообщение ищется в текстах публикаций всех каналов
+func (c *callbackPublication) CallbackSearchPublication() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
 			update.CallbackQuery.Message.MessageID,
-			publicationMarkup,
-			text)
+			&markup.MainMenu,
+			"Отправьте фразу для поиска по тексту публикаций всех каналов. "+
+				"Каждое следующее сообщение начинает новый поиск, для выхода вернитесь в главное меню")
 		if err != nil {
 			return err
 		}
 
+		c.store.Set(&store.Data{
+			CurrentMsgID:  sentMsg,
+			PreferMsgID:   update.CallbackQuery.Message.MessageID,
+			OperationType: store.PublicationSearch,
+		}, update.FromChat().ID)
+
 		return nil
 	}
 }
@@ -195,7 +291,7 @@ func (c *callbackPublication) CallbackUpdatePublicationText() tgbot.ViewFunc {
 			CurrentMsgID:  sentMsg,
 			PreferMsgID:   update.CallbackQuery.Message.MessageID,
 			OperationType: store.PublicationTextUpdate,
-			ChannelID:     publicationID,
+			PublicationID: publicationID,
 		}, update.FromChat().ID)
 
 		return nil
@@ -211,7 +307,8 @@ func (c *callbackPublication) CallbackUpdatePublicationImage() tgbot.ViewFunc {
 			return customErr.ErrNotFound
 		}
 
-		text := "Отправьте изображение для публикации"
+		text := "Отправьте вложение для публикации: фото, видео, GIF, документ, аудио, голосовое или видеосообщение. " +
+			"Чтобы отправить альбом, выберите до 10 фото и видео (или документов, или аудио) в одном сообщении"
 		cancelCommandMarkup := markup.CancelCommandPublication(publicationID)
 		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
 			update.CallbackQuery.Message.MessageID,
@@ -225,23 +322,55 @@ func (c *callbackPublication) CallbackUpdatePublicationImage() tgbot.ViewFunc {
 			CurrentMsgID:  sentMsg,
 			PreferMsgID:   update.CallbackQuery.Message.MessageID,
 			OperationType: store.PublicationImageUpdate,
-			ChannelID:     publicationID,
+			PublicationID: publicationID,
 		}, update.FromChat().ID)
 
 		return nil
 	}
 }
 
-// CallbackUpdatePublicationButton - buttontext_update_{publication_id}
-func (c *callbackPublication) CallbackUpdatePublicationButtonText() tgbot.ViewFunc {
+func (c *callbackPublication) showButtons(ctx context.Context, update *tgbotapi.Update, publicationID int) error {
+	publication, err := c.publicationService.GetPublicationByPublicationID(ctx, publicationID)
+	if err != nil {
+		c.log.Error("publicationService.GetPublicationByPublicationID: %v", err)
+		return err
+	}
+
+	buttonsMarkup := markup.PublicationButtons(publicationID, publication.Buttons)
+	_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+		update.CallbackQuery.Message.MessageID,
+		&buttonsMarkup,
+		"Кнопки публикации:\n\n"+publication.Buttons.Text())
+	return err
+}
+
+// CallbackGetButtons - buttons_get_{publication_id}
+func (c *callbackPublication) CallbackGetButtons() tgbot.ViewFunc {
 	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
 		publicationID := GetID(update.CallbackData())
 		if publicationID == 0 {
-			c.log.Error("entity.GetID: failed to get id from channel button")
+			c.log.Error("entity.GetID: failed to get id from buttons button")
+			return customErr.ErrNotFound
+		}
+
+		return c.showButtons(ctx, update, publicationID)
+	}
+}
+
+// CallbackAddButtons - buttons_add_{publication_id}
+func (c *callbackPublication) CallbackAddButtons() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		publicationID := GetID(update.CallbackData())
+		if publicationID == 0 {
+			c.log.Error("entity.GetID: failed to get id from buttons button")
 			return customErr.ErrNotFound
 		}
 
-		text := "Отправьте подпись для кнопки"
+		text := "Отправьте кнопки в формате Текст - https://example.com\n\n" +
+			"Кнопки одного ряда разделяются |, каждый ряд с новой строки, например:\n" +
+			"Купить - https://example.com/buy | Подробнее - https://example.com\n" +
+			"Поддержка - https://t.me/support\n\n" +
+			"Новые ряды добавляются после существующих"
 		cancelCommandMarkup := markup.CancelCommandPublication(publicationID)
 		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
 			update.CallbackQuery.Message.MessageID,
@@ -254,7 +383,7 @@ func (c *callbackPublication) CallbackUpdatePublicationButtonText() tgbot.ViewFu
 		c.store.Set(&store.Data{
 			CurrentMsgID:  sentMsg,
 			PreferMsgID:   update.CallbackQuery.Message.MessageID,
-			OperationType: store.PublicationButtonTextUpdate,
+			OperationType: store.PublicationButtonsAdd,
 			ChannelID:     publicationID,
 		}, update.FromChat().ID)
 
@@ -262,76 +391,108 @@ func (c *callbackPublication) CallbackUpdatePublicationButtonText() tgbot.ViewFu
 	}
 }
 
-// CallbackUpdatePublicationButtonLink - buttonlink_update_{publication_id}
-func (c *callbackPublication) CallbackUpdatePublicationButtonLink() tgbot.ViewFunc {
+// CallbackDeleteButton - button_del_{publication_id}_{row}_{col}
+func (c *callbackPublication) CallbackDeleteButton() tgbot.ViewFunc {
 	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
-		publicationID := GetID(update.CallbackData())
-		if publicationID == 0 {
-			c.log.Error("entity.GetID: failed to get id from channel button")
+		ids, ok := GetIDs(update.CallbackData(), 3)
+		if !ok {
+			c.log.Error("GetIDs: failed to get ids from delete button")
 			return customErr.ErrNotFound
 		}
 
-		text := "Отправьте ссылку для кнопки"
-		cancelCommandMarkup := markup.CancelCommandPublication(publicationID)
-		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
-			update.CallbackQuery.Message.MessageID,
-			&cancelCommandMarkup,
-			text)
-		if err != nil {
+		if err := c.publicationService.DeleteButton(ctx, ids[0], ids[1], ids[2]); err != nil {
+			c.log.Error("publicationService.DeleteButton: %v", err)
 			return err
 		}
 
-		c.store.Set(&store.Data{
-			CurrentMsgID:  sentMsg,
-			PreferMsgID:   update.CallbackQuery.Message.MessageID,
-			OperationType: store.PublicationButtonLinkUpdate,
-			ChannelID:     publicationID,
-		}, update.FromChat().ID)
+		return c.showButtons(ctx, update, ids[0])
+	}
+}
 
-		return nil
+// CallbackMoveButtonRow - buttons_up_{publication_id}_{row}, buttons_down_{publication_id}_{row}
+func (c *callbackPublication) CallbackMoveButtonRow() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		ids, ok := GetIDs(update.CallbackData(), 2)
+		if !ok {
+			c.log.Error("GetIDs: failed to get ids from move button")
+			return customErr.ErrNotFound
+		}
+
+		shift := 1
+		if strings.HasPrefix(update.CallbackData(), "buttons_up_") {
+			shift = -1
+		}
+
+		if err := c.publicationService.MoveButtonRow(ctx, ids[0], ids[1], shift); err != nil {
+			c.log.Error("publicationService.MoveButtonRow: %v", err)
+			return err
+		}
+
+		return c.showButtons(ctx, update, ids[0])
 	}
 }
 
-// CallbackUpdatePublicationSentDate - sent-date_update_{publication_id}
-func (c *callbackPublication) CallbackUpdatePublicationSentDate() tgbot.ViewFunc {
+func (c *callbackPublication) showOptions(ctx context.Context, update *tgbotapi.Update, publicationID int) error {
+	publication, err := c.publicationService.GetPublicationByPublicationID(ctx, publicationID)
+	if err != nil {
+		c.log.Error("publicationService.GetPublicationByPublicationID: %v", err)
+		return err
+	}
+
+	optionsMarkup := markup.DeliveryOptions(publicationID, publication.Options)
+	_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+		update.CallbackQuery.Message.MessageID,
+		&optionsMarkup,
+		"Параметры доставки:\n\n"+publication.Options.Text())
+	return err
+}
+
+// CallbackGetOptions - options_get_{publication_id}
+func (c *callbackPublication) CallbackGetOptions() tgbot.ViewFunc {
 	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
 		publicationID := GetID(update.CallbackData())
 		if publicationID == 0 {
-			c.log.Error("entity.GetID: failed to get id from channel button")
+			c.log.Error("entity.GetID: failed to get id from options button")
 			return customErr.ErrNotFound
 		}
 
-		text := "Отправьте время и дату в формате: 2024-08-27 15:48"
-		cancelCommandMarkup := markup.CancelCommandPublication(publicationID)
-		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
-			update.CallbackQuery.Message.MessageID,
-			&cancelCommandMarkup,
-			text)
+		return c.showOptions(ctx, update, publicationID)
+	}
+}
+
+// CallbackToggleOption - opt_{option}_{publication_id}
+func (c *callbackPublication) CallbackToggleOption() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		publicationID := GetID(update.CallbackData())
+		if publicationID == 0 {
+			c.log.Error("entity.GetID: failed to get id from option button")
+			return customErr.ErrNotFound
+		}
+
+		option := entity.DeliveryOption(strings.Split(update.CallbackData(), "_")[1])
+		err := c.publicationService.ToggleOption(ctx, publicationID, option)
+		if errors.Is(err, service.ErrTextTooLong) {
+			return errors.New("ошибка: текст не помещается в одно сообщение, сначала сократите его")
+		}
 		if err != nil {
+			c.log.Error("publicationService.ToggleOption: %v", err)
 			return err
 		}
 
-		c.store.Set(&store.Data{
-			CurrentMsgID:  sentMsg,
-			PreferMsgID:   update.CallbackQuery.Message.MessageID,
-			OperationType: store.PublicationSentDateUpdate,
-			ChannelID:     publicationID,
-		}, update.FromChat().ID)
-
-		return nil
+		return c.showOptions(ctx, update, publicationID)
 	}
 }
 
-// CallbackUpdatePublicationDeleteDate - delete-date_update_{publication_id}
-func (c *callbackPublication) CallbackUpdatePublicationDeleteDate() tgbot.ViewFunc {
+// CallbackUpdatePreviewURL - opt_url_{publication_id}
+func (c *callbackPublication) CallbackUpdatePreviewURL() tgbot.ViewFunc {
 	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
 		publicationID := GetID(update.CallbackData())
 		if publicationID == 0 {
-			c.log.Error("entity.GetID: failed to get id from channel button")
+			c.log.Error("entity.GetID: failed to get id from option button")
 			return customErr.ErrNotFound
 		}
 
-		text := "Отправьте время и дату в формате: 2024-08-27 15:48"
+		text := "Отправьте ссылку, для которой показывать превью, или - чтобы показывать превью первой ссылки текста"
 		cancelCommandMarkup := markup.CancelCommandPublication(publicationID)
 		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
 			update.CallbackQuery.Message.MessageID,
@@ -344,7 +505,7 @@ func (c *callbackPublication) CallbackUpdatePublicationDeleteDate() tgbot.ViewFu
 		c.store.Set(&store.Data{
 			CurrentMsgID:  sentMsg,
 			PreferMsgID:   update.CallbackQuery.Message.MessageID,
-			OperationType: store.PublicationDeleteDateUpdate,
+			OperationType: store.PublicationPreviewURLUpdate,
 			ChannelID:     publicationID,
 		}, update.FromChat().ID)
 
@@ -384,27 +545,7 @@ func (c *callbackPublication) CallbackGetListForCancelPublication() tgbot.ViewFu
 			return customErr.ErrNotFound
 		}
 
-		channel, err := c.channelService.GetByID(ctx, channelID)
-		if err != nil {
-			return err
-		}
-
-		publicationMarkup, err := c.publicationService.GetAllPublicationsByChannelID(ctx, channelID, "delete")
-		if err != nil {
-			return err
-		}
-
-		text := fmt.Sprintf("Публикации для канала: **%s**\\n\\n❌ - ошибка при удалении|ошибка при отправке\\n\"+\n\t\t\t\"✅ - отправлено\\n⏱ - ожидает отправки\\n🗑 - удалено из канала"+
-			"\n\n Нажмите на публикацию чтобы ее *удалить*", channel.ChannelName)
-		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
-			update.CallbackQuery.Message.MessageID,
-			publicationMarkup,
-			text)
-		if err != nil {
-			return err
-		}
-
-		return nil
+		return c.sendPublicationList(ctx, update, entity.PublicationList{ChannelID: channelID, Mode: entity.ListDelete})
 	}
 }
 
@@ -417,19 +558,25 @@ func (c *callbackPublication) CallbackDeletePublication() tgbot.ViewFunc {
 			return customErr.ErrNotFound
 		}
 
-		if err := c.publicationService.DeletePublication(ctx, publicationID); err != nil {
-			c.log.Error("failed to delete publication: %v", err)
+		publication, err := c.publicationService.GetPublicationAndChannel(ctx, publicationID)
+		if err != nil {
+			c.log.Error("failed to GetPublicationAndChannel: %v", err)
+			return err
 		}
 
-		c.publicationArray.RemovePub(&store.PubData{
-			PublicationID: publicationID,
-		})
+		// задачи на отправку/удаление удаляются каскадно вместе с публикацией
+		if err = c.publicationService.DeletePublication(ctx, publicationID); err != nil {
+			c.log.Error("failed to delete publication: %v", err)
+		}
 
 		text := "Публикация удалена"
-		if _, err := c.tgMsg.SendNewMessage(update.FromChat().ID, nil, text); err != nil {
+		if _, err = c.tgMsg.SendNewMessage(update.FromChat().ID, nil, text); err != nil {
 			return err
 		}
 
+		if slot, ok := publication.QueueSlot(); ok {
+			return sendQueueGap(ctx, c.tgMsg, update, publication.ChannelID, slot)
+		}
 		return nil
 	}
 }
@@ -444,27 +591,160 @@ func (c *callbackPublication) CallbackCancelUpdate() tgbot.ViewFunc {
 
 		c.store.Delete(update.FromChat().ID)
 
-		publication, err := c.publicationService.GetAllPublicationByID(ctx, publicationID)
+		publication, err := c.publicationService.GetOnePublicationByID(ctx, publicationID)
 		if err != nil {
 			c.log.Error("failed to get publication: %v", err)
 			return err
 		}
 
-		publicationMarkup, err := c.publicationService.GetMarkupPublication(publication, "get")
+		return c.sendPublicationList(ctx, update, entity.PublicationList{ChannelID: int(publication.ChannelID)})
+	}
+}
+
+// heldJob returns job from callback {key}_{action}_{publication_id}_{job_id}, nil if job is not held anymore
+func (c *callbackPublication) heldJob(ctx context.Context, update *tgbotapi.Update) (*entity.Job, error) {
+	ids, ok := GetIDs(update.CallbackData(), 2)
+	if !ok {
+		c.log.Error("GetIDs: failed to get ids from overdue button")
+		return nil, customErr.ErrNotFound
+	}
+
+	job, err := c.jobService.GetByID(ctx, ids[1])
+	if errors.Is(err, customErr.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		c.log.Error("failed to get job: %v", err)
+		return nil, err
+	}
+
+	// задачу уже отправили, перенесли или отменили, возможно другим администратором
+	if job.State != entity.JobHeld || job.PublicationID != ids[0] {
+		return nil, nil
+	}
+	return job, nil
+}
+
+// CallbackOverduePublication - overdue_send_{publication_id}_{job_id}/overdue_reschedule_{publication_id}_{job_id}/overdue_drop_{publication_id}_{job_id}
+func (c *callbackPublication) CallbackOverduePublication() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		job, err := c.heldJob(ctx, update)
 		if err != nil {
 			return err
 		}
+		if job == nil {
+			_, err = c.tgMsg.SendEditMessage(update.FromChat().ID, update.CallbackQuery.Message.MessageID, nil,
+				"Публикация уже обработана")
+			return err
+		}
+		publicationID := job.PublicationID
+
+		var text string
+		switch {
+		case strings.HasPrefix(update.CallbackData(), "overdue_send_"):
+			// после периода тишины администратор сам решает, актуальна ли публикация
+			heldByBlackout := job.HoldReason != nil && *job.HoldReason == entity.HoldBlackout
+			if !heldByBlackout && time.Since(job.RunAt) > job.MaxLateness() {
+				text = fmt.Sprintf("Публикация #%d просрочена в канале %s больше чем на %d мин., отправка отклонена",
+					publicationID, job.ChannelName, job.MaxLatenessMinutes)
+				break
+			}
+
+			if err = c.jobService.Force(ctx, job.ID, entity.JobPublish); err != nil {
+				c.log.Error("failed to release job: %v", err)
+				return err
+			}
+			text = fmt.Sprintf("Публикация #%d поставлена на отправку в канал %s", publicationID, job.ChannelName)
+		case strings.HasPrefix(update.CallbackData(), "overdue_reschedule_"):
+			// дата основного канала переносится календарем публикации, здесь переносится только
+			// отправка в дополнительный канал, публикация и остальные каналы остаются в прежнее время
+			if job.TargetID == nil {
+				text = "Перенесите публикацию кнопкой «Изменить дату отправки» в ее настройках"
+				break
+			}
+
+			text = fmt.Sprintf("Дата отправки публикации #%d в канал %s\n\n%s", publicationID, job.ChannelName, dateInputText)
+			cancelCommandMarkup := markup.CancelCommandPublication(publicationID)
+			sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+				update.CallbackQuery.Message.MessageID,
+				&cancelCommandMarkup,
+				text)
+			if err != nil {
+				return err
+			}
+
+			c.store.Set(&store.Data{
+				Data:          job.ID,
+				CurrentMsgID:  sentMsg,
+				PreferMsgID:   update.CallbackQuery.Message.MessageID,
+				OperationType: store.PublicationTargetReschedule,
+				ChannelID:     *job.TargetID,
+			}, update.FromChat().ID)
+			return nil
+		case strings.HasPrefix(update.CallbackData(), "overdue_drop_"):
+			if err = c.jobService.Cancel(ctx, job.ID, entity.JobPublish); err != nil {
+				c.log.Error("failed to cancel job: %v", err)
+				return err
+			}
+
+			// отмена отправки в дополнительный канал не меняет статус публикации
+			if job.TargetID != nil {
+				err = c.targetService.UpdateStatus(ctx, *job.TargetID, entity.StatusErrorOnSending, errors.New("отправка отменена администратором"))
+			} else {
+				err = c.publicationService.UpdatePublicationStatus(ctx, publicationID, entity.StatusErrorOnSending)
+			}
+			if err != nil {
+				c.log.Error("failed to update publication status: %v", err)
+				return err
+			}
+			text = fmt.Sprintf("Отправка публикации #%d в канал %s отменена", publicationID, job.ChannelName)
+		}
+
+		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID, update.CallbackQuery.Message.MessageID, nil, text)
+		return err
+	}
+}
 
-		text := fmt.Sprintf("Публикации для канала: **%s**\n\n❌ - ошибка при удалении|ошибка при отправке\n"+
-			"✅ - отправлено\n⏱ - ожидает отправки\n🗑 - удалено из канала", publication[0].ChannelName)
-		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
-			update.CallbackQuery.Message.MessageID,
-			publicationMarkup,
-			text)
+// CallbackOverdueDelete - overduedel_send_{publication_id}_{job_id}/overduedel_drop_{publication_id}_{job_id},
+// удаление переносится календарем публикации
+func (c *callbackPublication) CallbackOverdueDelete() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		job, err := c.heldJob(ctx, update)
 		if err != nil {
 			return err
 		}
-
-		return nil
+		if job == nil {
+			_, err = c.tgMsg.SendEditMessage(update.FromChat().ID, update.CallbackQuery.Message.MessageID, nil,
+				"Публикация уже обработана")
+			return err
+		}
+		// удаление по сроку хранения не заполняет delete_date, поэтому решение принимается только по задаче удаления
+		publicationID := job.PublicationID
+
+		var text string
+		switch {
+		case strings.HasPrefix(update.CallbackData(), "overduedel_send_"):
+			if err = c.jobService.Release(ctx, job.ID, entity.JobDelete, time.Now()); err != nil {
+				c.log.Error("failed to release job: %v", err)
+				return err
+			}
+			text = fmt.Sprintf("Публикация #%d поставлена на удаление из канала %s", publicationID, job.ChannelName)
+		case strings.HasPrefix(update.CallbackData(), "overduedel_drop_"):
+			if err = c.jobService.Cancel(ctx, job.ID, entity.JobDelete); err != nil {
+				c.log.Error("failed to cancel job: %v", err)
+				return err
+			}
+			// дата удаления общая для публикации, задачи дополнительных каналов и вхождений серии ее не меняют
+			if job.TargetID == nil && job.OccurrenceID == nil {
+				if err = c.publicationService.ResetDeleteDate(ctx, publicationID); err != nil {
+					c.log.Error("failed to reset delete date: %v", err)
+					return err
+				}
+			}
+			text = fmt.Sprintf("Публикация #%d останется в канале %s", publicationID, job.ChannelName)
+		}
+
+		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID, update.CallbackQuery.Message.MessageID, nil, text)
+		return err
 	}
 }
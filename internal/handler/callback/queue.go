@@ -0,0 +1,174 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot"
+	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
+	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
+	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	customMsg "github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CallbackQueue interface {
+	CallbackAddToQueue() tgbot.ViewFunc
+	CallbackUpdateSlots() tgbot.ViewFunc
+	CallbackShiftQueue() tgbot.ViewFunc
+	CallbackKeepGap() tgbot.ViewFunc
+}
+
+type callbackQueue struct {
+	queueService service.QueueService
+	log          *logger.Logger
+	tgMsg        customMsg.Message
+	store        store.LocalStorage
+}
+
+func NewCallbackQueue(queueService service.QueueService,
+	log *logger.Logger,
+	tgMsg customMsg.Message,
+	store store.LocalStorage,
+) (CallbackQueue, error) {
+	if log == nil {
+		return nil, errors.New("logger is nil")
+	}
+	if queueService == nil {
+		return nil, errors.New("queueService is nil")
+	}
+	if tgMsg == nil {
+		return nil, errors.New("tgMsg is nil")
+	}
+	if store == nil {
+		return nil, errors.New("store is nil")
+	}
+
+	return &callbackQueue{
+		queueService: queueService,
+		log:          log,
+		tgMsg:        tgMsg,
+		store:        store,
+	}, nil
+}
+
+// CallbackAddToQueue - queue_add_{publication_id}
+func (c *callbackQueue) CallbackAddToQueue() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		publicationID := GetID(update.CallbackData())
+		if publicationID == 0 {
+			c.log.Error("entity.GetID: failed to get id from publication button")
+			return customErr.ErrNotFound
+		}
+
+		var text string
+		slot, err := c.queueService.Add(ctx, publicationID)
+		switch {
+		case errors.Is(err, service.ErrNoQueueSlots):
+			text = "У канала не заданы слоты очереди, задайте их в настройках канала"
+		case errors.Is(err, service.ErrQueueSeries):
+			text = "Повторяющуюся публикацию нельзя поставить в очередь"
+		case errors.Is(err, service.ErrQueueFull):
+			text = "Свободных слотов очереди не найдено, проверьте окна публикаций и периоды тишины канала"
+		case err != nil:
+			c.log.Error("queueService.Add: %v", err)
+			return err
+		default:
+			text = fmt.Sprintf("Публикация поставлена в очередь на %s", entity.FormatTime(&slot, entity.LocationFromContext(ctx)))
+		}
+
+		updatePublicationSettingsMarkup := markup.UpdatePublicationSettings(publicationID)
+		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&updatePublicationSettingsMarkup,
+			text)
+		return err
+	}
+}
+
+// CallbackUpdateSlots - slots_update_{channel_id}
+func (c *callbackQueue) CallbackUpdateSlots() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		channelID := GetID(update.CallbackData())
+		if channelID == 0 {
+			c.log.Error("entity.GetID: failed to get id from channel button")
+			return customErr.ErrNotFound
+		}
+
+		text := "Отправьте ежедневные слоты очереди в часовом поясе канала, например: 09:00, 13:00, 18:00, 21:00\n\n" +
+			"Чтобы отключить очередь, отправьте «нет»"
+		cancelCommandMarkup := markup.CancelCommandCreate(channelID)
+		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&cancelCommandMarkup,
+			text)
+		if err != nil {
+			return err
+		}
+
+		c.store.Set(&store.Data{
+			CurrentMsgID:  sentMsg,
+			PreferMsgID:   update.CallbackQuery.Message.MessageID,
+			OperationType: store.ChannelQueueSlotsUpdate,
+			ChannelID:     channelID,
+		}, update.FromChat().ID)
+
+		return nil
+	}
+}
+
+// CallbackShiftQueue - queue_shift_{channel_id}_{unix_time}
+func (c *callbackQueue) CallbackShiftQueue() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		parts := strings.Split(update.CallbackData(), "_")
+		if len(parts) != 4 {
+			return customErr.ErrNotFound
+		}
+		channelID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return customErr.ErrNotFound
+		}
+		unix, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			return customErr.ErrNotFound
+		}
+		freed := time.Unix(unix, 0).UTC()
+
+		var text string
+		if freed.Before(time.Now()) {
+			text = "Освободившийся слот уже прошел, очередь не изменена"
+		} else {
+			moved, err := c.queueService.Shift(ctx, channelID, freed)
+			if err != nil {
+				c.log.Error("queueService.Shift: %v", err)
+				return err
+			}
+			text = fmt.Sprintf("Очередь сдвинута, перенесено публикаций: %d", moved)
+		}
+
+		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID, update.CallbackQuery.Message.MessageID, nil, text)
+		return err
+	}
+}
+
+// CallbackKeepGap - queue_keep_{channel_id}
+func (c *callbackQueue) CallbackKeepGap() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		_, err := c.tgMsg.SendEditMessage(update.FromChat().ID, update.CallbackQuery.Message.MessageID, nil,
+			"Пробел в очереди оставлен")
+		return err
+	}
+}
+
+// sendQueueGap asks whether to shift channel queue into freed slot or to leave the gap
+func sendQueueGap(ctx context.Context, tgMsg customMsg.Message, update *tgbotapi.Update, channelID int64, slot time.Time) error {
+	gapMarkup := markup.QueueGap(int(channelID), slot.Unix())
+	_, err := tgMsg.SendNewMessage(update.FromChat().ID, &gapMarkup, entity.QueueGapText(slot, entity.LocationFromContext(ctx)))
+	return err
+}
@@ -0,0 +1,203 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot"
+	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
+	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
+	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	customMsg "github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type CallbackSeries interface {
+	CallbackGetSeries() tgbot.ViewFunc
+	CallbackUpdateSeriesRule() tgbot.ViewFunc
+	CallbackPauseSeries() tgbot.ViewFunc
+	CallbackResumeSeries() tgbot.ViewFunc
+	CallbackSkipOccurrence() tgbot.ViewFunc
+	CallbackEndSeries() tgbot.ViewFunc
+}
+
+type callbackSeries struct {
+	seriesService service.SeriesService
+	log           *logger.Logger
+	tgMsg         customMsg.Message
+	store         store.LocalStorage
+}
+
+func NewCallbackSeries(
+	seriesService service.SeriesService,
+	log *logger.Logger,
+	tgMsg customMsg.Message,
+	store store.LocalStorage,
+) (CallbackSeries, error) {
+	if log == nil {
+		return nil, errors.New("logger is nil")
+	}
+	if seriesService == nil {
+		return nil, errors.New("seriesService is nil")
+	}
+	if tgMsg == nil {
+		return nil, errors.New("tgMsg is nil")
+	}
+	if store == nil {
+		return nil, errors.New("store is nil")
+	}
+
+	return &callbackSeries{
+		seriesService: seriesService,
+		log:           log,
+		tgMsg:         tgMsg,
+		store:         store,
+	}, nil
+}
+
+// showSeries - редактирует сообщение панелью управления серией
+func (c *callbackSeries) showSeries(ctx context.Context, update *tgbotapi.Update, publicationID int, prefix string) error {
+	publication, next, occurrences, err := c.seriesService.GetSeries(ctx, publicationID)
+	if err != nil {
+		c.log.Error("SeriesService.GetSeries: %v", err)
+		return err
+	}
+
+	seriesMarkup := markup.SeriesSetting(publicationID, publication.IsSeries(), publication.SeriesPaused)
+	_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+		update.CallbackQuery.Message.MessageID,
+		&seriesMarkup,
+		prefix+publication.SeriesText(next, occurrences, entity.LocationFromContext(ctx)))
+	return err
+}
+
+// CallbackGetSeries - series_get_{publication_id}
+func (c *callbackSeries) CallbackGetSeries() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		publicationID := GetID(update.CallbackData())
+		if publicationID == 0 {
+			c.log.Error("entity.GetID: failed to get id from publication button")
+			return customErr.ErrNotFound
+		}
+
+		return c.showSeries(ctx, update, publicationID, "")
+	}
+}
+
+// CallbackUpdateSeriesRule - series_rule_{publication_id}
+func (c *callbackSeries) CallbackUpdateSeriesRule() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		publicationID := GetID(update.CallbackData())
+		if publicationID == 0 {
+			c.log.Error("entity.GetID: failed to get id from publication button")
+			return customErr.ErrNotFound
+		}
+
+		text := "Отправьте правило повторения.\n\n" +
+			"Cron: минута час день месяц день_недели, например 0 10 * * 1 - каждый понедельник в 10:00\n" +
+			"RRULE: например FREQ=MONTHLY;BYMONTHDAY=1;BYHOUR=9;BYMINUTE=0 - первого числа каждого месяца в 9:00"
+		cancelCommandMarkup := markup.CancelCommandPublication(publicationID)
+		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&cancelCommandMarkup,
+			text)
+		if err != nil {
+			return err
+		}
+
+		c.store.Set(&store.Data{
+			CurrentMsgID:  sentMsg,
+			PreferMsgID:   update.CallbackQuery.Message.MessageID,
+			OperationType: store.PublicationRecurrenceUpdate,
+			ChannelID:     publicationID,
+		}, update.FromChat().ID)
+
+		return nil
+	}
+}
+
+// CallbackPauseSeries - series_pause_{publication_id}
+func (c *callbackSeries) CallbackPauseSeries() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		publicationID := GetID(update.CallbackData())
+		if publicationID == 0 {
+			c.log.Error("entity.GetID: failed to get id from publication button")
+			return customErr.ErrNotFound
+		}
+
+		if err := c.seriesService.Pause(ctx, publicationID); err != nil {
+			c.log.Error("SeriesService.Pause: %v", err)
+			return err
+		}
+
+		return c.showSeries(ctx, update, publicationID, "Серия приостановлена\n\n")
+	}
+}
+
+// CallbackResumeSeries - series_resume_{publication_id}
+func (c *callbackSeries) CallbackResumeSeries() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		publicationID := GetID(update.CallbackData())
+		if publicationID == 0 {
+			c.log.Error("entity.GetID: failed to get id from publication button")
+			return customErr.ErrNotFound
+		}
+
+		ok, err := c.seriesService.Resume(ctx, publicationID)
+		if err != nil {
+			c.log.Error("SeriesService.Resume: %v", err)
+			return err
+		}
+		if !ok {
+			if err := c.seriesService.End(ctx, publicationID); err != nil {
+				c.log.Error("SeriesService.End: %v", err)
+				return err
+			}
+			return c.showSeries(ctx, update, publicationID, "У серии больше нет отправок, серия завершена\n\n")
+		}
+
+		return c.showSeries(ctx, update, publicationID, "Серия возобновлена\n\n")
+	}
+}
+
+// CallbackSkipOccurrence - series_skip_{publication_id}
+func (c *callbackSeries) CallbackSkipOccurrence() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		publicationID := GetID(update.CallbackData())
+		if publicationID == 0 {
+			c.log.Error("entity.GetID: failed to get id from publication button")
+			return customErr.ErrNotFound
+		}
+
+		ok, err := c.seriesService.Skip(ctx, publicationID)
+		if err != nil {
+			c.log.Error("SeriesService.Skip: %v", err)
+			return err
+		}
+		if !ok {
+			return c.showSeries(ctx, update, publicationID, "Пропущена последняя отправка, серия завершена\n\n")
+		}
+
+		return c.showSeries(ctx, update, publicationID, "Отправка пропущена\n\n")
+	}
+}
+
+// CallbackEndSeries - series_end_{publication_id}
+func (c *callbackSeries) CallbackEndSeries() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		publicationID := GetID(update.CallbackData())
+		if publicationID == 0 {
+			c.log.Error("entity.GetID: failed to get id from publication button")
+			return customErr.ErrNotFound
+		}
+
+		if err := c.seriesService.End(ctx, publicationID); err != nil {
+			c.log.Error("SeriesService.End: %v", err)
+			return err
+		}
+
+		return c.showSeries(ctx, update, publicationID, "Серия завершена\n\n")
+	}
+}
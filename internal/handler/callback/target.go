@@ -0,0 +1,199 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot"
+	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
+	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
+	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	customMsg "github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"strconv"
+	"strings"
+)
+
+type CallbackTarget interface {
+	CallbackGetTargets() tgbot.ViewFunc
+	CallbackToggleTarget() tgbot.ViewFunc
+	CallbackUpdateTargetOffset() tgbot.ViewFunc
+	CallbackAddGroup() tgbot.ViewFunc
+}
+
+type callbackTarget struct {
+	targetService service.TargetService
+	log           *logger.Logger
+	tgMsg         customMsg.Message
+	store         store.LocalStorage
+}
+
+func NewCallbackTarget(targetService service.TargetService,
+	log *logger.Logger,
+	tgMsg customMsg.Message,
+	store store.LocalStorage,
+) (CallbackTarget, error) {
+	if log == nil {
+		return nil, errors.New("logger is nil")
+	}
+	if targetService == nil {
+		return nil, errors.New("targetService is nil")
+	}
+	if tgMsg == nil {
+		return nil, errors.New("tgMsg is nil")
+	}
+	if store == nil {
+		return nil, errors.New("store is nil")
+	}
+
+	return &callbackTarget{
+		targetService: targetService,
+		log:           log,
+		tgMsg:         tgMsg,
+		store:         store,
+	}, nil
+}
+
+// showTargets - редактирует сообщение списком каналов публикации
+func (c *callbackTarget) showTargets(ctx context.Context, update *tgbotapi.Update, publicationID int, prefix string) error {
+	publication, targets, err := c.targetService.GetTargets(ctx, publicationID)
+	if err != nil {
+		c.log.Error("targetService.GetTargets: %v", err)
+		return err
+	}
+
+	targetsMarkup, err := c.targetService.TargetsMarkup(ctx, publication, targets)
+	if err != nil {
+		c.log.Error("targetService.TargetsMarkup: %v", err)
+		return err
+	}
+
+	_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+		update.CallbackQuery.Message.MessageID,
+		targetsMarkup,
+		prefix+entity.TargetsText(*publication, targets, entity.LocationFromContext(ctx)))
+	return err
+}
+
+// CallbackGetTargets - target_get_{publication_id}
+func (c *callbackTarget) CallbackGetTargets() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		publicationID := GetID(update.CallbackData())
+		if publicationID == 0 {
+			c.log.Error("entity.GetID: failed to get id from publication button")
+			return customErr.ErrNotFound
+		}
+
+		return c.showTargets(ctx, update, publicationID, "")
+	}
+}
+
+// CallbackToggleTarget - target_toggle_{publication_id}_{channel_id}
+func (c *callbackTarget) CallbackToggleTarget() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		parts := strings.Split(update.CallbackData(), "_")
+		if len(parts) != 4 {
+			return customErr.ErrNotFound
+		}
+		publicationID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return customErr.ErrNotFound
+		}
+		channelID, err := strconv.Atoi(parts[3])
+		if err != nil {
+			return customErr.ErrNotFound
+		}
+
+		var prefix string
+		added, err := c.targetService.Toggle(ctx, publicationID, channelID)
+		switch {
+		case errors.Is(err, service.ErrTargetPrimary):
+			prefix = "Это основной канал публикации.\n\n"
+		case errors.Is(err, service.ErrTargetSeries):
+			prefix = "Повторяющуюся публикацию нельзя отправить в несколько каналов.\n\n"
+		case errors.Is(err, service.ErrTargetSent):
+			prefix = "Публикация уже отправлена в этот канал, убрать его нельзя.\n\n"
+		case err != nil:
+			c.log.Error("targetService.Toggle: %v", err)
+			return err
+		case added:
+			prefix = "Канал добавлен.\n\n"
+		default:
+			prefix = "Канал убран.\n\n"
+		}
+
+		return c.showTargets(ctx, update, publicationID, prefix)
+	}
+}
+
+// CallbackUpdateTargetOffset - target_offset_{target_id}
+func (c *callbackTarget) CallbackUpdateTargetOffset() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		targetID := GetID(update.CallbackData())
+		if targetID == 0 {
+			c.log.Error("entity.GetID: failed to get id from target button")
+			return customErr.ErrNotFound
+		}
+
+		target, err := c.targetService.GetByID(ctx, targetID)
+		if err != nil {
+			c.log.Error("targetService.GetByID: %v", err)
+			return err
+		}
+
+		text := "Отправьте смещение отправки в канал " + target.ChannelName + " в минутах относительно даты отправки публикации " +
+			"и, при необходимости, смещение удаления, например: 30 60\n\nЧтобы убрать смещение, отправьте 0"
+		cancelCommandMarkup := markup.CancelCommandPublication(target.PublicationID)
+		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&cancelCommandMarkup,
+			text)
+		if err != nil {
+			return err
+		}
+
+		c.store.Set(&store.Data{
+			CurrentMsgID:  sentMsg,
+			PreferMsgID:   update.CallbackQuery.Message.MessageID,
+			OperationType: store.PublicationTargetOffset,
+			ChannelID:     targetID,
+		}, update.FromChat().ID)
+
+		return nil
+	}
+}
+
+// CallbackAddGroup - group_target_{publication_id}_{group_id}
+func (c *callbackTarget) CallbackAddGroup() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		parts := strings.Split(update.CallbackData(), "_")
+		if len(parts) != 4 {
+			return customErr.ErrNotFound
+		}
+		publicationID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return customErr.ErrNotFound
+		}
+		groupID, err := strconv.Atoi(parts[3])
+		if err != nil {
+			return customErr.ErrNotFound
+		}
+
+		var prefix string
+		added, err := c.targetService.AddGroup(ctx, publicationID, groupID)
+		switch {
+		case errors.Is(err, service.ErrTargetSeries):
+			prefix = "Повторяющуюся публикацию нельзя отправить в несколько каналов.\n\n"
+		case err != nil:
+			c.log.Error("targetService.AddGroup: %v", err)
+			return err
+		default:
+			prefix = fmt.Sprintf("Добавлено каналов группы: %d.\n\n", added)
+		}
+
+		return c.showTargets(ctx, update, publicationID, prefix)
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
 	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot"
 	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
 	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
 	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
 	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
 	customMsg "github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/button"
 	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -20,6 +23,7 @@ type CallbackUser interface {
 	AdminDeleteRole() tgbot.ViewFunc
 	AdminSetRole() tgbot.ViewFunc
 	MainMenu() tgbot.ViewFunc
+	UpdateTimezone() tgbot.ViewFunc
 }
 
 type callbackUser struct {
@@ -72,7 +76,7 @@ func (c *callbackUser) AdminRoleSetting() tgbot.ViewFunc {
 	}
 }
 
-// AdminLookUp - admin_look_up
+// AdminLookUp - admin_look_up/admin_look_up_{page}
 func (c *callbackUser) AdminLookUp() tgbot.ViewFunc {
 	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
 		admin, err := c.userService.GetAllAdmin(ctx)
@@ -81,15 +85,29 @@ func (c *callbackUser) AdminLookUp() tgbot.ViewFunc {
 			return customErr.ErrServerError
 		}
 
-		adminByte, err := json.MarshalIndent(admin, "", "\t")
+		pager := markup.Paginator{
+			Page:  GetPage(update.CallbackData()),
+			Total: len(admin),
+			Data:  func(page int) string { return fmt.Sprintf("admin_look_up_%d", page) },
+		}
+		from, to := pager.Bounds()
+
+		adminByte, err := json.MarshalIndent(admin[from:to], "", "\t")
 		if err != nil {
 			c.log.Error("AdminLookUp: create_post.json.MarshalIndent: %v", err)
 			return customErr.ErrServerError
 		}
 
+		var rows [][]tgbotapi.InlineKeyboardButton
+		if row := pager.Row(); row != nil {
+			rows = append(rows, row)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button.MainMenuButton))
+		adminMarkup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
 		if _, err := c.tgMsg.SendEditMessage(update.CallbackQuery.Message.Chat.ID,
 			update.CallbackQuery.Message.MessageID,
-			&markup.MainMenu,
+			&adminMarkup,
 			string(adminByte)); err != nil {
 			return err
 		}
@@ -142,6 +160,8 @@ func (c *callbackUser) AdminSetRole() tgbot.ViewFunc {
 
 func (c *callbackUser) MainMenu() tgbot.ViewFunc {
 	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		// возврат в меню отменяет незавершенный ввод
+		c.store.Delete(update.FromChat().ID)
 
 		if _, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
 			update.CallbackQuery.Message.MessageID,
@@ -153,3 +173,26 @@ func (c *callbackUser) MainMenu() tgbot.ViewFunc {
 		return nil
 	}
 }
+
+// UpdateTimezone - user_timezone
+func (c *callbackUser) UpdateTimezone() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		text := fmt.Sprintf("Ваш часовой пояс: %s\n\nВ нем вводятся и отображаются даты публикаций. "+
+			"Отправьте новый часовой пояс в формате IANA, например Europe/Moscow или Asia/Novosibirsk", entity.LocationFromContext(ctx))
+
+		if _, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&markup.MainMenu,
+			text); err != nil {
+			return err
+		}
+
+		c.store.Set(&store.Data{
+			OperationType: store.UserTimezoneUpdate,
+			CurrentMsgID:  update.CallbackQuery.Message.MessageID,
+			PreferMsgID:   update.CallbackQuery.Message.MessageID,
+		}, update.FromChat().ID)
+
+		return nil
+	}
+}
@@ -0,0 +1,246 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot"
+	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
+	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
+	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	customMsg "github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CallbackWindow interface {
+	CallbackUpdateWindows() tgbot.ViewFunc
+	CallbackAcceptSlot() tgbot.ViewFunc
+	CallbackGetBlackouts() tgbot.ViewFunc
+	CallbackAddBlackout() tgbot.ViewFunc
+	CallbackDeleteBlackout() tgbot.ViewFunc
+}
+
+type callbackWindow struct {
+	windowService      service.PostingWindowService
+	publicationService service.PublicationService
+	jobService         service.JobService
+	log                *logger.Logger
+	tgMsg              customMsg.Message
+	store              store.LocalStorage
+}
+
+func NewCallbackWindow(windowService service.PostingWindowService,
+	publicationService service.PublicationService,
+	jobService service.JobService,
+	log *logger.Logger,
+	tgMsg customMsg.Message,
+	store store.LocalStorage,
+) (CallbackWindow, error) {
+	if log == nil {
+		return nil, errors.New("logger is nil")
+	}
+	if windowService == nil {
+		return nil, errors.New("windowService is nil")
+	}
+	if publicationService == nil {
+		return nil, errors.New("publicationService is nil")
+	}
+	if jobService == nil {
+		return nil, errors.New("jobService is nil")
+	}
+	if tgMsg == nil {
+		return nil, errors.New("tgMsg is nil")
+	}
+	if store == nil {
+		return nil, errors.New("store is nil")
+	}
+
+	return &callbackWindow{
+		windowService:      windowService,
+		publicationService: publicationService,
+		jobService:         jobService,
+		log:                log,
+		tgMsg:              tgMsg,
+		store:              store,
+	}, nil
+}
+
+// CallbackUpdateWindows - window_update_{channel_id}
+func (c *callbackWindow) CallbackUpdateWindows() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		channelID := GetID(update.CallbackData())
+		if channelID == 0 {
+			c.log.Error("entity.GetID: failed to get id from channel button")
+			return customErr.ErrNotFound
+		}
+
+		text := "Отправьте разрешенные окна публикаций в часовом поясе канала, каждое окно с новой строки или через «;», например:\n" +
+			"пн-пт 08:00-23:00\nсб,вс 10:00-22:00\n\nЧтобы разрешить публикации в любое время, отправьте «нет»"
+		cancelCommandMarkup := markup.CancelCommandCreate(channelID)
+		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&cancelCommandMarkup,
+			text)
+		if err != nil {
+			return err
+		}
+
+		c.store.Set(&store.Data{
+			CurrentMsgID:  sentMsg,
+			PreferMsgID:   update.CallbackQuery.Message.MessageID,
+			OperationType: store.ChannelWindowsUpdate,
+			ChannelID:     channelID,
+		}, update.FromChat().ID)
+
+		return nil
+	}
+}
+
+// CallbackAcceptSlot - window_accept_{publication_id}_{unix_time}
+func (c *callbackWindow) CallbackAcceptSlot() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		parts := strings.Split(update.CallbackData(), "_")
+		if len(parts) != 4 {
+			return customErr.ErrNotFound
+		}
+		publicationID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return customErr.ErrNotFound
+		}
+		unix, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			return customErr.ErrNotFound
+		}
+		date := time.Unix(unix, 0).UTC()
+
+		publication, err := c.publicationService.GetPublicationAndChannel(ctx, publicationID)
+		if err != nil {
+			c.log.Error("failed to GetPublicationAndChannel: %v", err)
+			return err
+		}
+
+		// окна или периоды тишины могли измениться, пока администратор не нажал кнопку
+		allowed, _, err := c.windowService.Check(ctx, int(publication.ChannelID), date)
+		if err != nil {
+			c.log.Error("failed to check posting windows: %v", err)
+			return err
+		}
+		if !allowed || date.Before(time.Now()) {
+			_, err = c.tgMsg.SendEditMessage(update.FromChat().ID, update.CallbackQuery.Message.MessageID, nil,
+				"Предложенное время больше недоступно, установите дату отправки заново")
+			return err
+		}
+
+		if err = c.publicationService.UpdatePublicationDate(ctx, publicationID, date); err != nil {
+			c.log.Error("failed to UpdatePublicationDate: %v", err)
+			return err
+		}
+		if err = c.jobService.SchedulePublish(ctx, publicationID, date); err != nil {
+			c.log.Error("failed to SchedulePublish: %v", err)
+			return err
+		}
+
+		updatePublicationSettingsMarkup := markup.UpdatePublicationSettings(publicationID)
+		_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&updatePublicationSettingsMarkup,
+			fmt.Sprintf("Публикация #%d будет отправлена %s", publicationID, entity.FormatTime(&date, entity.LocationFromContext(ctx))))
+		if err != nil {
+			return err
+		}
+
+		if slot, ok := publication.QueueSlot(); ok && !slot.Equal(date) {
+			return sendQueueGap(ctx, c.tgMsg, update, publication.ChannelID, slot)
+		}
+		return nil
+	}
+}
+
+func (c *callbackWindow) showBlackouts(ctx context.Context, update *tgbotapi.Update, channelID int, prefix string) error {
+	blackouts, err := c.windowService.GetBlackouts(ctx, channelID)
+	if err != nil {
+		c.log.Error("windowService.GetBlackouts: %v", err)
+		return err
+	}
+
+	blackoutMarkup := markup.BlackoutSetting(channelID, entity.BlackoutIDs(blackouts))
+	_, err = c.tgMsg.SendEditMessage(update.FromChat().ID,
+		update.CallbackQuery.Message.MessageID,
+		&blackoutMarkup,
+		prefix+entity.BlackoutsText(blackouts, entity.LocationFromContext(ctx)))
+	return err
+}
+
+// CallbackGetBlackouts - blackout_get_{channel_id}
+func (c *callbackWindow) CallbackGetBlackouts() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		channelID := GetID(update.CallbackData())
+		if channelID == 0 {
+			c.log.Error("entity.GetID: failed to get id from channel button")
+			return customErr.ErrNotFound
+		}
+
+		return c.showBlackouts(ctx, update, channelID, "")
+	}
+}
+
+// CallbackAddBlackout - blackout_add_{channel_id}
+func (c *callbackWindow) CallbackAddBlackout() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		channelID := GetID(update.CallbackData())
+		if channelID == 0 {
+			c.log.Error("entity.GetID: failed to get id from channel button")
+			return customErr.ErrNotFound
+		}
+
+		text := "Отправьте начало и конец периода тишины и, при необходимости, причину в формате:\n" +
+			"2024-05-09 00:00 - 2024-05-10 00:00 День Победы"
+		cancelCommandMarkup := markup.CancelCommandCreate(channelID)
+		sentMsg, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&cancelCommandMarkup,
+			text)
+		if err != nil {
+			return err
+		}
+
+		c.store.Set(&store.Data{
+			CurrentMsgID:  sentMsg,
+			PreferMsgID:   update.CallbackQuery.Message.MessageID,
+			OperationType: store.ChannelBlackoutCreate,
+			ChannelID:     channelID,
+		}, update.FromChat().ID)
+
+		return nil
+	}
+}
+
+// CallbackDeleteBlackout - blackout_delete_{blackout_id}
+func (c *callbackWindow) CallbackDeleteBlackout() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		blackoutID := GetID(update.CallbackData())
+		if blackoutID == 0 {
+			c.log.Error("entity.GetID: failed to get id from blackout button")
+			return customErr.ErrNotFound
+		}
+
+		blackout, err := c.windowService.GetBlackout(ctx, blackoutID)
+		if err != nil {
+			c.log.Error("windowService.GetBlackout: %v", err)
+			return err
+		}
+
+		if err = c.windowService.DeleteBlackout(ctx, blackoutID); err != nil {
+			c.log.Error("windowService.DeleteBlackout: %v", err)
+			return err
+		}
+
+		return c.showBlackouts(ctx, update, blackout.ChannelID, "Период тишины удален.\n\n")
+	}
+}
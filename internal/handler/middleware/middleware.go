@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
 	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot"
 	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
 	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
@@ -44,7 +45,7 @@ func AdminMiddleware(service service.UserService, next tgbot.ViewFunc) tgbot.Vie
 		}
 
 		if user.UserRole == "admin" || user.UserRole == "superAdmin" {
-			return next(ctx, bot, update)
+			return next(entity.ContextWithLocation(ctx, user.Location()), bot, update)
 		}
 
 		return customErr.ErrIsNotAdmin
@@ -62,7 +63,7 @@ func SuperAdminMiddleware(service service.UserService, next tgbot.ViewFunc) tgbo
 		}
 
 		if user.UserRole == "superAdmin" {
-			return next(ctx, bot, update)
+			return next(entity.ContextWithLocation(ctx, user.Location()), bot, update)
 		}
 
 		return customErr.ErrIsNotAdmin
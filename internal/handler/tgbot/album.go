@@ -0,0 +1,143 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
+	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// album - альбом, который администратор отправляет в публикацию. Telegram присылает
+// каждый файл альбома отдельным сообщением с общим media_group_id
+type album struct {
+	mediaGroupID  string
+	publicationID int
+	preferMsgID   int
+	// draft - альбом отправлен при создании публикации и сохраняется в канал-хранилище
+	draft bool
+	// chatID, messageIDs - сообщения альбома, которые сохраняются в хранилище после получения последнего файла
+	chatID     int64
+	messageIDs []int
+	timer      *time.Timer
+	// stored - альбом уже сохранен, опоздавший файл делает копию в хранилище неполной
+	stored bool
+}
+
+// albumStoreDelay - telegram не сообщает о последнем файле альбома, файлы приходят почти одновременно,
+// поэтому альбом считается собранным, если после очередного файла других не было
+const albumStoreDelay = 2 * time.Second
+
+func (b *Bot) setAlbum(userID int64, a album) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.albums == nil {
+		b.albums = make(map[int64]album)
+	}
+	b.albums[userID] = a
+}
+
+// isAlbumProcessing - добавляет в публикацию следующий файл альбома, первый файл обработан операцией обновления вложений
+func (b *Bot) isAlbumProcessing(ctx context.Context, update *tgbotapi.Update) (bool, error) {
+	if update.Message.MediaGroupID == "" {
+		return false, nil
+	}
+
+	userID := update.Message.From.ID
+	b.mu.RLock()
+	a, ok := b.albums[userID]
+	b.mu.RUnlock()
+	if !ok || a.mediaGroupID != update.Message.MediaGroupID {
+		return false, nil
+	}
+
+	media, ok := messageMedia(update.Message)
+	if !ok {
+		return true, errors.New("ошибка: сообщение альбома не содержит вложения")
+	}
+
+	if _, err := b.publicationService.AddMedia(ctx, a.publicationID, media); err != nil {
+		b.log.Error("publicationService.AddMedia: %v", err)
+		if errors.Is(err, service.ErrAlbumFull) {
+			return true, errors.New("ошибка: в альбоме может быть не больше 10 файлов")
+		}
+		return true, err
+	}
+	if a.stored {
+		b.resetDraft(ctx, a.publicationID)
+	}
+	b.addDraftFile(update.Message)
+
+	b.response(ctx, store.PublicationImageUpdate, 0, a.preferMsgID, a.publicationID, update)
+	return true, nil
+}
+
+// addDraftFile - откладывает сохранение черновика альбома, пока приходят его файлы
+func (b *Bot) addDraftFile(message *tgbotapi.Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	userID := message.From.ID
+	a, ok := b.albums[userID]
+	if !ok || !a.draft || a.stored || a.mediaGroupID != message.MediaGroupID {
+		return
+	}
+	a.chatID = message.Chat.ID
+	a.messageIDs = append(a.messageIDs, message.MessageID)
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	a.timer = time.AfterFunc(albumStoreDelay, func() {
+		b.storeAlbum(userID, message.MediaGroupID)
+	})
+	b.albums[userID] = a
+}
+
+// storeAlbum - сохраняет собранный альбом в канал-хранилище, вызывается таймером вне обработки сообщения
+func (b *Bot) storeAlbum(userID int64, mediaGroupID string) {
+	b.mu.Lock()
+	a, ok := b.albums[userID]
+	if !ok || !a.draft || a.stored || a.mediaGroupID != mediaGroupID {
+		b.mu.Unlock()
+		return
+	}
+	a.stored = true
+	b.albums[userID] = a
+	b.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	// copyMessages принимает идентификаторы только по возрастанию, порядок получения файлов telegram не гарантирует
+	b.storeAlbumDraft(ctx, a.publicationID, a.chatID, slices.Sorted(slices.Values(a.messageIDs)))
+}
+
+// messageMedia - вложение сообщения администратора, у фото берется самый большой размер
+func messageMedia(message *tgbotapi.Message) (entity.Media, bool) {
+	media := entity.Media{MediaGroupID: message.MediaGroupID}
+
+	// у GIF telegram дополнительно заполняет document, поэтому animation проверяется раньше
+	switch {
+	case len(message.Photo) > 0:
+		media.Type, media.FileID = entity.MediaPhoto, message.Photo[len(message.Photo)-1].FileID
+	case message.Animation != nil:
+		media.Type, media.FileID = entity.MediaAnimation, message.Animation.FileID
+	case message.Video != nil:
+		media.Type, media.FileID = entity.MediaVideo, message.Video.FileID
+	case message.Document != nil:
+		media.Type, media.FileID = entity.MediaDocument, message.Document.FileID
+	case message.Audio != nil:
+		media.Type, media.FileID = entity.MediaAudio, message.Audio.FileID
+	case message.Voice != nil:
+		media.Type, media.FileID = entity.MediaVoice, message.Voice.FileID
+	case message.VideoNote != nil:
+		media.Type, media.FileID = entity.MediaVideoNote, message.VideoNote.FileID
+	default:
+		return entity.Media{}, false
+	}
+	return media, true
+}
@@ -27,10 +27,18 @@ type Bot struct {
 	channelService     service.ChannelService
 	publicationService service.PublicationService
 	callbackStore      *store.CallbackStorage
-	publicationArray   *store.PublicationArray
+	jobService         service.JobService
+	seriesService      service.SeriesService
+	windowService      service.PostingWindowService
+	queueService       service.QueueService
+	targetService      service.TargetService
+	groupService       service.GroupService
+	importService      service.ImportService
+	exportService      service.ExportService
 
 	cmdView      map[string]ViewFunc
 	callbackView map[string]ViewFunc
+	albums       map[int64]album
 
 	mu      sync.RWMutex
 	isDebug bool
@@ -44,7 +52,14 @@ func NewBot(bot *tgbotapi.BotAPI,
 	channelService service.ChannelService,
 	publicationService service.PublicationService,
 	callbackStore *store.CallbackStorage,
-	publicationArray *store.PublicationArray,
+	jobService service.JobService,
+	seriesService service.SeriesService,
+	windowService service.PostingWindowService,
+	queueService service.QueueService,
+	targetService service.TargetService,
+	groupService service.GroupService,
+	importService service.ImportService,
+	exportService service.ExportService,
 ) (*Bot, error) {
 	if log == nil {
 		return nil, errors.New("log is nil")
@@ -67,8 +82,29 @@ func NewBot(bot *tgbotapi.BotAPI,
 	if publicationService == nil {
 		return nil, errors.New("publicationService is nil")
 	}
-	if publicationArray == nil {
-		return nil, errors.New("publicationArray is nil")
+	if jobService == nil {
+		return nil, errors.New("jobService is nil")
+	}
+	if seriesService == nil {
+		return nil, errors.New("seriesService is nil")
+	}
+	if windowService == nil {
+		return nil, errors.New("windowService is nil")
+	}
+	if queueService == nil {
+		return nil, errors.New("queueService is nil")
+	}
+	if targetService == nil {
+		return nil, errors.New("targetService is nil")
+	}
+	if groupService == nil {
+		return nil, errors.New("groupService is nil")
+	}
+	if importService == nil {
+		return nil, errors.New("importService is nil")
+	}
+	if exportService == nil {
+		return nil, errors.New("exportService is nil")
 	}
 
 	return &Bot{
@@ -80,7 +116,14 @@ func NewBot(bot *tgbotapi.BotAPI,
 		channelService:     channelService,
 		publicationService: publicationService,
 		callbackStore:      callbackStore,
-		publicationArray:   publicationArray,
+		jobService:         jobService,
+		seriesService:      seriesService,
+		windowService:      windowService,
+		queueService:       queueService,
+		targetService:      targetService,
+		groupService:       groupService,
+		importService:      importService,
+		exportService:      exportService,
 	}, nil
 }
 
@@ -105,20 +148,36 @@ func (b *Bot) Run(ctx context.Context) error {
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
-	updates := b.bot.GetUpdatesChan(u)
 	for {
-		select {
-		case update := <-updates:
-			updateCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		updates, err := b.getUpdates(u)
+		// после отмены полученные обновления не обрабатываются и не подтверждаются, их получит следующий лидер
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			b.log.Error("failed to get updates, retrying in 3 seconds: %v", err)
+			select {
+			case <-time.After(3 * time.Second):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		for _, update := range updates {
+			if update.UpdateID < u.Offset {
+				continue
+			}
+			u.Offset = update.UpdateID + 1
+
+			updateCtx, cancel := context.WithTimeout(contextWithCustomEmoji(context.Background(), update.customEmoji), 5*time.Minute)
 
 			b.isDebug = false
-			b.jsonDebug(update)
+			b.jsonDebug(update.Update)
 
-			b.handlerUpdate(updateCtx, &update)
+			b.handlerUpdate(updateCtx, &update.Update)
 
 			cancel()
-		case <-ctx.Done():
-			return ctx.Err()
 		}
 	}
 }
@@ -144,6 +203,17 @@ func (b *Bot) handlerUpdate(ctx context.Context, update *tgbotapi.Update) {
 	if update.Message != nil {
 		b.log.Info("[%s] %s", update.Message.From.UserName, update.Message.Text)
 
+		isAlbum, err := b.isAlbumProcessing(ctx, update)
+		if err != nil {
+			b.log.Error("failed in isAlbumProcessing: %v", err)
+			handler.HandleError(b.bot, update, err)
+			return
+		}
+
+		if isAlbum {
+			return
+		}
+
 		isProcessing, err := b.isStoreProcessing(ctx, update)
 		if err != nil {
 			b.log.Error("failed in isStoreProcessing: %v", err)
@@ -0,0 +1,40 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"time"
+)
+
+var weekdayNames = [7]string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"}
+
+// confirmDate - дата, введенная сообщением, показывается полностью и сохраняется только после подтверждения
+// кнопкой календаря, поэтому ошибка в относительной дате видна до сохранения
+func (b *Bot) confirmDate(ctx context.Context, update *tgbotapi.Update, storeData *store.Data, kind int, date time.Time) error {
+	loc := entity.LocationFromContext(ctx)
+	local := date.In(loc)
+
+	title := fmt.Sprintf("Дата отправки публикации #%d", storeData.PublicationID)
+	if kind == markup.PickDeleteDate {
+		title = fmt.Sprintf("Дата удаления публикации #%d", storeData.PublicationID)
+	}
+	text := fmt.Sprintf("%s: %s, %s\n\nПодтвердите дату или настройте время.",
+		title, entity.FormatTime(&date, loc), weekdayNames[local.Weekday()])
+
+	if err := b.tgMsg.DeleteMessage(update.FromChat().ID, update.Message.MessageID); err != nil {
+		b.log.Error("failed to delete message id %d: %v", update.Message.MessageID, err)
+	}
+
+	picker := markup.DatePicker{
+		Kind: kind,
+		ID:   storeData.PublicationID,
+		Back: fmt.Sprintf("cancel_update_%d", storeData.PublicationID),
+	}
+	confirmMarkup := picker.Confirm(local)
+	_, err := b.tgMsg.SendEditMessage(update.FromChat().ID, storeData.PreferMsgID, &confirmMarkup, text)
+	return err
+}
@@ -2,54 +2,128 @@ package dto
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"strings"
 	"time"
 )
 
+// Button - кнопка в формате импорта: {"текст": "...", "ссылка": "..."}
 type Button struct {
-	ButtonUrl  *string `json:"текст_кнопки"`
-	ButtonText *string `json:"ссылка_кнопки"`
+	Text string `json:"текст"`
+	URL  string `json:"ссылка"`
+}
+
+// Media - вложение в формате импорта: {"тип": "photo", "file_id": "..."}, file_id должен быть получен этим ботом
+type Media struct {
+	Type   entity.MediaType `json:"тип"`
+	FileID string           `json:"file_id"`
 }
 
 type PublicationCreate struct {
-	PublicationDate time.Time  `json:"дата_публикации"`
-	DeleteDate      *time.Time `json:"дата_удаления"`
-	Button          Button     `json:"кнопка"`
+	// Channel - канал публикации: название, @username или telegram id
+	Channel         string         `json:"канал"`
+	Text            string         `json:"текст"`
+	Media           []entity.Media `json:"вложения"`
+	PublicationDate time.Time      `json:"дата_публикации"`
+	DeleteDate      *time.Time     `json:"дата_удаления"`
+	// Buttons - ряды кнопок из поля "кнопки", прежнее поле "кнопка" с одной кнопкой дает один ряд
+	Buttons entity.Buttons `json:"кнопки"`
+
+	// Location - часовой пояс администратора, в котором указаны даты, задается до разбора JSON.
+	// Без него даты берутся в entity.DefaultTimezone
+	Location *time.Location `json:"-"`
 }
 
-const Layout = "2006-01-02 15:04:05 -0700"
+const Layout = "2006-01-02 15:04"
+
+// layouts - даты также принимаются в формате 26.08.2024 21:12
+var layouts = []string{Layout, "02.01.2006 15:04"}
+
+// ParseDate parses date of import in location loc
+func ParseDate(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range layouts {
+		if date, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("неверная дата %q, ожидается формат %s", s, Layout)
+}
 
 func (c *PublicationCreate) UnmarshalJSON(b []byte) (err error) {
-	var jsonMap map[string]interface{}
+	var jsonMap map[string]json.RawMessage
 	err = json.Unmarshal(b, &jsonMap)
 	if err != nil {
 		return
 	}
 
-	publicationDateStr, _ := jsonMap["дата_публикации"].(string)
-	c.PublicationDate, err = time.Parse(Layout, publicationDateStr+":00 +0300")
+	loc := c.Location
+	if loc == nil {
+		loc = entity.LoadLocation(entity.DefaultTimezone)
+	}
+
+	// telegram id канала может быть указан числом
+	var channel json.Number
+	if json.Unmarshal(jsonMap["канал"], &channel) == nil {
+		c.Channel = channel.String()
+	} else {
+		_ = json.Unmarshal(jsonMap["канал"], &c.Channel)
+	}
+	_ = json.Unmarshal(jsonMap["текст"], &c.Text)
+
+	if raw, ok := jsonMap["вложения"]; ok {
+		var media []Media
+		if err = json.Unmarshal(raw, &media); err != nil {
+			return errors.New("вложения должны быть списком объектов с полями тип и file_id")
+		}
+		for _, m := range media {
+			c.Media = append(c.Media, entity.Media{Type: m.Type, FileID: m.FileID})
+		}
+	}
+
+	var publicationDateStr string
+	if json.Unmarshal(jsonMap["дата_публикации"], &publicationDateStr) != nil || publicationDateStr == "" {
+		return errors.New("не указана дата публикации")
+	}
+	c.PublicationDate, err = ParseDate(publicationDateStr, loc)
 	if err != nil {
 		return
 	}
 
-	deleteDateStr, ok := jsonMap["дата_удаления"].(string)
-	if ok {
+	var deleteDateStr string
+	if json.Unmarshal(jsonMap["дата_удаления"], &deleteDateStr) == nil && deleteDateStr != "" {
 		c.DeleteDate = new(time.Time)
-		*c.DeleteDate, err = time.Parse(Layout, deleteDateStr+":00 +0300")
+		*c.DeleteDate, err = ParseDate(deleteDateStr, loc)
 		if err != nil {
 			return
 		}
 	}
 
-	button, ok := jsonMap["кнопка"].(map[string]interface{})
-	if ok {
-		if btnUrl, urlExist := button["текст_кнопки"].(string); urlExist {
-			c.Button.ButtonUrl = new(string)
-			*c.Button.ButtonUrl = btnUrl
+	if raw, ok := jsonMap["кнопки"]; ok {
+		var rows [][]Button
+		if err = json.Unmarshal(raw, &rows); err != nil {
+			return errors.New("кнопки должны быть списком рядов из объектов с полями текст и ссылка")
+		}
+		for _, row := range rows {
+			buttons := make([]entity.Button, 0, len(row))
+			for _, button := range row {
+				buttons = append(buttons, entity.Button{Text: button.Text, URL: button.URL})
+			}
+			c.Buttons = append(c.Buttons, buttons)
 		}
-		if btnText, textExist := button["ссылка_кнопки"].(string); textExist {
-			c.Button.ButtonText = new(string)
-			*c.Button.ButtonText = btnText
+	}
+
+	var legacy struct {
+		Text string `json:"текст_кнопки"`
+		URL  string `json:"ссылка_кнопки"`
+	}
+	if raw, ok := jsonMap["кнопка"]; ok {
+		if err = json.Unmarshal(raw, &legacy); err != nil {
+			return errors.New("кнопка должна быть объектом с полями текст_кнопки и ссылка_кнопки")
 		}
+		c.Buttons = append(c.Buttons, []entity.Button{{Text: legacy.Text, URL: legacy.URL}})
 	}
 
 	return
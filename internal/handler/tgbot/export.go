@@ -0,0 +1,39 @@
+package tgbot
+
+import (
+	"context"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// exportPeriod - сохраняет введенный период в фильтре и возвращает панель выгрузки
+func (b *Bot) exportPeriod(ctx context.Context, update *tgbotapi.Update, storeData *store.Data) error {
+	filter, ok := storeData.Data.(entity.ExportFilter)
+	if !ok {
+		b.log.Error("exportPeriod: store data is not export filter: %v", storeData.Data)
+		return nil
+	}
+
+	from, to, err := ParseExportPeriod(ctx, update.Message.Text)
+	if err != nil {
+		return err
+	}
+	filter = filter.WithPeriod(&from, &to)
+
+	loc := entity.LocationFromContext(ctx)
+	title, count, err := b.exportService.Preview(ctx, filter, loc)
+	if err != nil {
+		b.log.Error("exportPeriod: exportService.Preview: %v", err)
+		return err
+	}
+
+	if err = b.tgMsg.DeleteMessage(update.FromChat().ID, update.Message.MessageID); err != nil {
+		b.log.Error("failed to delete message id %d: %v", update.Message.MessageID, err)
+	}
+
+	exportMarkup := markup.Export(filter)
+	_, err = b.tgMsg.SendEditMessage(update.FromChat().ID, storeData.PreferMsgID, &exportMarkup, entity.ExportText(title, count, filter))
+	return err
+}
@@ -0,0 +1,307 @@
+package tgbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot/dto"
+	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxImportSize - файл импорта загружается в память целиком
+const maxImportSize = 1 << 20
+
+// importRow - публикация из файла импорта, err - ошибка разбора или проверки
+type importRow struct {
+	line        int
+	publication dto.PublicationCreate
+	err         error
+}
+
+// importPublications - проверяет каждую публикацию файла и отправляет отчет. Публикации сохраняются
+// только после подтверждения администратора и только если в файле нет ошибок
+func (b *Bot) importPublications(ctx context.Context, update *tgbotapi.Update, storeData *store.Data) error {
+	document := update.Message.Document
+	if document == nil {
+		return errors.New("ошибка: отправьте файл .json или .csv")
+	}
+	if document.FileSize > maxImportSize {
+		return errors.New("ошибка: файл импорта больше 1 МБ, разделите его на несколько")
+	}
+
+	data, err := b.downloadFile(document.FileID)
+	if err != nil {
+		b.log.Error("importPublications: downloadFile: %v", err)
+		return errors.New("ошибка: не удалось загрузить файл")
+	}
+
+	loc := entity.LocationFromContext(ctx)
+	var (
+		rows  []importRow
+		label string
+	)
+	switch strings.ToLower(path.Ext(document.FileName)) {
+	case ".json":
+		rows, err = parseImportJSON(data, loc)
+		label = "Публикация"
+	case ".csv":
+		rows, err = parseImportCSV(data, loc)
+		label = "Строка"
+	default:
+		return errors.New("ошибка: поддерживаются только файлы .json и .csv")
+	}
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return errors.New("ошибка: в файле нет публикаций")
+	}
+
+	channels, err := b.channelService.GetAll(ctx)
+	if err != nil {
+		b.log.Error("importPublications: GetAll: %v", err)
+		return err
+	}
+
+	var (
+		publications = make([]entity.Publication, 0, len(rows))
+		report       = make([]string, 0, len(rows))
+		failed       int
+	)
+	for _, row := range rows {
+		var publication entity.Publication
+		if row.err == nil {
+			publication, row.err = importPublication(row.publication, channels, loc)
+		}
+		if row.err != nil {
+			failed++
+			report = append(report, fmt.Sprintf("%s %d: ❌ %s", label, row.line, strings.TrimPrefix(row.err.Error(), "ошибка: ")))
+			continue
+		}
+
+		publications = append(publications, publication)
+		report = append(report, fmt.Sprintf("%s %d: ✅ %s, %s", label, row.line,
+			publication.ChannelName, entity.FormatTime(publication.PublicationDate, loc)))
+	}
+
+	text := fmt.Sprintf("Проверка файла %s\n\nПубликаций: %d, с ошибками: %d\n\n", document.FileName, len(rows), failed)
+	resultMarkup := markup.ImportRetry
+	if failed == 0 {
+		importID, err := b.importService.Prepare(ctx, update.Message.From.ID, publications)
+		if err != nil {
+			b.log.Error("importPublications: Prepare: %v", err)
+			return err
+		}
+		resultMarkup = markup.ImportConfirm(importID)
+		text += "Ничего не сохранено до подтверждения, все публикации будут созданы одной операцией.\n\n"
+	} else {
+		text += "Исправьте ошибки и загрузите файл заново, публикации из файла с ошибками не создаются.\n\n"
+	}
+	text += truncateReport(report, entity.MaxTextLength-entity.TextLength(text))
+
+	if _, err = b.tgMsg.SendEditMessage(update.FromChat().ID, storeData.PreferMsgID, &resultMarkup, text); err != nil {
+		b.log.Error("importPublications: SendEditMessage: %v", err)
+		return err
+	}
+	return nil
+}
+
+// importPublication - проверяет публикацию из файла и находит ее канал
+func importPublication(row dto.PublicationCreate, channels []entity.Channel, loc *time.Location) (entity.Publication, error) {
+	channel, ok := findChannel(channels, row.Channel)
+	if !ok {
+		return entity.Publication{}, fmt.Errorf("канал %q не найден", row.Channel)
+	}
+	if row.Text == "" && len(row.Media) == 0 {
+		return entity.Publication{}, errors.New("нет ни текста, ни вложений")
+	}
+	if err := PublicationCreateValidation(row, loc); err != nil {
+		return entity.Publication{}, err
+	}
+	if err := ValidateMedia(row.Media); err != nil {
+		return entity.Publication{}, err
+	}
+
+	publication := entity.Publication{
+		ChannelID:       int64(channel.ID),
+		ChannelName:     channel.ChannelName,
+		Text:            row.Text,
+		Media:           row.Media,
+		Buttons:         row.Buttons,
+		PublicationDate: &row.PublicationDate,
+		DeleteDate:      row.DeleteDate,
+	}
+	return publication, PublicationTextLengthValidation(&publication)
+}
+
+// findChannel - канал указывается названием, @username или telegram id
+func findChannel(channels []entity.Channel, ref string) (*entity.Channel, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, false
+	}
+
+	tgID, idErr := strconv.ParseInt(ref, 10, 64)
+	username, isUsername := strings.CutPrefix(ref, "@")
+	for i, channel := range channels {
+		switch {
+		case idErr == nil && channel.TgID == tgID:
+			return &channels[i], true
+		case isUsername && channel.ChannelUrl != nil && strings.EqualFold(*channel.ChannelUrl, "t.me/"+username):
+			return &channels[i], true
+		case strings.EqualFold(channel.ChannelName, ref):
+			return &channels[i], true
+		}
+	}
+	return nil, false
+}
+
+// parseImportJSON - файл содержит список публикаций или одну публикацию в формате create_post.json
+func parseImportJSON(data []byte, loc *time.Location) ([]importRow, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		data = append(append([]byte{'['}, data...), ']')
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("ошибка: файл не является JSON со списком публикаций: %v", err)
+	}
+
+	rows := make([]importRow, 0, len(items))
+	for i, item := range items {
+		row := importRow{line: i + 1, publication: dto.PublicationCreate{Location: loc}}
+		row.err = json.Unmarshal(item, &row.publication)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseImportCSV - первая строка содержит названия колонок как в JSON, разделитель запятая или точка с запятой.
+// Вложения перечисляются через пробел в виде тип:file_id, кнопки в формате добавления кнопок
+func parseImportCSV(data []byte, loc *time.Location) ([]importRow, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	if header, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка: не удалось прочитать заголовок CSV: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["дата_публикации"]; !ok {
+		return nil, errors.New("ошибка: в CSV нет колонки дата_публикации")
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			rows = append(rows, importRow{line: line, err: fmt.Errorf("ошибка разбора CSV: %v", err)})
+			continue
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row := importRow{line: line}
+		row.publication, row.err = csvPublication(value, loc)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func csvPublication(value func(column string) string, loc *time.Location) (dto.PublicationCreate, error) {
+	publication := dto.PublicationCreate{
+		Channel:  value("канал"),
+		Text:     value("текст"),
+		Location: loc,
+	}
+
+	var err error
+	if value("дата_публикации") == "" {
+		return publication, errors.New("не указана дата публикации")
+	}
+	if publication.PublicationDate, err = dto.ParseDate(value("дата_публикации"), loc); err != nil {
+		return publication, err
+	}
+	if deleteDate := value("дата_удаления"); deleteDate != "" {
+		publication.DeleteDate = new(time.Time)
+		if *publication.DeleteDate, err = dto.ParseDate(deleteDate, loc); err != nil {
+			return publication, err
+		}
+	}
+
+	for _, item := range strings.Fields(value("вложения")) {
+		mediaType, fileID, ok := strings.Cut(item, ":")
+		if !ok {
+			return publication, fmt.Errorf("вложение %q должно быть в формате тип:file_id", item)
+		}
+		publication.Media = append(publication.Media, entity.Media{Type: entity.MediaType(mediaType), FileID: fileID})
+	}
+
+	if buttons := value("кнопки"); buttons != "" {
+		if publication.Buttons, err = ParseButtons(buttons); err != nil {
+			return publication, err
+		}
+	}
+	return publication, nil
+}
+
+// truncateReport - отчет обрезается по лимиту сообщения, количество непоказанных строк указывается в конце
+func truncateReport(lines []string, limit int) string {
+	var report strings.Builder
+	for i, line := range lines {
+		rest := fmt.Sprintf("\n… и еще %d", len(lines)-i)
+		if entity.TextLength(report.String()+line+"\n")+entity.TextLength(rest) > limit {
+			report.WriteString(rest)
+			break
+		}
+		report.WriteString(line + "\n")
+	}
+	return report.String()
+}
+
+func (b *Bot) downloadFile(fileID string) ([]byte, error) {
+	fileURL, err := b.bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(fileURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status of file download: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxImportSize))
+}
@@ -3,6 +3,7 @@ package tgbot
 import (
 	"context"
 	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
 	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
 	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -13,7 +14,7 @@ const (
 )
 
 // response - возвращает ответ администратору
-func (b *Bot) response(operationType store.TypeCommand, currentMessageId int, preferMessageId int, channelID int, update *tgbotapi.Update) {
+func (b *Bot) response(ctx context.Context, operationType store.TypeCommand, currentMessageId int, preferMessageId int, channelID int, update *tgbotapi.Update) {
 	var (
 		messageId int
 		userID    = update.FromChat().ID
@@ -25,24 +26,24 @@ func (b *Bot) response(operationType store.TypeCommand, currentMessageId int, pr
 		messageId = update.CallbackQuery.Message.MessageID
 	}
 
-	if resp, err := b.bot.Request(tgbotapi.NewDeleteMessage(userID, messageId)); nil != err || !resp.Ok {
-		b.log.Error("failed to delete message id %d (%s): %v", messageId, string(resp.Result), err)
+	if err := b.tgMsg.DeleteMessage(userID, messageId); err != nil {
+		b.log.Error("failed to delete message id %d: %v", messageId, err)
 	}
 
 	// Выполнять удаление сообщения только для определенных операций
 	if value, _ := store.MapTypes[operationType]; value == store.Admin {
-		if resp, err := b.bot.Request(tgbotapi.NewDeleteMessage(userID, currentMessageId)); nil != err || !resp.Ok {
-			b.log.Error("failed to delete message id %d (%s): %v", currentMessageId, string(resp.Result), err)
+		if err := b.tgMsg.DeleteMessage(userID, currentMessageId); err != nil {
+			b.log.Error("failed to delete message id %d: %v", currentMessageId, err)
 		}
 	}
 
-	text, markup := b.responseText(operationType, channelID)
+	text, markup := b.responseText(ctx, operationType, channelID)
 	if _, err := b.tgMsg.SendEditMessage(userID, preferMessageId, markup, text); err != nil {
 		b.log.Error("failed to send telegram message: ", err)
 	}
 }
 
-func (b *Bot) responseText(operationType store.TypeCommand, channelID int) (string, *tgbotapi.InlineKeyboardMarkup) {
+func (b *Bot) responseText(ctx context.Context, operationType store.TypeCommand, channelID int) (string, *tgbotapi.InlineKeyboardMarkup) {
 	switch operationType {
 	case store.AdminCreate:
 		return success + "Пользователь получил администраторские права.", &markup.UserSetting
@@ -51,20 +52,107 @@ func (b *Bot) responseText(operationType store.TypeCommand, channelID int) (stri
 	case store.PublicationCreate:
 		keyMarkup := markup.ChannelSetting(channelID)
 		return success + "Публикация добавлена.", &keyMarkup
-	case store.PublicationTextUpdate, store.PublicationImageUpdate, store.PublicationButtonTextUpdate,
-		store.PublicationSentDateUpdate, store.PublicationDeleteDateUpdate, store.PublicationButtonLinkUpdate:
-		publication, err := b.publicationService.GetPublicationAndChannel(context.Background(), channelID)
+	case store.PublicationTextUpdate, store.PublicationImageUpdate,
+		store.PublicationSentDateUpdate, store.PublicationDeleteDateUpdate:
+		publication, err := b.publicationService.GetPublicationAndChannel(ctx, channelID)
 		if err != nil {
 			b.log.Error("failed to GetPublicationAndChannel: %v", err)
 			return "Ошибка получения данных канала", nil
 		}
 
+		loc := entity.LocationFromContext(ctx)
 		text := fmt.Sprintf("*Изменение публикации*\n\n"+
 			"Канал: %s\n"+
-			"Время удаления: %v\n"+
-			"Время отправления: %v", publication.ChannelName, publication.DeleteDate, publication.PublicationDate)
+			"Вложения: %s\n"+
+			"Кнопок: %d\n"+
+			"Символов: %s\n"+
+			"Время удаления: %s\n"+
+			"Время отправления: %s", publication.ChannelName, publication.MediaText(), publication.Buttons.Count(), publication.LengthText(), publication.DeleteText(loc), entity.FormatTime(publication.PublicationDate, loc))
 		updatePublicationSettingsMarkup := markup.UpdatePublicationSettings(channelID)
 		return text, &updatePublicationSettingsMarkup
+	case store.PublicationButtonsAdd:
+		publication, err := b.publicationService.GetPublicationByPublicationID(ctx, channelID)
+		if err != nil {
+			b.log.Error("failed to GetPublicationByPublicationID: %v", err)
+			return "Ошибка получения данных публикации", nil
+		}
+
+		keyMarkup := markup.PublicationButtons(channelID, publication.Buttons)
+		return success + "Кнопки публикации:\n\n" + publication.Buttons.Text(), &keyMarkup
+	case store.PublicationPreviewURLUpdate:
+		publication, err := b.publicationService.GetPublicationByPublicationID(ctx, channelID)
+		if err != nil {
+			b.log.Error("failed to GetPublicationByPublicationID: %v", err)
+			return "Ошибка получения данных публикации", nil
+		}
+
+		keyMarkup := markup.DeliveryOptions(channelID, publication.Options)
+		return success + "Параметры доставки:\n\n" + publication.Options.Text(), &keyMarkup
+	case store.PublicationRecurrenceUpdate:
+		publication, next, occurrences, err := b.seriesService.GetSeries(ctx, channelID)
+		if err != nil {
+			b.log.Error("failed to GetSeries: %v", err)
+			return "Ошибка получения данных публикации", nil
+		}
+
+		keyMarkup := markup.SeriesSetting(channelID, publication.IsSeries(), publication.SeriesPaused)
+		return success + publication.SeriesText(next, occurrences, entity.LocationFromContext(ctx)), &keyMarkup
+	case store.PublicationTargetOffset, store.PublicationTargetReschedule:
+		target, err := b.targetService.GetByID(ctx, channelID)
+		if err != nil {
+			b.log.Error("failed to GetByID: %v", err)
+			return "Ошибка получения данных публикации", nil
+		}
+		publication, targets, err := b.targetService.GetTargets(ctx, target.PublicationID)
+		if err != nil {
+			b.log.Error("failed to GetTargets: %v", err)
+			return "Ошибка получения данных публикации", nil
+		}
+		keyMarkup, err := b.targetService.TargetsMarkup(ctx, publication, targets)
+		if err != nil {
+			b.log.Error("failed to TargetsMarkup: %v", err)
+			return "Ошибка получения данных каналов", nil
+		}
+
+		return success + entity.TargetsText(*publication, targets, entity.LocationFromContext(ctx)), keyMarkup
+	case store.ChannelGroupCreate, store.ChannelGroupBroadcast, store.ChannelGroupReschedule:
+		group, err := b.channelService.GetGroup(ctx, channelID)
+		if err != nil {
+			b.log.Error("failed to GetGroup: %v", err)
+			return "Ошибка получения данных группы", nil
+		}
+		keyMarkup, err := b.channelService.GroupMarkup(ctx, group)
+		if err != nil {
+			b.log.Error("failed to GroupMarkup: %v", err)
+			return "Ошибка получения данных каналов", nil
+		}
+
+		text := success
+		if operationType == store.ChannelGroupBroadcast {
+			text += "Рассылка отправляется в каналы группы.\n\n"
+		}
+		return text + group.Text(), keyMarkup
+	case store.UserTimezoneUpdate:
+		return success + fmt.Sprintf("Часовой пояс изменен на %s.", entity.LocationFromContext(ctx)), &markup.StartMenu
+	case store.ChannelBlackoutCreate:
+		blackouts, err := b.windowService.GetBlackouts(ctx, channelID)
+		if err != nil {
+			b.log.Error("failed to GetBlackouts: %v", err)
+			return "Ошибка получения данных канала", nil
+		}
+
+		keyMarkup := markup.BlackoutSetting(channelID, entity.BlackoutIDs(blackouts))
+		return success + "Период тишины добавлен.\n\n" + entity.BlackoutsText(blackouts, entity.LocationFromContext(ctx)), &keyMarkup
+	case store.ChannelMaxLatenessUpdate, store.ChannelMaxAttemptsUpdate, store.ChannelTimezoneUpdate, store.ChannelWindowsUpdate,
+		store.ChannelQueueSlotsUpdate:
+		channel, err := b.channelService.GetByID(ctx, channelID)
+		if err != nil {
+			b.log.Error("failed to GetByID: %v", err)
+			return "Ошибка получения данных канала", nil
+		}
+
+		keyMarkup := markup.ChannelSetting(channelID)
+		return success + channel.SettingsText(), &keyMarkup
 	}
 	return success, nil
 }
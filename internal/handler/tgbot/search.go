@@ -0,0 +1,36 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"html"
+	"strings"
+)
+
+// searchPublications - запоминает фразу для перехода по страницам и отправляет первую страницу результатов поиска
+func (b *Bot) searchPublications(ctx context.Context, update *tgbotapi.Update, storeData *store.Data) error {
+	search := strings.TrimSpace(update.Message.Text)
+	if search == "" {
+		_, err := b.tgMsg.SendNewMessage(update.FromChat().ID, nil, "Отправьте фразу для поиска текстом")
+		return err
+	}
+	b.store.Set(&store.Data{
+		Data:          search,
+		CurrentMsgID:  storeData.CurrentMsgID,
+		PreferMsgID:   storeData.PreferMsgID,
+		OperationType: store.PublicationSearch,
+	}, update.FromChat().ID)
+
+	publicationMarkup, total, err := b.publicationService.GetPublicationList(ctx, entity.PublicationList{}, search, entity.LocationFromContext(ctx))
+	if err != nil {
+		b.log.Error("searchPublications: publicationService.GetPublicationList: %v", err)
+		return err
+	}
+
+	title := fmt.Sprintf("Результаты поиска: %s\nОтправьте другую фразу для нового поиска", html.EscapeString(search))
+	_, err = b.tgMsg.SendNewMessage(update.FromChat().ID, publicationMarkup, entity.PublicationListText(title, total))
+	return err
+}
@@ -0,0 +1,53 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	customMsg "github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// storeDraft - копирует черновик администратора в канал-хранилище, с которого публикация отправляется копированием.
+// Возвращает false, если черновик не сохранен и публикация будет собрана из полей
+func (b *Bot) storeDraft(ctx context.Context, publicationID int, message *tgbotapi.Message) bool {
+	storedID, err := b.tgMsg.StoreMessage(message.Chat.ID, message.MessageID)
+	if err != nil {
+		if !errors.Is(err, customMsg.ErrStorageDisabled) {
+			b.log.Error("storeDraft: StoreMessage: %v", err)
+		}
+		return false
+	}
+
+	if err = b.publicationService.UpdateStorageMessageIDs(ctx, publicationID, []int64{int64(storedID)}); err != nil {
+		b.log.Error("storeDraft: %v", err)
+		return false
+	}
+	return true
+}
+
+// storeAlbumDraft - копирует собранный альбом в канал-хранилище одним запросом, иначе копии
+// файлов в хранилище не сгруппированы и публикация не отправится альбомом
+func (b *Bot) storeAlbumDraft(ctx context.Context, publicationID int, chatID int64, messageIDs []int) {
+	storedIDs, err := b.tgMsg.StoreMessages(chatID, messageIDs)
+	if err != nil {
+		if !errors.Is(err, customMsg.ErrStorageDisabled) {
+			b.log.Error("storeAlbumDraft: StoreMessages: %v", err)
+		}
+		return
+	}
+
+	ids := make([]int64, 0, len(storedIDs))
+	for _, id := range storedIDs {
+		ids = append(ids, int64(id))
+	}
+	if err = b.publicationService.UpdateStorageMessageIDs(ctx, publicationID, ids); err != nil {
+		b.log.Error("storeAlbumDraft: %v", err)
+	}
+}
+
+// resetDraft - после изменения текста или вложений сохраненный черновик устарел, публикация собирается из полей
+func (b *Bot) resetDraft(ctx context.Context, publicationID int) {
+	if err := b.publicationService.UpdateStorageMessageIDs(ctx, publicationID, nil); err != nil {
+		b.log.Error("resetDraft: %v", err)
+	}
+}
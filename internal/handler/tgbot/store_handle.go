@@ -2,24 +2,23 @@ package tgbot
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/Enthreeka/tg-posting-bot/internal/entity"
-	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot/dto"
+	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
+	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
 	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
+	"github.com/Enthreeka/tg-posting-bot/pkg/ttl"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
-	"unicode/utf16"
+	"unicode/utf8"
 )
 
-var needEscape = make(map[rune]struct{})
-
-func init() {
-	for _, r := range []rune{'_', '*', '[', ']', '(', ')', '~', '`', '>', '#', '+', '-', '=', '|', '{', '}', '.', '!'} {
-		needEscape[r] = struct{}{}
-	}
-}
-
 func (b *Bot) isStateExist(userID int64) (*store.Data, bool) {
 	data, exist := b.store.Read(userID)
 	return data, exist
@@ -31,7 +30,17 @@ func (b *Bot) isStoreProcessing(ctx context.Context, update *tgbotapi.Update) (b
 	if !isExist || storeData == nil {
 		return false, nil
 	}
-	defer b.store.Delete(userID)
+	defer func() {
+		// в режиме поиска каждое сообщение - новый запрос, режим завершается выходом в главное меню
+		if storeData.OperationType != store.PublicationSearch {
+			b.store.Delete(userID)
+		}
+	}()
+
+	// даты вводятся в часовом поясе администратора
+	if user, err := b.userService.GetUserByID(ctx, userID); err == nil {
+		ctx = entity.ContextWithLocation(ctx, user.Location())
+	}
 
 	return b.switchStoreData(ctx, update, storeData)
 }
@@ -52,140 +61,402 @@ func (b *Bot) switchStoreData(ctx context.Context, update *tgbotapi.Update, stor
 			b.log.Error("isStoreExist::store.AdminDelete:userRepo.UpdateRoleByUsername: %v", err)
 		}
 	case store.PublicationCreate:
+		// черновик можно отправить любым сообщением: текстом, вложением с подписью или альбомом
+		text, entities := update.Message.Text, update.Message.Entities
+		media, withMedia := messageMedia(update.Message)
+		if withMedia {
+			text, entities = update.Message.Caption, update.Message.CaptionEntities
+		}
 
-		_, err = b.publicationService.CreatePublicationOnlyWithText(ctx, ConvertToMarkdownV2(update.Message.Text, update.Message.Entities), storeData.ChannelID)
+		var publicationID int
+		publicationID, err = b.publicationService.CreatePublicationOnlyWithText(ctx, text, messageEntities(ctx, entities), storeData.ChannelID)
 		if err != nil {
 			b.log.Error("isStoreExist::store.PublicationCreate: %v", err)
+			break
 		}
+		if withMedia {
+			if _, err = b.publicationService.AddMedia(ctx, publicationID, media); err != nil {
+				b.log.Error("isStoreExist::store.PublicationCreate: AddMedia: %v", err)
+				break
+			}
+		}
+
+		if media.MediaGroupID == "" {
+			b.storeDraft(ctx, publicationID, update.Message)
+			break
+		}
+		b.setAlbum(update.Message.From.ID, album{
+			mediaGroupID:  media.MediaGroupID,
+			publicationID: publicationID,
+			preferMsgID:   storeData.PreferMsgID,
+			draft:         true,
+		})
+		b.addDraftFile(update.Message)
 	case store.PublicationTextUpdate:
-		// todo переделать с storeData.ChannelID на storeData.PublicationID
-		if err = b.publicationService.UpdatePublicationText(ctx, storeData.ChannelID, ConvertToMarkdownV2(update.Message.Text, update.Message.Entities)); err != nil {
+		var publication *entity.Publication
+		if publication, err = b.publicationService.GetPublicationByPublicationID(ctx, storeData.PublicationID); err != nil {
 			b.log.Error("isStoreExist::store.PublicationTextUpdate: %v", err)
+			return true, err
 		}
+		publication.Text, publication.ParseMode = update.Message.Text, ""
+		if err = PublicationTextLengthValidation(publication); err != nil {
+			return true, err
+		}
+
+		if err = b.publicationService.UpdatePublicationText(ctx, storeData.PublicationID, update.Message.Text, messageEntities(ctx, update.Message.Entities)); err != nil {
+			b.log.Error("isStoreExist::store.PublicationTextUpdate: %v", err)
+			break
+		}
+		b.resetDraft(ctx, storeData.PublicationID)
 	case store.PublicationImageUpdate:
-		largestPhoto := update.Message.Photo[len(update.Message.Photo)-1]
-		// todo переделать с storeData.ChannelID на storeData.PublicationID
-		if err = b.publicationService.UpdatePublicationImage(ctx, storeData.ChannelID, &largestPhoto.FileID); err != nil {
+		media, ok := messageMedia(update.Message)
+		if !ok {
+			return true, errors.New("ошибка: сообщение не содержит вложения")
+		}
+
+		var publication *entity.Publication
+		if publication, err = b.publicationService.GetPublicationByPublicationID(ctx, storeData.PublicationID); err != nil {
+			b.log.Error("isStoreExist::store.PublicationImageUpdate: %v", err)
+			return true, err
+		}
+		// с вложением текст становится подписью, у которой меньше лимит; первый файл альбома проверяется как альбом
+		publication.Media = []entity.Media{media}
+		if err = PublicationTextLimitValidation(publication, publication.TextLimitFor(media.MediaGroupID != "")); err != nil {
+			return true, err
+		}
+		if _, err = b.publicationService.AddMedia(ctx, storeData.PublicationID, media); err != nil {
 			b.log.Error("isStoreExist::store.PublicationTextImage: %v", err)
+			return true, err
+		}
+		b.resetDraft(ctx, storeData.PublicationID)
+		// остальные файлы альбома приходят отдельными сообщениями после завершения операции
+		if media.MediaGroupID != "" {
+			b.setAlbum(update.Message.From.ID, album{
+				mediaGroupID:  media.MediaGroupID,
+				publicationID: storeData.PublicationID,
+				preferMsgID:   storeData.PreferMsgID,
+			})
 		}
-	case store.PublicationButtonTextUpdate:
-		// todo переделать с storeData.ChannelID на storeData.PublicationID
-		if err = b.publicationService.UpdatePublicationButtonText(ctx, storeData.ChannelID, update.Message.Text); err != nil {
-			b.log.Error("isStoreExist::store.PublicationButtonTextUpdate: %v", err)
+	case store.PublicationButtonsAdd:
+		var rows entity.Buttons
+		if rows, err = ParseButtons(update.Message.Text); err != nil {
+			return true, err
+		}
+
+		if err = b.publicationService.AddButtons(ctx, storeData.ChannelID, rows); err != nil {
+			b.log.Error("isStoreExist::store.PublicationButtonsAdd: %v", err)
+			return true, err
 		}
-	case store.PublicationButtonLinkUpdate:
-		// todo переделать с storeData.ChannelID на storeData.PublicationID
-		if _, err = url.ParseRequestURI(update.Message.Text); err != nil {
-			b.log.Error("isStoreExist::store.PublicationButtonLinkUpdate: %v", err)
+	case store.PublicationPreviewURLUpdate:
+		previewURL := strings.TrimSpace(update.Message.Text)
+		if previewURL == "-" {
+			previewURL = ""
+		} else if _, err = url.ParseRequestURI(previewURL); err != nil {
+			b.log.Error("isStoreExist::store.PublicationPreviewURLUpdate: %v", err)
 			return true, errors.New("ошибка: невалидная ссылка")
 		}
 
-		if err = b.publicationService.UpdatePublicationButtonLink(ctx, storeData.ChannelID, update.Message.Text); err != nil {
-			b.log.Error("isStoreExist::store.PublicationButtonLinkUpdate: %v", err)
+		if err = b.publicationService.UpdatePreviewURL(ctx, storeData.ChannelID, previewURL); err != nil {
+			b.log.Error("isStoreExist::store.PublicationPreviewURLUpdate: %v", err)
+			return true, err
 		}
 	case store.PublicationDeleteDateUpdate:
-		var (
-			date time.Time
-		)
-		date, err = time.Parse(dto.Layout, update.Message.Text+":00 +0300")
+		var publication *entity.Publication
+		publication, err = b.publicationService.GetPublicationAndChannel(ctx, storeData.PublicationID)
 		if err != nil {
 			b.log.Error("isStoreExist::store.PublicationDeleteDateUpdate: %v", err)
 			return true, err
 		}
 
-		if err = PublicationUpdateDateValidation(date); err != nil {
-			return true, err
+		// время жизни (24h, 1d12h) отсчитывается от фактической отправки, иначе ожидается дата
+		deleteTTL, ttlErr := ttl.Parse(update.Message.Text)
+		if ttlErr != nil {
+			var date time.Time
+			date, err = ParseDate(ctx, update.Message.Text)
+			if err != nil {
+				b.log.Error("isStoreExist::store.PublicationDeleteDateUpdate: %v", err)
+				return true, errors.New("ошибка: не удалось распознать дату, отправьте например 2024-08-27 15:48, " +
+					"завтра 10:00, пн 18:30 или через 2 часа, либо время жизни, например 24h или 1d12h")
+			}
+
+			if err = PublicationDeleteDateValidation(date, publication.PublicationDate, entity.LocationFromContext(ctx)); err != nil {
+				return true, err
+			}
+			return true, b.confirmDate(ctx, update, storeData, markup.PickDeleteDate, date)
 		}
 
-		// todo переделать с storeData.ChannelID на storeData.PublicationID
-		if err = b.publicationService.UpdateDeleteDate(ctx, storeData.ChannelID, date); err != nil {
+		if err = b.publicationService.UpdateDeleteTTL(ctx, storeData.PublicationID, deleteTTL); err != nil {
 			b.log.Error("isStoreExist::store.PublicationDeleteDateUpdate: %v", err)
+			return true, err
 		}
+		publication.DeleteTTL = &deleteTTL
 
-		// удаление происходит в [scheduled.go] в случае успешной отправки сообщения
+		// удаление происходит в [scheduled.go] в случае успешной отправки сообщения,
+		// если публикация уже отправлена - переносим удаление
+		if publication.PublicationStatus == entity.StatusSent && len(publication.MessageIDs) > 0 {
+			sentAt := time.Now()
+			if publication.SentAt != nil {
+				sentAt = *publication.SentAt
+			}
+			if deleteAt, ok := publication.DeleteAt(sentAt); ok {
+				if err = b.jobService.ScheduleDelete(ctx, publication.ID, deleteAt,
+					publication.TelegramChannelID, publication.MessageIDs); err != nil {
+					b.log.Error("isStoreExist::store.PublicationDeleteDateUpdate: %v", err)
+					return true, err
+				}
+			}
+		}
+		if err = b.targetService.RescheduleDeletes(ctx, publication); err != nil {
+			b.log.Error("isStoreExist::store.PublicationDeleteDateUpdate: %v", err)
+			return true, err
+		}
 
 	case store.PublicationSentDateUpdate:
-		var (
-			date time.Time
-		)
-		date, err = time.Parse(dto.Layout, update.Message.Text+":00 +0300")
+		var date time.Time
+		date, err = ParseDate(ctx, update.Message.Text)
 		if err != nil {
 			b.log.Error("isStoreExist::store.PublicationSentDateUpdate: %v", err)
+			return true, errDateInput
+		}
+
+		if err = PublicationUpdateDateValidation(date, entity.LocationFromContext(ctx)); err != nil {
 			return true, err
 		}
+		// окна публикаций и очередь канала проверяются при подтверждении даты
+		return true, b.confirmDate(ctx, update, storeData, markup.PickSentDate, date)
 
-		if err = PublicationUpdateDateValidation(date); err != nil {
+	case store.PublicationTargetReschedule:
+		// storeData.ChannelID - id дополнительного канала, в Data задержанная задача его отправки
+		jobID, _ := storeData.Data.(int)
+		var date time.Time
+		if date, err = ParseDate(ctx, update.Message.Text); err != nil {
+			b.log.Error("isStoreExist::store.PublicationTargetReschedule: %v", err)
+			return true, errDateInput
+		}
+		if err = PublicationUpdateDateValidation(date, entity.LocationFromContext(ctx)); err != nil {
+			return true, err
+		}
+		if err = b.jobService.Release(ctx, jobID, entity.JobPublish, date); err != nil {
+			b.log.Error("isStoreExist::store.PublicationTargetReschedule: %v", err)
 			return true, err
 		}
 
-		// todo переделать с storeData.ChannelID на storeData.PublicationID
-		if err = b.publicationService.UpdatePublicationDate(ctx, storeData.ChannelID, date); err != nil {
-			b.log.Error("isStoreExist::store.PublicationSentDateUpdate: %v", err)
+	case store.PublicationRecurrenceUpdate:
+		var targets []entity.Target
+		if _, targets, err = b.targetService.GetTargets(ctx, storeData.ChannelID); err != nil {
+			b.log.Error("isStoreExist::store.PublicationRecurrenceUpdate: %v", err)
+			return true, err
 		}
-		if err == nil {
-			b.log.Info("set publication date: date=%v channelID=%d", date, storeData.ChannelID)
-			b.publicationArray.AppendPub(&store.PubData{
-				PubDate:       date,
-				PublicationID: storeData.ChannelID,
-			})
+		if len(targets) > 0 {
+			return true, errors.New("ошибка: повторение недоступно для публикации в несколько каналов, сначала уберите дополнительные каналы")
+		}
+
+		var next time.Time
+		next, err = b.seriesService.SetRule(ctx, storeData.ChannelID, update.Message.Text)
+		if errors.Is(err, service.ErrNoPublicationDate) {
+			return true, errors.New("ошибка: сначала установите дату отправки, она станет первой отправкой серии")
+		}
+		if err != nil {
+			b.log.Error("isStoreExist::store.PublicationRecurrenceUpdate: %v", err)
+			return true, fmt.Errorf("ошибка: неверное правило повторения: %v", err)
+		}
+		b.log.Info("set publication recurrence: rule=%s next=%v publicationID=%d", update.Message.Text, next, storeData.ChannelID)
+
+	case store.PublicationTargetOffset:
+		fields := strings.Fields(update.Message.Text)
+		offsets := make([]int, 2)
+		for i, field := range fields {
+			if i >= len(offsets) {
+				err = errors.New("too many values")
+				break
+			}
+			if offsets[i], err = strconv.Atoi(field); err != nil || offsets[i] < 0 {
+				err = errors.New("invalid offset")
+				break
+			}
+		}
+		if err != nil || len(fields) == 0 {
+			return true, errors.New("ошибка: отправьте смещение отправки и, при необходимости, удаления в минутах, например: 30 60")
+		}
+
+		if err = b.targetService.UpdateOffsets(ctx, storeData.ChannelID, offsets[0], offsets[1]); err != nil {
+			b.log.Error("isStoreExist::store.PublicationTargetOffset: %v", err)
+		}
+
+	case store.ChannelMaxLatenessUpdate:
+		var minutes int
+		minutes, err = strconv.Atoi(strings.TrimSpace(update.Message.Text))
+		if err != nil || minutes < 0 {
+			return true, errors.New("ошибка: необходимо отправить целое число минут")
+		}
+
+		if err = b.channelService.UpdateMaxLateness(ctx, storeData.ChannelID, minutes); err != nil {
+			b.log.Error("isStoreExist::store.ChannelMaxLatenessUpdate: %v", err)
+		}
+
+	case store.ChannelMaxAttemptsUpdate:
+		var attempts int
+		attempts, err = strconv.Atoi(strings.TrimSpace(update.Message.Text))
+		if err != nil || attempts < 1 {
+			return true, errors.New("ошибка: необходимо отправить целое число попыток, не меньше 1")
+		}
+
+		if err = b.channelService.UpdateMaxSendAttempts(ctx, storeData.ChannelID, attempts); err != nil {
+			b.log.Error("isStoreExist::store.ChannelMaxAttemptsUpdate: %v", err)
+		}
+
+	case store.ChannelTimezoneUpdate:
+		var loc *time.Location
+		loc, err = ParseTimezone(update.Message.Text)
+		if err != nil {
+			return true, err
+		}
+
+		if err = b.channelService.UpdateTimezone(ctx, storeData.ChannelID, loc.String()); err != nil {
+			b.log.Error("isStoreExist::store.ChannelTimezoneUpdate: %v", err)
+		}
+
+	case store.ChannelWindowsUpdate:
+		spec := strings.TrimSpace(update.Message.Text)
+		if strings.EqualFold(spec, "нет") {
+			spec = ""
+		}
+
+		if err = b.windowService.UpdateWindows(ctx, storeData.ChannelID, spec); err != nil {
+			b.log.Error("isStoreExist::store.ChannelWindowsUpdate: %v", err)
+			return true, fmt.Errorf("ошибка: неверный формат окон публикаций: %v", err)
+		}
+
+	case store.ChannelBlackoutCreate:
+		var blackout *entity.Blackout
+		blackout, err = ParseBlackout(ctx, update.Message.Text)
+		if err != nil {
+			return true, err
+		}
+		blackout.ChannelID = storeData.ChannelID
+
+		if _, err = b.windowService.AddBlackout(ctx, blackout); err != nil {
+			b.log.Error("isStoreExist::store.ChannelBlackoutCreate: %v", err)
+		}
+
+	case store.ChannelQueueSlotsUpdate:
+		spec := strings.TrimSpace(update.Message.Text)
+		if strings.EqualFold(spec, "нет") {
+			spec = ""
+		}
+
+		if err = b.queueService.UpdateSlots(ctx, storeData.ChannelID, spec); err != nil {
+			b.log.Error("isStoreExist::store.ChannelQueueSlotsUpdate: %v", err)
+			return true, fmt.Errorf("ошибка: неверный формат слотов очереди: %v", err)
+		}
+
+	case store.ChannelGroupCreate:
+		name := strings.TrimSpace(update.Message.Text)
+		if name == "" || utf8.RuneCountInString(name) > 150 {
+			return true, errors.New("ошибка: название группы должно быть от 1 до 150 символов")
+		}
+
+		var groupID int
+		groupID, err = b.channelService.CreateGroup(ctx, name)
+		if errors.Is(err, customErr.ErrUniqueViolation) {
+			return true, errors.New("ошибка: группа с таким названием уже существует")
 		}
+		if err != nil {
+			b.log.Error("isStoreExist::store.ChannelGroupCreate: %v", err)
+		}
+		// после создания показывается панель новой группы
+		storeData.ChannelID = groupID
+
+	case store.ChannelGroupBroadcast:
+		var publicationID int
+		publicationID, err = b.groupService.Broadcast(ctx, storeData.ChannelID, update.Message.Text, messageEntities(ctx, update.Message.Entities))
+		if errors.Is(err, service.ErrGroupEmpty) {
+			return true, errors.New("ошибка: в группе нет каналов")
+		}
+		if err != nil {
+			b.log.Error("isStoreExist::store.ChannelGroupBroadcast: %v", err)
+			break
+		}
+		b.storeDraft(ctx, publicationID, update.Message)
+		b.log.Info("broadcast publication %d to group %d", publicationID, storeData.ChannelID)
+
+	case store.ChannelGroupReschedule:
+		var shift time.Duration
+		shift, err = ParseShift(update.Message.Text)
+		if err != nil {
+			return true, err
+		}
+
+		var moved int
+		moved, err = b.groupService.Reschedule(ctx, storeData.ChannelID, shift)
+		if errors.Is(err, service.ErrGroupEmpty) {
+			return true, errors.New("ошибка: в группе нет каналов")
+		}
+		if err != nil {
+			b.log.Error("isStoreExist::store.ChannelGroupReschedule: %v", err)
+			return true, err
+		}
+		if _, err = b.tgMsg.SendNewMessage(update.FromChat().ID, nil, fmt.Sprintf("Перенесено публикаций: %d", moved)); err != nil {
+			b.log.Error("isStoreExist::store.ChannelGroupReschedule: %v", err)
+		}
+
+	case store.PublicationImport:
+		// отчет о проверке файла заменяет приглашение загрузить файл
+		return true, b.importPublications(ctx, update, storeData)
+
+	case store.PublicationExport:
+		// панель выгрузки с новым периодом заменяет приглашение ввести период
+		return true, b.exportPeriod(ctx, update, storeData)
+
+	case store.PublicationSearch:
+		// результаты поиска отправляются новым сообщением под фразой поиска
+		return true, b.searchPublications(ctx, update, storeData)
+
+	case store.UserTimezoneUpdate:
+		var loc *time.Location
+		loc, err = ParseTimezone(update.Message.Text)
+		if err != nil {
+			return true, err
+		}
+
+		if err = b.userService.UpdateTimezone(ctx, update.Message.From.ID, loc.String()); err != nil {
+			b.log.Error("isStoreExist::store.UserTimezoneUpdate: %v", err)
+		}
+		ctx = entity.ContextWithLocation(ctx, loc)
 
 	default:
 		return false, nil
 	}
 
 	if err == nil {
-		b.response(storeData.OperationType, storeData.CurrentMsgID, storeData.PreferMsgID, storeData.ChannelID, update)
+		id := storeData.ChannelID
+		if storeData.PublicationID != 0 {
+			id = storeData.PublicationID
+		}
+		b.response(ctx, storeData.OperationType, storeData.CurrentMsgID, storeData.PreferMsgID, id, update)
 	}
 	return true, err
 }
 
-func ConvertToMarkdownV2(text string, messageEntities []tgbotapi.MessageEntity) string {
-	insertions := make(map[int]string)
+// messageEntities - форматирование сообщения администратора сохраняется без преобразования в разметку.
+// custom_emoji_id библиотека бота не декодирует, он берется из исходного update
+func messageEntities(ctx context.Context, messageEntities []tgbotapi.MessageEntity) []entity.MessageEntity {
+	entities := make([]entity.MessageEntity, 0, len(messageEntities))
 	for _, e := range messageEntities {
-		var before, after string
-		if e.IsBold() {
-			before = "*"
-			after = "*"
-		} else if e.IsItalic() {
-			before = "_"
-			after = "_"
-		} else if e.Type == "underline" {
-			before = "__"
-			after = "__"
-		} else if e.Type == "strikethrough" {
-			before = "~"
-			after = "~"
-			//} else if e.Type == "spoiler" {
-			//	before = "||"
-			//	after = "||"
-		} else if e.IsCode() {
-			before = "`"
-			after = "`"
-		} else if e.IsPre() {
-			before = "```" + e.Language
-			after = "```"
-		} else if e.IsTextLink() {
-			before = "["
-			after = "](" + e.URL + ")"
-		}
-		if before != "" {
-			insertions[e.Offset] += before
-			insertions[e.Offset+e.Length] += after
+		messageEntity := entity.MessageEntity{
+			Type:     e.Type,
+			Offset:   e.Offset,
+			Length:   e.Length,
+			URL:      e.URL,
+			Language: e.Language,
 		}
-	}
-
-	input := []rune(text)
-	var output []rune
-	utf16pos := 0
-	for _, c := range input {
-		output = append(output, []rune(insertions[utf16pos])...)
-		if _, has := needEscape[c]; has {
-			output = append(output, '\\')
+		if e.User != nil {
+			messageEntity.User, _ = json.Marshal(e.User)
+		}
+		if e.Type == "custom_emoji" {
+			messageEntity.CustomEmojiID = customEmojiID(ctx, e.Offset)
 		}
-		output = append(output, c)
-		utf16pos += len(utf16.Encode([]rune{c}))
+		entities = append(entities, messageEntity)
 	}
-	output = append(output, []rune(insertions[utf16pos])...)
-	return string(output)
+	return entities
 }
@@ -0,0 +1,73 @@
+package tgbot
+
+import (
+	"context"
+	"encoding/json"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// rawUpdate - update вместе с данными, которые библиотека бота не декодирует
+type rawUpdate struct {
+	tgbotapi.Update
+	// customEmoji - custom_emoji_id форматирования сообщения по смещению эмодзи в тексте или подписи
+	customEmoji map[int]string
+}
+
+// messageCustomEmoji - форматирование сообщения в том виде, как его прислал telegram. У сообщения есть
+// либо текст, либо подпись, поэтому эмодзи из обоих списков не пересекаются
+type messageCustomEmoji struct {
+	Message *struct {
+		Entities        []customEmojiEntity `json:"entities"`
+		CaptionEntities []customEmojiEntity `json:"caption_entities"`
+	} `json:"message"`
+}
+
+type customEmojiEntity struct {
+	Offset        int    `json:"offset"`
+	CustomEmojiID string `json:"custom_emoji_id"`
+}
+
+func (u *rawUpdate) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &u.Update); err != nil {
+		return err
+	}
+
+	var raw messageCustomEmoji
+	if err := json.Unmarshal(data, &raw); err != nil || raw.Message == nil {
+		return err
+	}
+	for _, e := range append(raw.Message.Entities, raw.Message.CaptionEntities...) {
+		if e.CustomEmojiID == "" {
+			continue
+		}
+		if u.customEmoji == nil {
+			u.customEmoji = make(map[int]string)
+		}
+		u.customEmoji[e.Offset] = e.CustomEmojiID
+	}
+	return nil
+}
+
+// getUpdates - запрос getUpdates библиотеки, ответ дополнительно разбирается ради custom_emoji_id
+func (b *Bot) getUpdates(config tgbotapi.UpdateConfig) ([]rawUpdate, error) {
+	resp, err := b.bot.Request(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []rawUpdate
+	err = json.Unmarshal(resp.Result, &updates)
+	return updates, err
+}
+
+type customEmojiKey struct{}
+
+func contextWithCustomEmoji(ctx context.Context, customEmoji map[int]string) context.Context {
+	return context.WithValue(ctx, customEmojiKey{}, customEmoji)
+}
+
+// customEmojiID returns custom_emoji_id of entity at offset in message of update
+func customEmojiID(ctx context.Context, offset int) string {
+	customEmoji, _ := ctx.Value(customEmojiKey{}).(map[int]string)
+	return customEmoji[offset]
+}
@@ -1,60 +1,263 @@
 package tgbot
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
 	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot/dto"
+	"github.com/Enthreeka/tg-posting-bot/pkg/dateparse"
+	"github.com/Enthreeka/tg-posting-bot/pkg/ttl"
+	"net/url"
+	"strings"
 	"time"
 )
 
-func PublicationCreateValidation(msg dto.PublicationCreate) error {
-	loc, err := time.LoadLocation("Europe/Moscow")
+// ParseDate - дата вводится администратором в его часовом поясе в формате 2024-08-27 15:48 или словами:
+// "завтра 10:00", "пн 18:30", "через 2 часа", "+45m". В базе хранится в UTC
+func ParseDate(ctx context.Context, text string) (time.Time, error) {
+	date, err := dateparse.Parse(text, time.Now().In(entity.LocationFromContext(ctx)))
 	if err != nil {
-		return err
+		return time.Time{}, err
 	}
+	return date.UTC(), nil
+}
 
-	// Преобразуем все даты в часовой пояс "Europe/Moscow"
-	publicationDate := msg.PublicationDate.In(loc)
-	deleteDate := msg.DeleteDate
-	if deleteDate != nil {
-		*deleteDate = deleteDate.In(loc)
+var errDateInput = errors.New("ошибка: не удалось распознать дату, отправьте например 2024-08-27 15:48, " +
+	"завтра 10:00, пн 18:30 или через 2 часа")
+
+// ParseTimezone - часовой пояс задается именем из базы IANA
+func ParseTimezone(text string) (*time.Location, error) {
+	name := strings.TrimSpace(text)
+	// пустое имя и Local дают часовой пояс сервера, а не пользователя
+	if name == "" || name == "Local" {
+		return nil, errors.New("ошибка: отправьте часовой пояс в формате IANA, например Europe/Moscow")
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка: неизвестный часовой пояс %q, отправьте его в формате IANA, например Europe/Moscow", name)
+	}
+	return loc, nil
+}
+
+// ParseShift parses signed shift of publications: "+2h", "-30m", "1d"
+func ParseShift(text string) (time.Duration, error) {
+	text = strings.TrimSpace(text)
+	sign := time.Duration(1)
+	if strings.HasPrefix(text, "-") {
+		sign = -1
+	}
+	shift, err := ttl.Parse(strings.TrimLeft(text, "+-"))
+	if err != nil {
+		return 0, errors.New("ошибка: отправьте сдвиг, например +2h, -30m или 1d")
+	}
+	return sign * shift, nil
+}
+
+// ParseExportPeriod parses period of export: "2024-09-01 - 2024-09-30", "01.09.2024 30.09.2024" or one day.
+// Дни периода берутся в часовом поясе администратора
+func ParseExportPeriod(ctx context.Context, text string) (time.Time, time.Time, error) {
+	loc := entity.LocationFromContext(ctx)
+	formatErr := errors.New("ошибка: отправьте период в формате 2024-09-01 - 2024-09-30 или 01.09.2024 - 30.09.2024")
+
+	var dates []time.Time
+	for _, field := range strings.Fields(text) {
+		if field == "-" {
+			continue
+		}
+		date, err := time.ParseInLocation("2006-01-02", field, loc)
+		if err != nil {
+			if date, err = time.ParseInLocation("02.01.2006", field, loc); err != nil {
+				return time.Time{}, time.Time{}, formatErr
+			}
+		}
+		dates = append(dates, date)
+	}
+
+	switch {
+	case len(dates) == 1:
+		return dates[0], dates[0], nil
+	case len(dates) != 2:
+		return time.Time{}, time.Time{}, formatErr
+	case dates[1].Before(dates[0]):
+		return time.Time{}, time.Time{}, errors.New("ошибка: начало периода позже его окончания")
+	}
+	return dates[0], dates[1], nil
+}
+
+// ParseBlackout parses period of silence: "2024-05-09 00:00 - 2024-05-10 00:00 причина" or "сегодня 22:00 - завтра 08:00 причина"
+func ParseBlackout(ctx context.Context, text string) (*entity.Blackout, error) {
+	formatErr := errors.New("ошибка: отправьте период в формате 2024-05-09 00:00 - 2024-05-10 00:00 причина")
+
+	startText, rest, ok := strings.Cut(strings.TrimSpace(text), " - ")
+	if !ok {
+		return nil, formatErr
+	}
+	startsAt, err := ParseDate(ctx, startText)
+	if err != nil {
+		return nil, formatErr
+	}
+
+	// конец периода может быть записан словами ("завтра 10:00"), поэтому концом считается
+	// самое длинное начало строки, которое является датой, остальное - причина
+	words := strings.Fields(rest)
+	var (
+		endsAt time.Time
+		reason string
+	)
+	err = formatErr
+	for i := len(words); i > 0 && err != nil; i-- {
+		if endsAt, err = ParseDate(ctx, strings.Join(words[:i], " ")); err == nil {
+			reason = strings.Join(words[i:], " ")
+		}
+	}
+	if err != nil {
+		return nil, formatErr
+	}
+	if !startsAt.Before(endsAt) {
+		return nil, errors.New("ошибка: начало периода должно быть раньше его конца")
+	}
+	if endsAt.Before(time.Now()) {
+		return nil, errors.New("ошибка: период тишины уже закончился")
+	}
+
+	blackout := &entity.Blackout{StartsAt: startsAt, EndsAt: endsAt}
+	if reason != "" {
+		blackout.Reason = &reason
+	}
+	return blackout, nil
+}
+
+// ParseButtons parses rows of buttons: every line is a row, buttons of row are separated by "|",
+// button is "Текст - https://example.com"
+func ParseButtons(text string) (entity.Buttons, error) {
+	formatErr := errors.New("ошибка: отправьте кнопки в формате Текст - https://example.com, " +
+		"кнопки одного ряда разделяются |, каждый ряд с новой строки")
+
+	var buttons entity.Buttons
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var row []entity.Button
+		for _, item := range strings.Split(line, "|") {
+			sep := strings.LastIndex(item, " - ")
+			if sep < 0 {
+				return nil, formatErr
+			}
+			row = append(row, entity.Button{
+				Text: strings.TrimSpace(item[:sep]),
+				URL:  strings.TrimSpace(item[sep+len(" - "):]),
+			})
+		}
+		buttons = append(buttons, row)
+	}
+	if len(buttons) == 0 {
+		return nil, formatErr
 	}
 
+	return buttons, ValidateButtons(buttons)
+}
+
+// ValidateButtons - у каждой кнопки должен быть текст и валидная ссылка
+func ValidateButtons(buttons entity.Buttons) error {
+	if buttons.Count() > entity.MaxButtons {
+		return fmt.Errorf("ошибка: у публикации может быть не больше %d кнопок", entity.MaxButtons)
+	}
+
+	for i, row := range buttons {
+		if len(row) > entity.MaxButtonsInRow {
+			return fmt.Errorf("ошибка: в ряду %d больше %d кнопок", i+1, entity.MaxButtonsInRow)
+		}
+		for _, button := range row {
+			if button.Text == "" {
+				return fmt.Errorf("ошибка: отсутствует текст для кнопки в ряду %d", i+1)
+			}
+			if _, err := url.ParseRequestURI(button.URL); err != nil {
+				return fmt.Errorf("ошибка: невалидная ссылка %q", button.URL)
+			}
+		}
+	}
+	return nil
+}
+
+func PublicationCreateValidation(msg dto.PublicationCreate, loc *time.Location) error {
+	publicationDate := msg.PublicationDate
+	deleteDate := msg.DeleteDate
+
 	// Проверяем, является ли время публикации раньше текущего времени
-	if publicationDate.Before(time.Now().In(loc)) {
-		return errors.New("время публикации раньше чем текущее время по Europe/Moscow")
+	if publicationDate.Before(time.Now()) {
+		return fmt.Errorf("время публикации раньше чем текущее время по %s", loc)
 	}
 
 	// Проверяем, является ли время удаления раньше текущего времени
-	if deleteDate != nil && deleteDate.Before(time.Now().In(loc)) {
-		return errors.New("время удаления раньше чем текущее время по Europe/Moscow")
+	if deleteDate != nil && deleteDate.Before(time.Now()) {
+		return fmt.Errorf("время удаления раньше чем текущее время по %s", loc)
 	}
 
 	// Проверяем, является ли время удаления раньше времени публикации
 	if deleteDate != nil && deleteDate.Before(publicationDate) {
-		return errors.New("время удаления раньше чем время публикации по Europe/Moscow")
+		return errors.New("время удаления раньше чем время публикации")
 	}
 
-	if msg.Button != (dto.Button{}) {
-		if msg.Button.ButtonUrl == nil {
-			return errors.New("отсутствует текст для кнопки")
-		}
-		if msg.Button.ButtonText == nil {
-			return errors.New("отсутствует ссылка для кнопки")
-		}
+	return ValidateButtons(msg.Buttons)
+}
+
+func PublicationUpdateDateValidation(date time.Time, loc *time.Location) error {
+	if date.Before(time.Now()) {
+		return fmt.Errorf("время публикации раньше чем текущее время по %s", loc)
 	}
 
 	return nil
 }
 
-func PublicationUpdateDateValidation(date time.Time) error {
-	loc, err := time.LoadLocation("Europe/Moscow")
-	if err != nil {
-		return err
+// PublicationDeleteDateValidation - дата удаления должна быть позже текущего времени и времени отправки
+func PublicationDeleteDateValidation(date time.Time, publicationDate *time.Time, loc *time.Location) error {
+	if date.Before(time.Now()) {
+		return fmt.Errorf("время удаления раньше чем текущее время по %s", loc)
+	}
+
+	if publicationDate != nil && !date.After(*publicationDate) {
+		return fmt.Errorf("время удаления раньше чем время публикации (%s)", entity.FormatTime(publicationDate, loc))
+	}
+
+	return nil
+}
+
+// PublicationTextLengthValidation - текст должен помещаться в публикацию, если не включено разделение
+func PublicationTextLengthValidation(publication *entity.Publication) error {
+	return PublicationTextLimitValidation(publication, publication.TextLimit())
+}
+
+// PublicationTextLimitValidation - PublicationTextLengthValidation для заданного лимита первого сообщения
+func PublicationTextLimitValidation(publication *entity.Publication, limit int) error {
+	if publication.FitsLimit(limit) {
+		return nil
 	}
 
-	if date.Before(time.Now().In(loc)) {
-		return errors.New("время публикации раньше чем текущее время по Europe/Moscow")
+	return fmt.Errorf("ошибка: текст длиннее допустимого на %d символов (лимит %d). "+
+		"Сократите текст или включите «Разделять длинный текст» в параметрах доставки",
+		entity.TextLength(publication.Text)-limit, limit)
+}
+
+// ValidateMedia - вложения из файла импорта: известный тип, file_id и допустимый состав альбома
+func ValidateMedia(media []entity.Media) error {
+	for _, m := range media {
+		if !m.Type.Valid() {
+			return fmt.Errorf("ошибка: неизвестный тип вложения %q", m.Type)
+		}
+		if m.FileID == "" {
+			return errors.New("ошибка: у вложения не указан file_id")
+		}
 	}
 
+	if len(media) > entity.MaxAlbumSize {
+		return fmt.Errorf("ошибка: в альбоме может быть не больше %d файлов", entity.MaxAlbumSize)
+	}
+	if !entity.AlbumAllowed(media) {
+		return errors.New("ошибка: в альбоме можно объединить только фото с видео, только документы или только аудио")
+	}
 	return nil
 }
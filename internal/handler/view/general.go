@@ -2,25 +2,32 @@ package view
 
 import (
 	"context"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
 	"github.com/Enthreeka/tg-posting-bot/internal/handler/tgbot"
+	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
 	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
 	customMsg "github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api"
 	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"time"
 )
 
 type ViewGeneral struct {
-	log   *logger.Logger
-	tgMsg customMsg.Message
+	log          *logger.Logger
+	tgMsg        customMsg.Message
+	leaseService service.LeaseService
 }
 
 func NewViewGeneral(
 	log *logger.Logger,
 	tgMsg customMsg.Message,
+	leaseService service.LeaseService,
 ) *ViewGeneral {
 	return &ViewGeneral{
-		log:   log,
-		tgMsg: tgMsg,
+		log:          log,
+		tgMsg:        tgMsg,
+		leaseService: leaseService,
 	}
 }
 
@@ -34,3 +41,33 @@ func (c *ViewGeneral) CallbackStartAdminPanel() tgbot.ViewFunc {
 		return nil
 	}
 }
+
+// CallbackClusterStatus - cluster_status
+func (c *ViewGeneral) CallbackClusterStatus() tgbot.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update *tgbotapi.Update) error {
+		lease, err := c.leaseService.GetCurrent(ctx)
+		if err != nil {
+			c.log.Error("leaseService.GetCurrent: failed to get lease: %v", err)
+			return err
+		}
+
+		text := fmt.Sprintf("Статус кластера\n\n"+
+			"Лидер: %s\n"+
+			"Лидер с: %s (%s)\n"+
+			"Последнее продление: %s назад\n"+
+			"Аренда истекает через: %s",
+			lease.Holder,
+			entity.FormatTime(&lease.AcquiredAt, entity.LocationFromContext(ctx)), time.Since(lease.AcquiredAt).Round(time.Second),
+			time.Since(lease.RenewedAt).Round(time.Second),
+			time.Until(lease.ExpiresAt).Round(time.Second))
+
+		if _, err := c.tgMsg.SendEditMessage(update.FromChat().ID,
+			update.CallbackQuery.Message.MessageID,
+			&markup.MainMenu,
+			text); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	"time"
+)
+
+// ErrLeadershipLost - аренда перехвачена другим экземпляром или не продлена вовремя
+var ErrLeadershipLost = errors.New("leadership lost")
+
+type Elector interface {
+	// Run blocks until ctx is done or leadership is lost. onElected is started once when lease is acquired,
+	// its context is canceled when leadership is lost.
+	Run(ctx context.Context, onElected func(ctx context.Context)) error
+	InstanceID() string
+}
+
+type elector struct {
+	leaseService service.LeaseService
+	log          *logger.Logger
+	instanceID   string
+	ttl          time.Duration
+}
+
+func NewElector(leaseService service.LeaseService, log *logger.Logger, instanceID string, ttl time.Duration) (Elector, error) {
+	if leaseService == nil {
+		return nil, errors.New("leaseService cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("log cannot be nil")
+	}
+	if instanceID == "" {
+		return nil, errors.New("instanceID cannot be empty")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("ttl must be positive")
+	}
+
+	return &elector{
+		leaseService: leaseService,
+		log:          log,
+		instanceID:   instanceID,
+		ttl:          ttl,
+	}, nil
+}
+
+func (e *elector) InstanceID() string {
+	return e.instanceID
+}
+
+func (e *elector) Run(ctx context.Context, onElected func(ctx context.Context)) error {
+	// продлеваем аренду трижды за ttl, чтобы одна неудачная попытка не приводила к потере лидерства
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	var (
+		leaderCtx    context.Context
+		cancelLeader context.CancelFunc
+		renewedAt    time.Time
+	)
+
+	for {
+		acquired, err := e.leaseService.TryAcquire(ctx, e.instanceID, e.ttl)
+		if err != nil {
+			e.log.Error("failed to acquire lease: %v", err)
+		}
+
+		switch {
+		case acquired && leaderCtx == nil:
+			e.log.Info("instance %s elected as leader", e.instanceID)
+			renewedAt = time.Now()
+			leaderCtx, cancelLeader = context.WithCancel(ctx)
+			go onElected(leaderCtx)
+		case acquired:
+			renewedAt = time.Now()
+		case leaderCtx != nil && (err == nil || time.Since(renewedAt) >= e.ttl):
+			// аренда занята другим экземпляром или истекла, пока база была недоступна
+			e.log.Error("instance %s lost leadership", e.instanceID)
+			cancelLeader()
+			return ErrLeadershipLost
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if cancelLeader != nil {
+				cancelLeader()
+
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := e.leaseService.Release(releaseCtx, e.instanceID); err != nil {
+					e.log.Error("failed to release lease: %v", err)
+				}
+				cancel()
+			}
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type BlackoutRepo interface {
+	Create(ctx context.Context, blackout *entity.Blackout) (int, error)
+	GetByID(ctx context.Context, id int) (*entity.Blackout, error)
+	GetActual(ctx context.Context, channelID int, after time.Time) ([]entity.Blackout, error)
+	DeleteByID(ctx context.Context, id int) error
+}
+
+type blackoutRepo struct {
+	*postgres.Postgres
+}
+
+func NewBlackoutRepo(pg *postgres.Postgres) (BlackoutRepo, error) {
+	if pg == nil {
+		return nil, errors.New("postgres connection is nil")
+	}
+
+	return &blackoutRepo{
+		pg,
+	}, nil
+}
+
+const blackoutColumns = `id, channel_id, starts_at, ends_at, reason, created_at`
+
+func (b *blackoutRepo) collectRow(row pgx.Row) (*entity.Blackout, error) {
+	var blackout entity.Blackout
+	err := row.Scan(&blackout.ID,
+		&blackout.ChannelID,
+		&blackout.StartsAt,
+		&blackout.EndsAt,
+		&blackout.Reason,
+		&blackout.CreatedAt)
+	if checkErr := ErrorHandler(err); checkErr != nil {
+		return nil, checkErr
+	}
+	return &blackout, nil
+}
+
+func (b *blackoutRepo) Create(ctx context.Context, blackout *entity.Blackout) (int, error) {
+	query := `insert into channel_blackout (channel_id, starts_at, ends_at, reason) values ($1,$2,$3,$4) returning id`
+	var id int
+
+	err := b.Pool.QueryRow(ctx, query, blackout.ChannelID, blackout.StartsAt, blackout.EndsAt, blackout.Reason).Scan(&id)
+	return id, err
+}
+
+func (b *blackoutRepo) GetByID(ctx context.Context, id int) (*entity.Blackout, error) {
+	query := `select ` + blackoutColumns + ` from channel_blackout where id = $1`
+
+	return b.collectRow(b.Pool.QueryRow(ctx, query, id))
+}
+
+// GetActual returns blackouts of channel which end after given time, ordered by start
+func (b *blackoutRepo) GetActual(ctx context.Context, channelID int, after time.Time) ([]entity.Blackout, error) {
+	query := `select ` + blackoutColumns + ` from channel_blackout
+				where channel_id = $1 and ends_at > $2
+				order by starts_at`
+
+	rows, err := b.Pool.Query(ctx, query, channelID, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Blackout, error) {
+		blackout, err := b.collectRow(row)
+		if err != nil {
+			return entity.Blackout{}, err
+		}
+		return *blackout, nil
+	})
+}
+
+func (b *blackoutRepo) DeleteByID(ctx context.Context, id int) error {
+	query := `delete from channel_blackout where id = $1`
+
+	_, err := b.Pool.Exec(ctx, query, id)
+	return err
+}
@@ -18,9 +18,26 @@ type ChannelRepo interface {
 	GetAllAdminChannel(ctx context.Context) ([]entity.Channel, error)
 	GetChannelIDByChannelName(ctx context.Context, channelName string) (int64, error)
 	GetByChannelName(ctx context.Context, channelName string) (*entity.Channel, error)
+	UpdateCatchUpPolicy(ctx context.Context, id int, policy entity.CatchUpPolicy) error
+	UpdateMaxLateness(ctx context.Context, id int, minutes int) error
+	UpdateMaxSendAttempts(ctx context.Context, id int, attempts int) error
+	UpdateTimezone(ctx context.Context, id int, timezone string) error
+	UpdatePostingWindows(ctx context.Context, id int, windows *string) error
+	UpdateQueueSlots(ctx context.Context, id int, slots *string) error
+
+	CreateGroup(ctx context.Context, name string) (int, error)
+	GetGroupByID(ctx context.Context, id int) (*entity.ChannelGroup, error)
+	GetAllGroups(ctx context.Context) ([]entity.ChannelGroup, error)
+	DeleteGroupByID(ctx context.Context, id int) error
+	AddGroupMember(ctx context.Context, groupID int, channelID int) error
+	DeleteGroupMember(ctx context.Context, groupID int, channelID int) error
+	// DeleteFromGroupsByTgID removes channel from all groups
+	DeleteFromGroupsByTgID(ctx context.Context, telegramID int64) error
 	//GetChannelByUserID(ctx context.Context, userID int64) (string, error)
 }
 
+const channelColumns = `id,tg_id,channel_name,channel_url,channel_status,catch_up_policy,max_lateness_minutes,max_send_attempts,timezone,posting_windows,queue_slots`
+
 type channelRepo struct {
 	*postgres.Postgres
 }
@@ -37,7 +54,8 @@ func NewChannelRepo(pg *postgres.Postgres) (ChannelRepo, error) {
 
 func (u *channelRepo) collectRow(row pgx.Row) (*entity.Channel, error) {
 	var channel entity.Channel
-	err := row.Scan(&channel.ID, &channel.TgID, &channel.ChannelName, &channel.ChannelUrl, &channel.ChannelStatus)
+	err := row.Scan(&channel.ID, &channel.TgID, &channel.ChannelName, &channel.ChannelUrl, &channel.ChannelStatus,
+		&channel.CatchUpPolicy, &channel.MaxLatenessMinutes, &channel.MaxSendAttempts, &channel.Timezone, &channel.PostingWindows, &channel.QueueSlots)
 	if checkErr := ErrorHandler(err); checkErr != nil {
 		return nil, checkErr
 	}
@@ -59,7 +77,7 @@ func (u *channelRepo) Create(ctx context.Context, channel *entity.Channel) error
 }
 
 func (u *channelRepo) GetByID(ctx context.Context, id int) (*entity.Channel, error) {
-	query := `select * from channel where id = $1`
+	query := `select ` + channelColumns + ` from channel where id = $1`
 
 	row := u.Pool.QueryRow(ctx, query, id)
 	return u.collectRow(row)
@@ -73,7 +91,7 @@ func (u *channelRepo) DeleteByID(ctx context.Context, id int) error {
 }
 
 func (u *channelRepo) GetAll(ctx context.Context) ([]entity.Channel, error) {
-	query := `select * from channel`
+	query := `select ` + channelColumns + ` from channel`
 
 	rows, err := u.Pool.Query(ctx, query)
 	if err != nil {
@@ -106,7 +124,7 @@ func (u *channelRepo) IsChannelExistByTgID(ctx context.Context, telegramID int64
 }
 
 func (u *channelRepo) GetAllAdminChannel(ctx context.Context) ([]entity.Channel, error) {
-	query := `select * from channel where channel_status = 'administrator'`
+	query := `select ` + channelColumns + ` from channel where channel_status = 'administrator'`
 
 	rows, err := u.Pool.Query(ctx, query)
 	if err != nil {
@@ -128,21 +146,165 @@ func (u *channelRepo) GetChannelIDByChannelName(ctx context.Context, channelName
 }
 
 func (u *channelRepo) GetByChannelName(ctx context.Context, channelName string) (*entity.Channel, error) {
-	query := `select * from channel where channel_name = $1`
-	channel := new(entity.Channel)
-
-	err := u.Pool.QueryRow(ctx, query, channelName).Scan(
-		&channel.ID,
-		&channel.ChannelName,
-		&channel.ChannelUrl,
-		&channel.ChannelStatus,
-		&channel.TgID,
-	)
+	query := `select ` + channelColumns + ` from channel where channel_name = $1`
+
+	row := u.Pool.QueryRow(ctx, query, channelName)
+	return u.collectRow(row)
+}
+
+func (u *channelRepo) UpdateCatchUpPolicy(ctx context.Context, id int, policy entity.CatchUpPolicy) error {
+	query := `update channel set catch_up_policy = $1 where id = $2`
+
+	_, err := u.Pool.Exec(ctx, query, policy, id)
+	return err
+}
+
+func (u *channelRepo) UpdateMaxLateness(ctx context.Context, id int, minutes int) error {
+	query := `update channel set max_lateness_minutes = $1 where id = $2`
+
+	_, err := u.Pool.Exec(ctx, query, minutes, id)
+	return err
+}
+
+func (u *channelRepo) UpdateMaxSendAttempts(ctx context.Context, id int, attempts int) error {
+	query := `update channel set max_send_attempts = $1 where id = $2`
+
+	_, err := u.Pool.Exec(ctx, query, attempts, id)
+	return err
+}
+
+func (u *channelRepo) UpdateTimezone(ctx context.Context, id int, timezone string) error {
+	query := `update channel set timezone = $1 where id = $2`
+
+	_, err := u.Pool.Exec(ctx, query, timezone, id)
+	return err
+}
+
+func (u *channelRepo) UpdatePostingWindows(ctx context.Context, id int, windows *string) error {
+	query := `update channel set posting_windows = $1 where id = $2`
+
+	_, err := u.Pool.Exec(ctx, query, windows, id)
+	return err
+}
+
+func (u *channelRepo) UpdateQueueSlots(ctx context.Context, id int, slots *string) error {
+	query := `update channel set queue_slots = $1 where id = $2`
+
+	_, err := u.Pool.Exec(ctx, query, slots, id)
+	return err
+}
+
+func (u *channelRepo) CreateGroup(ctx context.Context, name string) (int, error) {
+	query := `insert into channel_group (name) values ($1) returning id`
+	var id int
+
+	err := u.Pool.QueryRow(ctx, query, name).Scan(&id)
+	return id, ErrorHandler(err)
+}
+
+// groupChannels returns channels of groups by group id, channels are ordered by name
+func (u *channelRepo) groupChannels(ctx context.Context, groupIDs []int) (map[int][]entity.Channel, error) {
+	query := `select m.group_id, ` + channelColumns + ` from channel_group_member m
+				join channel on m.channel_id = channel.id
+				where m.group_id = any($1)
+				order by channel_name`
+
+	rows, err := u.Pool.Query(ctx, query, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	channels := make(map[int][]entity.Channel, len(groupIDs))
+	for rows.Next() {
+		var (
+			groupID int
+			channel entity.Channel
+		)
+		err := rows.Scan(&groupID, &channel.ID, &channel.TgID, &channel.ChannelName, &channel.ChannelUrl, &channel.ChannelStatus,
+			&channel.CatchUpPolicy, &channel.MaxLatenessMinutes, &channel.MaxSendAttempts, &channel.Timezone, &channel.PostingWindows, &channel.QueueSlots)
+		if err != nil {
+			return nil, err
+		}
+		channels[groupID] = append(channels[groupID], channel)
+	}
+
+	return channels, rows.Err()
+}
+
+func (u *channelRepo) GetGroupByID(ctx context.Context, id int) (*entity.ChannelGroup, error) {
+	query := `select id, name from channel_group where id = $1`
+	var group entity.ChannelGroup
+
+	err := u.Pool.QueryRow(ctx, query, id).Scan(&group.ID, &group.Name)
 	if checkErr := ErrorHandler(err); checkErr != nil {
-		return channel, checkErr
+		return nil, checkErr
+	}
+
+	channels, err := u.groupChannels(ctx, []int{id})
+	if err != nil {
+		return nil, err
+	}
+	group.Channels = channels[id]
+	return &group, nil
+}
+
+func (u *channelRepo) GetAllGroups(ctx context.Context) ([]entity.ChannelGroup, error) {
+	query := `select id, name from channel_group order by name`
+
+	rows, err := u.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.ChannelGroup, error) {
+		var group entity.ChannelGroup
+		err := row.Scan(&group.ID, &group.Name)
+		return group, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(groups))
+	for _, group := range groups {
+		ids = append(ids, group.ID)
 	}
+	channels, err := u.groupChannels(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		groups[i].Channels = channels[groups[i].ID]
+	}
+	return groups, nil
+}
+
+func (u *channelRepo) DeleteGroupByID(ctx context.Context, id int) error {
+	query := `delete from channel_group where id = $1`
+
+	_, err := u.Pool.Exec(ctx, query, id)
+	return err
+}
 
-	return channel, err
+func (u *channelRepo) AddGroupMember(ctx context.Context, groupID int, channelID int) error {
+	query := `insert into channel_group_member (group_id, channel_id) values ($1,$2) on conflict do nothing`
+
+	_, err := u.Pool.Exec(ctx, query, groupID, channelID)
+	return err
+}
+
+func (u *channelRepo) DeleteGroupMember(ctx context.Context, groupID int, channelID int) error {
+	query := `delete from channel_group_member where group_id = $1 and channel_id = $2`
+
+	_, err := u.Pool.Exec(ctx, query, groupID, channelID)
+	return err
+}
+
+func (u *channelRepo) DeleteFromGroupsByTgID(ctx context.Context, telegramID int64) error {
+	query := `delete from channel_group_member where channel_id in (select id from channel where tg_id = $1)`
+
+	_, err := u.Pool.Exec(ctx, query, telegramID)
+	return err
 }
 
 //func (u *channelRepo) GetChannelByUserID(ctx context.Context, userID int64) (string, error) {
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+type ImportRepo interface {
+	Create(ctx context.Context, userID int64, publications []entity.Publication) (int, error)
+	Commit(ctx context.Context, id int, userID int64) (int, error)
+	Delete(ctx context.Context, id int, userID int64) error
+}
+
+type importRepo struct {
+	*postgres.Postgres
+}
+
+func NewImportRepo(pg *postgres.Postgres) (ImportRepo, error) {
+	if pg == nil {
+		return nil, errors.New("postgres connection is nil")
+	}
+
+	return &importRepo{
+		pg,
+	}, nil
+}
+
+func (i *importRepo) Create(ctx context.Context, userID int64, publications []entity.Publication) (int, error) {
+	query := `insert into publication_import (user_id, publications) values ($1,$2) returning id`
+
+	var id int
+	err := i.Pool.QueryRow(ctx, query, userID, publications).Scan(&id)
+	return id, err
+}
+
+// Commit creates all publications of import with their publish jobs in one transaction,
+// import is deleted in the same transaction, so it can not be committed twice
+func (i *importRepo) Commit(ctx context.Context, id int, userID int64) (int, error) {
+	var count int
+	err := pgx.BeginFunc(ctx, i.Pool, func(tx pgx.Tx) error {
+		var publications []entity.Publication
+		query := `delete from publication_import where id = $1 and user_id = $2 returning publications`
+		if err := tx.QueryRow(ctx, query, id, userID).Scan(&publications); err != nil {
+			return ErrorHandler(err)
+		}
+
+		jobQuery := `insert into publication_job (publication_id, kind, run_at) values ($1,$2,$3)`
+		for _, publication := range publications {
+			publicationID, err := insertPublication(ctx, tx, &publication)
+			if err != nil {
+				return err
+			}
+			if _, err = tx.Exec(ctx, jobQuery, publicationID, entity.JobPublish, publication.PublicationDate); err != nil {
+				return err
+			}
+		}
+
+		count = len(publications)
+		return nil
+	})
+	return count, err
+}
+
+func (i *importRepo) Delete(ctx context.Context, id int, userID int64) error {
+	query := `delete from publication_import where id = $1 and user_id = $2`
+	_, err := i.Pool.Exec(ctx, query, id, userID)
+	return err
+}
@@ -0,0 +1,402 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type JobRepo interface {
+	Upsert(ctx context.Context, job *entity.Job) error
+	UpsertTargets(ctx context.Context, publicationID int, runAt time.Time) error
+
+	Claim(ctx context.Context, kind entity.JobKind, lease time.Duration, limit int) ([]entity.Job, error)
+	NextRunAt(ctx context.Context, kind entity.JobKind) (*time.Time, error)
+	GetOverdue(ctx context.Context, before time.Time) ([]entity.Job, error)
+	GetByID(ctx context.Context, jobID int) (*entity.Job, error)
+	GetByPublicationID(ctx context.Context, publicationID int, kind entity.JobKind) (*entity.Job, error)
+	HasActive(ctx context.Context, publicationID int) (bool, error)
+
+	GetAttempts(ctx context.Context, publicationID int, limit int) ([]entity.JobAttempt, error)
+
+	Complete(ctx context.Context, jobID int) error
+	Fail(ctx context.Context, jobID int, lastError string) error
+	Retry(ctx context.Context, jobID int, runAt time.Time, lastError string) error
+	AddAttempt(ctx context.Context, attempt *entity.JobAttempt) error
+	Hold(ctx context.Context, jobID int, reason entity.HoldReason) error
+	HoldPublication(ctx context.Context, publicationID int, kind entity.JobKind) error
+	Release(ctx context.Context, jobID int, runAt time.Time) error
+	Force(ctx context.Context, jobID int, runAt time.Time) error
+	Delete(ctx context.Context, jobID int) error
+	DeletePublication(ctx context.Context, publicationID int, kind entity.JobKind) error
+}
+
+type jobRepo struct {
+	*postgres.Postgres
+}
+
+func NewJobRepo(pg *postgres.Postgres) (JobRepo, error) {
+	if pg == nil {
+		return nil, errors.New("postgres connection is nil")
+	}
+
+	return &jobRepo{
+		pg,
+	}, nil
+}
+
+const jobColumns = `j.id, j.publication_id, j.kind, j.state, j.run_at, j.chat_id, j.message_ids, j.attempts, j.locked_until, j.last_error, j.occurrence_id, j.ignore_blackout, j.target_id, j.hold_reason`
+
+func (j *jobRepo) collectRow(row pgx.Row) (*entity.Job, error) {
+	var job entity.Job
+	err := row.Scan(&job.ID,
+		&job.PublicationID,
+		&job.Kind,
+		&job.State,
+		&job.RunAt,
+		&job.ChatID,
+		&job.MessageIDs,
+		&job.Attempts,
+		&job.LockedUntil,
+		&job.LastError,
+		&job.OccurrenceID,
+		&job.IgnoreBlackout,
+		&job.TargetID,
+		&job.HoldReason)
+	if checkErr := ErrorHandler(err); checkErr != nil {
+		return nil, checkErr
+	}
+	return &job, err
+}
+
+func (j *jobRepo) collectRows(rows pgx.Rows) ([]entity.Job, error) {
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Job, error) {
+		job, err := j.collectRow(row)
+		if err != nil {
+			return entity.Job{}, err
+		}
+		return *job, nil
+	})
+}
+
+// Upsert creates job or reschedules existing job of the same kind for publication
+func (j *jobRepo) Upsert(ctx context.Context, job *entity.Job) error {
+	query := `insert into publication_job (publication_id, kind, run_at, chat_id, message_ids, occurrence_id, target_id)
+				values ($1,$2,$3,$4,$5,$6,$7)
+				on conflict (publication_id, kind, coalesce(occurrence_id, 0), coalesce(target_id, 0)) do update
+				set run_at = excluded.run_at,
+				    chat_id = excluded.chat_id,
+				    message_ids = excluded.message_ids,
+				    state = 'pending',
+				    attempts = 0,
+				    locked_until = null,
+				    last_error = null,
+				    ignore_blackout = false,
+				    hold_reason = null`
+
+	_, err := j.Pool.Exec(ctx, query, job.PublicationID, job.Kind, job.RunAt, job.ChatID, job.MessageIDs, job.OccurrenceID, job.TargetID)
+	return err
+}
+
+// UpsertTargets creates or reschedules publish jobs of not sent targets, each target is shifted by its send offset
+func (j *jobRepo) UpsertTargets(ctx context.Context, publicationID int, runAt time.Time) error {
+	query := `insert into publication_job (publication_id, kind, run_at, target_id)
+				select publication_id, 'publish', $2::timestamptz + make_interval(mins => send_offset_minutes), id
+				from publication_target
+				where publication_id = $1 and status = 'awaits'
+				on conflict (publication_id, kind, coalesce(occurrence_id, 0), coalesce(target_id, 0)) do update
+				set run_at = excluded.run_at,
+				    state = 'pending',
+				    attempts = 0,
+				    locked_until = null,
+				    last_error = null,
+				    ignore_blackout = false,
+				    hold_reason = null`
+
+	_, err := j.Pool.Exec(ctx, query, publicationID, runAt)
+	return err
+}
+
+// Claim locks due jobs for lease duration, jobs of crashed worker become available again after lease expired
+func (j *jobRepo) Claim(ctx context.Context, kind entity.JobKind, lease time.Duration, limit int) ([]entity.Job, error) {
+	query := `with j as (update publication_job j
+				set locked_until = now() + $2::interval, attempts = j.attempts + 1
+				where j.id in (select id from publication_job
+				               where kind = $1 and state = 'pending' and run_at <= now()
+				                 and (locked_until is null or locked_until < now())
+				               order by run_at
+				               limit $3
+				               for update skip locked)
+				returning j.*)
+				select ` + jobColumns + `, c.channel_name, c.catch_up_policy, c.max_lateness_minutes, c.max_send_attempts
+				from j
+				join publication p on j.publication_id = p.id
+				left join publication_target t on j.target_id = t.id
+				join channel c on coalesce(t.channel_id, p.channel_id) = c.id`
+
+	rows, err := j.Pool.Query(ctx, query, kind, lease, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]entity.Job, 0)
+	for rows.Next() {
+		job := entity.Job{}
+		err := rows.Scan(&job.ID,
+			&job.PublicationID,
+			&job.Kind,
+			&job.State,
+			&job.RunAt,
+			&job.ChatID,
+			&job.MessageIDs,
+			&job.Attempts,
+			&job.LockedUntil,
+			&job.LastError,
+			&job.OccurrenceID,
+			&job.IgnoreBlackout,
+			&job.TargetID,
+			&job.HoldReason,
+			&job.ChannelName,
+			&job.CatchUpPolicy,
+			&job.MaxLatenessMinutes,
+			&job.MaxAttempts)
+		if err != nil {
+			if checkErr := ErrorHandler(err); checkErr != nil {
+				return nil, checkErr
+			}
+			return nil, err
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+func (j *jobRepo) NextRunAt(ctx context.Context, kind entity.JobKind) (*time.Time, error) {
+	query := `select min(greatest(run_at, coalesce(locked_until, run_at))) from publication_job
+				where kind = $1 and state = 'pending'`
+	var next *time.Time
+
+	err := j.Pool.QueryRow(ctx, query, kind).Scan(&next)
+	return next, err
+}
+
+// GetOverdue returns pending jobs which are late at before more than max lateness of their channel,
+// jobs waiting for retry are not overdue, their delay is backoff
+func (j *jobRepo) GetOverdue(ctx context.Context, before time.Time) ([]entity.Job, error) {
+	query := `select ` + jobColumns + `, c.channel_name, c.catch_up_policy, c.max_lateness_minutes
+				from publication_job j
+				join publication p on j.publication_id = p.id
+				left join publication_target t on j.target_id = t.id
+				join channel c on coalesce(t.channel_id, p.channel_id) = c.id
+				where j.state = 'pending' and j.attempts = 0 and j.locked_until is null
+				  and j.run_at + make_interval(mins => c.max_lateness_minutes) < $1
+				order by j.run_at`
+
+	rows, err := j.Pool.Query(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]entity.Job, 0)
+	for rows.Next() {
+		job := entity.Job{}
+		err := rows.Scan(&job.ID,
+			&job.PublicationID,
+			&job.Kind,
+			&job.State,
+			&job.RunAt,
+			&job.ChatID,
+			&job.MessageIDs,
+			&job.Attempts,
+			&job.LockedUntil,
+			&job.LastError,
+			&job.OccurrenceID,
+			&job.IgnoreBlackout,
+			&job.TargetID,
+			&job.HoldReason,
+			&job.ChannelName,
+			&job.CatchUpPolicy,
+			&job.MaxLatenessMinutes)
+		if err != nil {
+			if checkErr := ErrorHandler(err); checkErr != nil {
+				return nil, checkErr
+			}
+			return nil, err
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// GetByID returns job with settings of channel where job delivers publication
+func (j *jobRepo) GetByID(ctx context.Context, jobID int) (*entity.Job, error) {
+	query := `select ` + jobColumns + `, c.channel_name, c.catch_up_policy, c.max_lateness_minutes
+				from publication_job j
+				join publication p on j.publication_id = p.id
+				left join publication_target t on j.target_id = t.id
+				join channel c on coalesce(t.channel_id, p.channel_id) = c.id
+				where j.id = $1`
+
+	var job entity.Job
+	err := j.Pool.QueryRow(ctx, query, jobID).Scan(&job.ID,
+		&job.PublicationID,
+		&job.Kind,
+		&job.State,
+		&job.RunAt,
+		&job.ChatID,
+		&job.MessageIDs,
+		&job.Attempts,
+		&job.LockedUntil,
+		&job.LastError,
+		&job.OccurrenceID,
+		&job.IgnoreBlackout,
+		&job.TargetID,
+		&job.HoldReason,
+		&job.ChannelName,
+		&job.CatchUpPolicy,
+		&job.MaxLatenessMinutes)
+	if checkErr := ErrorHandler(err); checkErr != nil {
+		return nil, checkErr
+	}
+	return &job, err
+}
+
+func (j *jobRepo) GetByPublicationID(ctx context.Context, publicationID int, kind entity.JobKind) (*entity.Job, error) {
+	query := `select ` + jobColumns + ` from publication_job j
+				where j.publication_id = $1 and j.kind = $2 and j.occurrence_id is null and j.target_id is null`
+
+	row := j.Pool.QueryRow(ctx, query, publicationID, kind)
+	return j.collectRow(row)
+}
+
+// HasActive checks that publication has pending or held jobs of any kind and any target
+func (j *jobRepo) HasActive(ctx context.Context, publicationID int) (bool, error) {
+	query := `select exists(select 1 from publication_job where publication_id = $1 and state in ('pending', 'held'))`
+	var exists bool
+
+	err := j.Pool.QueryRow(ctx, query, publicationID).Scan(&exists)
+	return exists, err
+}
+
+func (j *jobRepo) Complete(ctx context.Context, jobID int) error {
+	query := `delete from publication_job where id = $1`
+
+	_, err := j.Pool.Exec(ctx, query, jobID)
+	return err
+}
+
+func (j *jobRepo) Fail(ctx context.Context, jobID int, lastError string) error {
+	query := `update publication_job set state = 'failed', locked_until = null, last_error = $1 where id = $2`
+
+	_, err := j.Pool.Exec(ctx, query, lastError, jobID)
+	return err
+}
+
+// Retry releases job lock and reschedules it for next attempt
+func (j *jobRepo) Retry(ctx context.Context, jobID int, runAt time.Time, lastError string) error {
+	query := `update publication_job set run_at = $1, locked_until = null, last_error = $2 where id = $3`
+
+	_, err := j.Pool.Exec(ctx, query, runAt, lastError, jobID)
+	return err
+}
+
+func (j *jobRepo) AddAttempt(ctx context.Context, attempt *entity.JobAttempt) error {
+	query := `insert into publication_job_attempt (publication_id, job_id, target_id, kind, attempt, error) values ($1,$2,$3,$4,$5,$6)`
+
+	_, err := j.Pool.Exec(ctx, query, attempt.PublicationID, attempt.JobID, attempt.TargetID, attempt.Kind, attempt.Attempt, attempt.Error)
+	return err
+}
+
+// GetAttempts returns last failed attempts of publication, newest first
+func (j *jobRepo) GetAttempts(ctx context.Context, publicationID int, limit int) ([]entity.JobAttempt, error) {
+	query := `select a.id, a.publication_id, a.job_id, a.target_id, a.kind, a.attempt, a.error, a.created_at,
+					coalesce(c.channel_name, '')
+				from publication_job_attempt a
+				left join publication_target t on a.target_id = t.id
+				left join channel c on t.channel_id = c.id
+				where a.publication_id = $1
+				order by a.created_at desc
+				limit $2`
+
+	rows, err := j.Pool.Query(ctx, query, publicationID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.JobAttempt, error) {
+		var attempt entity.JobAttempt
+		err := row.Scan(&attempt.ID,
+			&attempt.PublicationID,
+			&attempt.JobID,
+			&attempt.TargetID,
+			&attempt.Kind,
+			&attempt.Attempt,
+			&attempt.Error,
+			&attempt.CreatedAt,
+			&attempt.TargetChannelName)
+		return attempt, err
+	})
+}
+
+// Hold stops pending job until administrator decides what to do with it
+func (j *jobRepo) Hold(ctx context.Context, jobID int, reason entity.HoldReason) error {
+	query := `update publication_job set state = 'held', locked_until = null, hold_reason = $1 where id = $2 and state = 'pending'`
+
+	_, err := j.Pool.Exec(ctx, query, reason, jobID)
+	return err
+}
+
+// HoldPublication stops pending jobs of publication in its channel and in targets, jobs of occurrences are not changed
+func (j *jobRepo) HoldPublication(ctx context.Context, publicationID int, kind entity.JobKind) error {
+	query := `update publication_job set state = 'held', locked_until = null, hold_reason = 'paused'
+				where publication_id = $1 and kind = $2 and occurrence_id is null and state = 'pending'`
+
+	_, err := j.Pool.Exec(ctx, query, publicationID, kind)
+	return err
+}
+
+// Release makes held job pending again at runAt
+func (j *jobRepo) Release(ctx context.Context, jobID int, runAt time.Time) error {
+	query := `update publication_job set state = 'pending', run_at = $1, locked_until = null, hold_reason = null
+				where id = $2 and state = 'held'`
+
+	_, err := j.Pool.Exec(ctx, query, runAt, jobID)
+	return err
+}
+
+// Force makes held job pending and marks that it must be executed even in blackout of channel
+func (j *jobRepo) Force(ctx context.Context, jobID int, runAt time.Time) error {
+	query := `update publication_job set state = 'pending', run_at = $1, locked_until = null, ignore_blackout = true,
+				    hold_reason = null
+				where id = $2 and state = 'held'`
+
+	_, err := j.Pool.Exec(ctx, query, runAt, jobID)
+	return err
+}
+
+// Delete deletes held job, administrator decided not to execute it
+func (j *jobRepo) Delete(ctx context.Context, jobID int) error {
+	query := `delete from publication_job where id = $1 and state = 'held'`
+
+	_, err := j.Pool.Exec(ctx, query, jobID)
+	return err
+}
+
+// DeletePublication deletes not failed jobs of publication in its channel and in targets,
+// jobs of occurrences and failed jobs are kept
+func (j *jobRepo) DeletePublication(ctx context.Context, publicationID int, kind entity.JobKind) error {
+	query := `delete from publication_job
+				where publication_id = $1 and kind = $2 and occurrence_id is null and state in ('pending', 'held')`
+
+	_, err := j.Pool.Exec(ctx, query, publicationID, kind)
+	return err
+}
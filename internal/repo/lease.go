@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/pkg/postgres"
+	"time"
+)
+
+type LeaseRepo interface {
+	TryAcquire(ctx context.Context, name string, holder string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, name string, holder string) error
+	Get(ctx context.Context, name string) (*entity.Lease, error)
+}
+
+type leaseRepo struct {
+	*postgres.Postgres
+}
+
+func NewLeaseRepo(pg *postgres.Postgres) (LeaseRepo, error) {
+	if pg == nil {
+		return nil, errors.New("postgres connection is nil")
+	}
+
+	return &leaseRepo{
+		pg,
+	}, nil
+}
+
+// TryAcquire acquires expired lease or renews lease of the same holder, returns false if lease is held by another holder
+func (l *leaseRepo) TryAcquire(ctx context.Context, name string, holder string, ttl time.Duration) (bool, error) {
+	query := `insert into leader_lease (name, holder, acquired_at, renewed_at, expires_at)
+				values ($1, $2, now(), now(), now() + $3::interval)
+				on conflict (name) do update
+				set holder = excluded.holder,
+				    acquired_at = case when leader_lease.holder = excluded.holder
+				                       then leader_lease.acquired_at else now() end,
+				    renewed_at = now(),
+				    expires_at = excluded.expires_at
+				where leader_lease.holder = excluded.holder or leader_lease.expires_at < now()
+				returning holder`
+	var gotHolder string
+
+	err := l.Pool.QueryRow(ctx, query, name, holder, ttl).Scan(&gotHolder)
+	if errors.Is(err, ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return gotHolder == holder, nil
+}
+
+func (l *leaseRepo) Release(ctx context.Context, name string, holder string) error {
+	query := `delete from leader_lease where name = $1 and holder = $2`
+
+	_, err := l.Pool.Exec(ctx, query, name, holder)
+	return err
+}
+
+func (l *leaseRepo) Get(ctx context.Context, name string) (*entity.Lease, error) {
+	query := `select name, holder, acquired_at, renewed_at, expires_at from leader_lease where name = $1`
+	lease := new(entity.Lease)
+
+	err := l.Pool.QueryRow(ctx, query, name).Scan(
+		&lease.Name,
+		&lease.Holder,
+		&lease.AcquiredAt,
+		&lease.RenewedAt,
+		&lease.ExpiresAt)
+	if checkErr := ErrorHandler(err); checkErr != nil {
+		return nil, checkErr
+	}
+
+	return lease, nil
+}
@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+type OccurrenceRepo interface {
+	Create(ctx context.Context, occurrence *entity.Occurrence) (int, error)
+	UpdateStatus(ctx context.Context, occurrenceID int, status entity.OccurrenceStatus, lastError *string) error
+	GetLast(ctx context.Context, publicationID int, limit int) ([]entity.Occurrence, error)
+}
+
+type occurrenceRepo struct {
+	*postgres.Postgres
+}
+
+func NewOccurrenceRepo(pg *postgres.Postgres) (OccurrenceRepo, error) {
+	if pg == nil {
+		return nil, errors.New("postgres connection is nil")
+	}
+
+	return &occurrenceRepo{
+		pg,
+	}, nil
+}
+
+func (o *occurrenceRepo) Create(ctx context.Context, occurrence *entity.Occurrence) (int, error) {
+	query := `insert into publication_occurrence (publication_id, run_at, status, message_ids, error)
+				values ($1,$2,$3,$4,$5) returning id`
+	var id int
+
+	err := o.Pool.QueryRow(ctx, query,
+		occurrence.PublicationID,
+		occurrence.RunAt,
+		occurrence.Status,
+		occurrence.MessageIDs,
+		occurrence.Error).Scan(&id)
+	return id, err
+}
+
+func (o *occurrenceRepo) UpdateStatus(ctx context.Context, occurrenceID int, status entity.OccurrenceStatus, lastError *string) error {
+	query := `update publication_occurrence set status = $1, error = $2, updated_at = now() where id = $3`
+
+	_, err := o.Pool.Exec(ctx, query, status, lastError, occurrenceID)
+	return err
+}
+
+// GetLast returns last occurrences of series, newest first
+func (o *occurrenceRepo) GetLast(ctx context.Context, publicationID int, limit int) ([]entity.Occurrence, error) {
+	query := `select id, publication_id, run_at, status, message_ids, error, created_at, updated_at
+				from publication_occurrence
+				where publication_id = $1
+				order by run_at desc
+				limit $2`
+
+	rows, err := o.Pool.Query(ctx, query, publicationID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Occurrence, error) {
+		var occurrence entity.Occurrence
+		err := row.Scan(&occurrence.ID,
+			&occurrence.PublicationID,
+			&occurrence.RunAt,
+			&occurrence.Status,
+			&occurrence.MessageIDs,
+			&occurrence.Error,
+			&occurrence.CreatedAt,
+			&occurrence.UpdatedAt)
+		return occurrence, err
+	})
+}
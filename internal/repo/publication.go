@@ -6,31 +6,44 @@ import (
 	"github.com/Enthreeka/tg-posting-bot/internal/entity"
 	"github.com/Enthreeka/tg-posting-bot/pkg/postgres"
 	"github.com/jackc/pgx/v5"
+	"strings"
 	"time"
 )
 
 type PublicationRepo interface {
 	CreatePublication(ctx context.Context, publication *entity.Publication) (int, error)
-	CreatePublicationOnlyWithText(ctx context.Context, text string, id int) (int, error)
+	CreatePublicationOnlyWithText(ctx context.Context, text string, entities []entity.MessageEntity, id int) (int, error)
 
 	DeletePublication(ctx context.Context, publicationID int) error
 
 	GetPublicationByPublicationID(ctx context.Context, publicationID int) (*entity.Publication, error)
-	GetAllPublicationByChannelID(ctx context.Context, channelID int) ([]entity.Publication, error)
-	GetAwaitingPublication(ctx context.Context) ([]*entity.Publication, error)
 	GetPublicationAndChannel(ctx context.Context, publicationID int) (*entity.Publication, error)
-	GetAllPublicationByID(ctx context.Context, publicationID int) ([]entity.Publication, error)
 	GetOnePublicationByID(ctx context.Context, publicationID int) (*entity.Publication, error)
-	GetSentAndWaitingToDeletePublication(ctx context.Context) ([]*entity.Publication, error)
 
-	UpdatePublicationButtonText(ctx context.Context, publicationID int, buttonText string) error
-	UpdatePublicationButtonLink(ctx context.Context, publicationID int, buttonLink string) error
-	UpdatePublicationText(ctx context.Context, publicationID int, text string) error
+	UpdateButtons(ctx context.Context, publicationID int, buttons entity.Buttons) error
+	UpdateOptions(ctx context.Context, publicationID int, options entity.DeliveryOptions) error
+	UpdatePublicationText(ctx context.Context, publicationID int, text string, entities []entity.MessageEntity) error
 	UpdatePublicationStatus(ctx context.Context, publicationID int, status entity.PublicationStatus) error
-	UpdatePublicationImage(ctx context.Context, publicationID int, image *string) error
+	GetMedia(ctx context.Context, publicationID int) ([]entity.Media, error)
+	ReplaceMedia(ctx context.Context, publicationID int, media []entity.Media) error
+	AddMedia(ctx context.Context, publicationID int, media entity.Media) error
 	UpdatePublicationDate(ctx context.Context, publicationID int, date time.Time) error
 	UpdateDeleteDate(ctx context.Context, publicationID int, date time.Time) error
-	UpdateMessageID(ctx context.Context, publicationID int, messageID int64) error
+	UpdateDeleteTTL(ctx context.Context, publicationID int, ttl time.Duration) error
+	ResetDeleteDate(ctx context.Context, publicationID int) error
+	UpdateMessageIDs(ctx context.Context, publicationID int, messageIDs []int64) error
+	UpdateStorageMessageIDs(ctx context.Context, publicationID int, messageIDs []int64) error
+	UpdateRecurrence(ctx context.Context, publicationID int, recurrence *string) error
+	UpdateSeriesPaused(ctx context.Context, publicationID int, paused bool) error
+	UpdateQueueDate(ctx context.Context, publicationID int, date time.Time) error
+
+	GetQueued(ctx context.Context, channelID int, after time.Time) ([]entity.Publication, error)
+	GetScheduledDates(ctx context.Context, channelID int, after time.Time) ([]time.Time, error)
+	GetAwaiting(ctx context.Context, channelIDs []int, after time.Time) ([]entity.Publication, error)
+	GetForExport(ctx context.Context, channelID int, from *time.Time, to *time.Time, status entity.PublicationStatus) ([]entity.Publication, error)
+	// CountList, GetList - публикации списка list, непустая фраза search ищется в тексте без учета регистра
+	CountList(ctx context.Context, list entity.PublicationList, search string) (int, error)
+	GetList(ctx context.Context, list entity.PublicationList, search string, limit int, offset int) ([]entity.Publication, error)
 
 	IsExistPublication(ctx context.Context, publicationID int) (bool, error)
 }
@@ -49,17 +62,25 @@ func NewPublicationRepo(pg *postgres.Postgres) (PublicationRepo, error) {
 	}, nil
 }
 
+// mediaColumn - вложения публикации p в порядке отправки
+const mediaColumn = `coalesce((select json_agg(json_build_object('type', m.type, 'file_id', m.file_id,
+						'media_group_id', coalesce(m.media_group_id, '')) order by m.position)
+					from publication_media m where m.publication_id = p.id), '[]')`
+
 func (p *publicationRepo) collectRow(row pgx.Row) (*entity.Publication, error) {
 	var publication entity.Publication
 	err := row.Scan(&publication.ID,
 		&publication.PublicationStatus,
 		&publication.PublicationDate,
-		&publication.Image,
+		&publication.Media,
 		&publication.Text,
+		&publication.Entities,
+		&publication.ParseMode,
 		&publication.DeleteDate,
 		&publication.ChannelID,
-		&publication.ButtonUrl,
-		&publication.ButtonText)
+		&publication.Buttons,
+		&publication.Options,
+		&publication.StorageMessageIDs)
 	if checkErr := ErrorHandler(err); checkErr != nil {
 		return nil, checkErr
 	}
@@ -74,83 +95,69 @@ func (p *publicationRepo) collectRows(rows pgx.Rows) ([]entity.Publication, erro
 }
 
 func (p *publicationRepo) GetPublicationByPublicationID(ctx context.Context, publicationID int) (*entity.Publication, error) {
-	query := `select id,publication_status,publication_date,image,text,delete_date,channel_id,button_url,button_text 
-				from publication where id = $1`
+	query := `select p.id,p.publication_status,p.publication_date,` + mediaColumn + `,p.text,coalesce(p.entities, '[]'),coalesce(p.parse_mode, ''),p.delete_date,p.channel_id,coalesce(p.buttons, '[]'),coalesce(p.options, '{}'),
+       				coalesce(p.storage_message_ids, '{}')
+				from publication p where p.id = $1`
 
 	row := p.Pool.QueryRow(ctx, query, publicationID)
 	return p.collectRow(row)
 }
 
-func (p *publicationRepo) CreatePublicationOnlyWithText(ctx context.Context, text string, id int) (int, error) {
-	query := `insert into publication (channel_id,text) values ($1,$2) returning id`
+func (p *publicationRepo) CreatePublicationOnlyWithText(ctx context.Context, text string, entities []entity.MessageEntity, id int) (int, error) {
+	query := `insert into publication (channel_id,text,entities) values ($1,$2,$3) returning id`
 
-	err := p.Pool.QueryRow(ctx, query, id, text).Scan(&id)
+	err := p.Pool.QueryRow(ctx, query, id, text, entities).Scan(&id)
 	return id, err
 }
 
 func (p *publicationRepo) CreatePublication(ctx context.Context, publication *entity.Publication) (int, error) {
-	query := `insert into publication (channel_id,text,image,publication_date,delete_date,button_url,button_text)
-			values ($1,$2,$3,$4,$5,$6,$7) returning id`
+	var id int
+	err := pgx.BeginFunc(ctx, p.Pool, func(tx pgx.Tx) error {
+		var err error
+		id, err = insertPublication(ctx, tx, publication)
+		return err
+	})
+	return id, err
+}
+
+// insertPublication inserts publication with its media in transaction tx
+func insertPublication(ctx context.Context, tx pgx.Tx, publication *entity.Publication) (int, error) {
+	query := `insert into publication (channel_id,text,entities,parse_mode,publication_date,delete_date,buttons,options,storage_message_ids)
+			values ($1,$2,$3,nullif($4, ''),$5,$6,$7,$8,$9) returning id`
 	var id int
 
-	err := p.Pool.QueryRow(ctx, query,
+	err := tx.QueryRow(ctx, query,
 		publication.ChannelID,
 		publication.Text,
-		publication.Image,
+		publication.Entities,
+		publication.ParseMode,
 		publication.PublicationDate,
 		publication.DeleteDate,
-		publication.ButtonUrl,
-		publication.ButtonText).Scan(&id)
-	return id, err
-}
-
-func (p *publicationRepo) GetAllPublicationByChannelID(ctx context.Context, channelID int) ([]entity.Publication, error) {
-	query := `select id,channel_id,text, publication_date, publication_status from publication where channel_id = $1`
-	rows, err := p.Pool.Query(ctx, query, channelID)
+		publication.Buttons,
+		publication.Options,
+		publication.StorageMessageIDs).Scan(&id)
 	if err != nil {
-		return nil, err
+		return id, err
 	}
-	defer rows.Close()
-
-	publications := make([]entity.Publication, 0)
-	for rows.Next() {
-		publication := entity.Publication{}
-		err := rows.Scan(&publication.ID,
-			&publication.ChannelID,
-			&publication.Text,
-			&publication.PublicationDate,
-			&publication.PublicationStatus)
-		if err != nil {
-			if checkErr := ErrorHandler(err); checkErr != nil {
-				return nil, checkErr
-			}
-			return nil, err
-		}
-
-		publications = append(publications, publication)
-	}
-	if rows.Err() != nil {
-		return nil, err
-	}
-
-	return publications, nil
+	return id, insertMedia(ctx, tx, id, publication.Media)
 }
 
-func (p *publicationRepo) UpdatePublicationButtonText(ctx context.Context, publicationID int, buttonText string) error {
-	query := `update publication set  button_text = $1  where id = $2`
-	_, err := p.Pool.Exec(ctx, query, buttonText, publicationID)
+func (p *publicationRepo) UpdateButtons(ctx context.Context, publicationID int, buttons entity.Buttons) error {
+	query := `update publication set buttons = $1 where id = $2`
+	_, err := p.Pool.Exec(ctx, query, buttons, publicationID)
 	return err
 }
 
-func (p *publicationRepo) UpdatePublicationButtonLink(ctx context.Context, publicationID int, buttonLink string) error {
-	query := `update publication set  button_url = $1  where id = $2`
-	_, err := p.Pool.Exec(ctx, query, buttonLink, publicationID)
+func (p *publicationRepo) UpdateOptions(ctx context.Context, publicationID int, options entity.DeliveryOptions) error {
+	query := `update publication set options = $1 where id = $2`
+	_, err := p.Pool.Exec(ctx, query, options, publicationID)
 	return err
 }
 
-func (p *publicationRepo) UpdatePublicationText(ctx context.Context, publicationID int, text string) error {
-	query := `update publication set text = $1 where id = $2`
-	_, err := p.Pool.Exec(ctx, query, text, publicationID)
+// UpdatePublicationText replaces text with its entities, text is not MarkdownV2 anymore
+func (p *publicationRepo) UpdatePublicationText(ctx context.Context, publicationID int, text string, entities []entity.MessageEntity) error {
+	query := `update publication set text = $1, entities = $2, parse_mode = null where id = $3`
+	_, err := p.Pool.Exec(ctx, query, text, entities, publicationID)
 	return err
 }
 
@@ -160,24 +167,78 @@ func (p *publicationRepo) UpdatePublicationStatus(ctx context.Context, publicati
 	return err
 }
 
-func (p *publicationRepo) UpdatePublicationImage(ctx context.Context, publicationID int, image *string) error {
-	query := `update publication set image = $1 where id = $2`
-	_, err := p.Pool.Exec(ctx, query, image, publicationID)
+func (p *publicationRepo) GetMedia(ctx context.Context, publicationID int) ([]entity.Media, error) {
+	query := `select type, file_id, coalesce(media_group_id, '') from publication_media
+				where publication_id = $1 order by position`
+
+	rows, err := p.Pool.Query(ctx, query, publicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Media, error) {
+		var media entity.Media
+		err := row.Scan(&media.Type, &media.FileID, &media.MediaGroupID)
+		return media, err
+	})
+}
+
+// ReplaceMedia replaces all attachments of publication keeping order of media
+func (p *publicationRepo) ReplaceMedia(ctx context.Context, publicationID int, media []entity.Media) error {
+	return pgx.BeginFunc(ctx, p.Pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `delete from publication_media where publication_id = $1`, publicationID); err != nil {
+			return err
+		}
+		return insertMedia(ctx, tx, publicationID, media)
+	})
+}
+
+func insertMedia(ctx context.Context, tx pgx.Tx, publicationID int, media []entity.Media) error {
+	query := `insert into publication_media (publication_id, position, type, file_id, media_group_id)
+				values ($1,$2,$3,$4,nullif($5, ''))`
+	for position, m := range media {
+		if _, err := tx.Exec(ctx, query, publicationID, position, m.Type, m.FileID, m.MediaGroupID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddMedia appends attachment to the end of publication media
+func (p *publicationRepo) AddMedia(ctx context.Context, publicationID int, media entity.Media) error {
+	query := `insert into publication_media (publication_id, position, type, file_id, media_group_id)
+				select $1, coalesce(max(position) + 1, 0), $2, $3, nullif($4, '')
+				from publication_media where publication_id = $1`
+
+	_, err := p.Pool.Exec(ctx, query, publicationID, media.Type, media.FileID, media.MediaGroupID)
 	return err
 }
 
 func (p *publicationRepo) UpdatePublicationDate(ctx context.Context, publicationID int, date time.Time) error {
-	query := `update publication set publication_date = $1 where id = $2`
+	// дата, назначенная вручную, выводит публикацию из очереди
+	query := `update publication set publication_date = $1, queued = false where id = $2`
 	_, err := p.Pool.Exec(ctx, query, date, publicationID)
 	return err
 }
 
 func (p *publicationRepo) UpdateDeleteDate(ctx context.Context, publicationID int, date time.Time) error {
-	query := `update publication set delete_date = $1 where id = $2`
+	query := `update publication set delete_date = $1, delete_ttl_seconds = null where id = $2`
 	_, err := p.Pool.Exec(ctx, query, date, publicationID)
 	return err
 }
 
+func (p *publicationRepo) UpdateDeleteTTL(ctx context.Context, publicationID int, ttl time.Duration) error {
+	query := `update publication set delete_ttl_seconds = $1, delete_date = null where id = $2`
+	_, err := p.Pool.Exec(ctx, query, int64(ttl/time.Second), publicationID)
+	return err
+}
+
+func (p *publicationRepo) ResetDeleteDate(ctx context.Context, publicationID int) error {
+	query := `update publication set delete_date = null, delete_ttl_seconds = null where id = $1`
+	_, err := p.Pool.Exec(ctx, query, publicationID)
+	return err
+}
+
 func (p *publicationRepo) DeletePublication(ctx context.Context, publicationID int) error {
 	query := `delete from publication where id = $1`
 	_, err := p.Pool.Exec(ctx, query, publicationID)
@@ -192,51 +253,35 @@ func (p *publicationRepo) IsExistPublication(ctx context.Context, publicationID
 	return isExist, err
 }
 
-func (p *publicationRepo) GetAwaitingPublication(ctx context.Context) ([]*entity.Publication, error) {
-	query := `select id, publication_date from publication where publication_status  = 'awaits'`
-	rows, err := p.Pool.Query(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	publications := make([]*entity.Publication, 0)
-	for rows.Next() {
-		publication := new(entity.Publication)
-		err := rows.Scan(&publication.ID,
-			&publication.PublicationDate)
-		if err != nil {
-			if checkErr := ErrorHandler(err); checkErr != nil {
-				return nil, checkErr
-			}
-			return nil, err
-		}
-
-		publications = append(publications, publication)
-	}
-	if rows.Err() != nil {
-		return nil, err
-	}
-
-	return publications, nil
-}
-
 func (p *publicationRepo) GetPublicationAndChannel(ctx context.Context, publicationID int) (*entity.Publication, error) {
 	query := `select c.tg_id,
        				c.channel_name,
 					   p.id,
 					   p.publication_status,
 					   p.publication_date,
-					   p.image,
+					   ` + mediaColumn + `,
 					   p.text,
+					   coalesce(p.entities, '[]'),
+					   coalesce(p.parse_mode, ''),
 					   p.delete_date,
 					   p.channel_id,
-					   p.button_url,
-					   p.button_text
+					   coalesce(p.buttons, '[]'),
+					   coalesce(p.options, '{}'),
+					   coalesce(p.storage_message_ids, '{}'),
+					   p.message_ids,
+					   c.catch_up_policy,
+					   c.max_lateness_minutes,
+					   p.recurrence,
+					   p.series_paused,
+					   p.delete_ttl_seconds,
+					   p.sent_at,
+					   c.timezone,
+					   p.queued
 				from publication p
 				join channel c on p.channel_id = c.id
 				where p.id = $1`
 	pub := new(entity.Publication)
+	var deleteTTLSeconds *int64
 
 	err := p.Pool.QueryRow(ctx, query, publicationID).Scan(
 		&pub.TelegramChannelID,
@@ -244,51 +289,32 @@ func (p *publicationRepo) GetPublicationAndChannel(ctx context.Context, publicat
 		&pub.ID,
 		&pub.PublicationStatus,
 		&pub.PublicationDate,
-		&pub.Image,
+		&pub.Media,
 		&pub.Text,
+		&pub.Entities,
+		&pub.ParseMode,
 		&pub.DeleteDate,
 		&pub.ChannelID,
-		&pub.ButtonUrl,
-		&pub.ButtonText)
-	return pub, err
-}
-
-func (p *publicationRepo) GetAllPublicationByID(ctx context.Context, publicationID int) ([]entity.Publication, error) {
-	query := `select p.id, p.channel_id, p.text, p.publication_date, p.publication_status, c.channel_name
-												from publication p
-													join channel c on p.channel_id = c.id
-												where p.channel_id = (select channel_id from channel с
-                                                 join publication p on с.id = p.channel_id
-												  where p.id = $1)`
-	rows, err := p.Pool.Query(ctx, query, publicationID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	publications := make([]entity.Publication, 0)
-	for rows.Next() {
-		publication := entity.Publication{}
-		err := rows.Scan(&publication.ID,
-			&publication.ChannelID,
-			&publication.Text,
-			&publication.PublicationDate,
-			&publication.PublicationStatus,
-			&publication.ChannelName)
-		if err != nil {
-			if checkErr := ErrorHandler(err); checkErr != nil {
-				return nil, checkErr
-			}
-			return nil, err
-		}
-
-		publications = append(publications, publication)
+		&pub.Buttons,
+		&pub.Options,
+		&pub.StorageMessageIDs,
+		&pub.MessageIDs,
+		&pub.CatchUpPolicy,
+		&pub.MaxLatenessMinutes,
+		&pub.Recurrence,
+		&pub.SeriesPaused,
+		&deleteTTLSeconds,
+		&pub.SentAt,
+		&pub.ChannelTimezone,
+		&pub.Queued)
+	if checkErr := ErrorHandler(err); checkErr != nil {
+		return nil, checkErr
 	}
-	if rows.Err() != nil {
-		return nil, err
+	if deleteTTLSeconds != nil {
+		deleteTTL := time.Duration(*deleteTTLSeconds) * time.Second
+		pub.DeleteTTL = &deleteTTL
 	}
-
-	return publications, nil
+	return pub, nil
 }
 
 func (p *publicationRepo) GetOnePublicationByID(ctx context.Context, publicationID int) (*entity.Publication, error) {
@@ -314,42 +340,211 @@ func (p *publicationRepo) GetOnePublicationByID(ctx context.Context, publication
 	return &publication, nil
 }
 
-func (p *publicationRepo) UpdateMessageID(ctx context.Context, publicationID int, messageID int64) error {
-	query := `update publication set message_id = $1 where id = $2`
+// UpdateMessageIDs saves ids of sent messages, time of sending is saved too
+func (p *publicationRepo) UpdateMessageIDs(ctx context.Context, publicationID int, messageIDs []int64) error {
+	query := `update publication set message_ids = $1, sent_at = now() where id = $2`
 
-	_, err := p.Pool.Exec(ctx, query, messageID, publicationID)
+	_, err := p.Pool.Exec(ctx, query, messageIDs, publicationID)
 	return err
 }
 
-func (p *publicationRepo) GetSentAndWaitingToDeletePublication(ctx context.Context) ([]*entity.Publication, error) {
-	query := `select id, message_id, delete_date from publication
-				where publication_status = 'sent' and delete_date > CURRENT_TIMESTAMP and message_id is not null`
-	rows, err := p.Pool.Query(ctx, query)
+func (p *publicationRepo) UpdateStorageMessageIDs(ctx context.Context, publicationID int, messageIDs []int64) error {
+	query := `update publication set storage_message_ids = $1 where id = $2`
+	_, err := p.Pool.Exec(ctx, query, messageIDs, publicationID)
+	return err
+}
+
+func (p *publicationRepo) UpdateRecurrence(ctx context.Context, publicationID int, recurrence *string) error {
+	query := `update publication set recurrence = $1, series_paused = false where id = $2`
+
+	_, err := p.Pool.Exec(ctx, query, recurrence, publicationID)
+	return err
+}
+
+func (p *publicationRepo) UpdateSeriesPaused(ctx context.Context, publicationID int, paused bool) error {
+	query := `update publication set series_paused = $1 where id = $2`
+
+	_, err := p.Pool.Exec(ctx, query, paused, publicationID)
+	return err
+}
+
+func (p *publicationRepo) UpdateQueueDate(ctx context.Context, publicationID int, date time.Time) error {
+	query := `update publication set publication_date = $1, queued = true where id = $2`
+
+	_, err := p.Pool.Exec(ctx, query, date, publicationID)
+	return err
+}
+
+// GetQueued returns awaiting queued publications of channel after given time ordered by publication date
+func (p *publicationRepo) GetQueued(ctx context.Context, channelID int, after time.Time) ([]entity.Publication, error) {
+	query := `select id, channel_id, publication_date
+				from publication
+				where channel_id = $1 and queued and publication_status = 'awaits' and publication_date > $2
+				order by publication_date`
+
+	rows, err := p.Pool.Query(ctx, query, channelID, after)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	publications := make([]*entity.Publication, 0)
-	for rows.Next() {
-		publication := new(entity.Publication)
-		err := rows.Scan(&publication.ID,
-			&publication.MessageID,
-			&publication.DeleteDate,
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Publication, error) {
+		publication := entity.Publication{Queued: true, PublicationStatus: entity.StatusAwaits}
+		err := row.Scan(&publication.ID, &publication.ChannelID, &publication.PublicationDate)
+		return publication, err
+	})
+}
+
+// GetScheduledDates returns publication dates of awaiting publications of channel after given time
+func (p *publicationRepo) GetScheduledDates(ctx context.Context, channelID int, after time.Time) ([]time.Time, error) {
+	query := `select publication_date
+				from publication
+				where channel_id = $1 and publication_status = 'awaits' and publication_date > $2 and recurrence is null
+				order by publication_date`
+
+	rows, err := p.Pool.Query(ctx, query, channelID, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[time.Time])
+}
+
+// GetAwaiting returns awaiting not recurring publications of channels after given time ordered by publication date
+func (p *publicationRepo) GetAwaiting(ctx context.Context, channelIDs []int, after time.Time) ([]entity.Publication, error) {
+	query := `select id, channel_id, publication_date
+				from publication
+				where channel_id = any($1) and publication_status = 'awaits' and publication_date > $2 and recurrence is null
+				order by publication_date`
+
+	rows, err := p.Pool.Query(ctx, query, channelIDs, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Publication, error) {
+		publication := entity.Publication{PublicationStatus: entity.StatusAwaits}
+		err := row.Scan(&publication.ID, &publication.ChannelID, &publication.PublicationDate)
+		return publication, err
+	})
+}
+
+// GetForExport returns publications with channel ordered by publication date, zero channelID and empty status match all,
+// period bounds are optional and to is exclusive
+func (p *publicationRepo) GetForExport(ctx context.Context, channelID int, from *time.Time, to *time.Time, status entity.PublicationStatus) ([]entity.Publication, error) {
+	query := `select p.id,
+					   p.channel_id,
+					   p.publication_status,
+					   p.publication_date,
+					   p.delete_date,
+					   p.delete_ttl_seconds,
+					   p.sent_at,
+					   p.text,
+					   ` + mediaColumn + `,
+					   coalesce(p.buttons, '[]'),
+					   p.message_ids,
+					   p.recurrence,
+					   c.tg_id,
+					   c.channel_name,
+					   c.channel_url
+				from publication p
+				join channel c on p.channel_id = c.id
+				where ($1 = 0 or p.channel_id = $1)
+				  and ($2::timestamptz is null or p.publication_date >= $2)
+				  and ($3::timestamptz is null or p.publication_date < $3)
+				  and ($4 = '' or p.publication_status::text = $4)
+				order by p.publication_date nulls last, p.id`
+
+	rows, err := p.Pool.Query(ctx, query, channelID, from, to, string(status))
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Publication, error) {
+		var (
+			publication      entity.Publication
+			deleteTTLSeconds *int64
 		)
-		if err != nil {
-			if checkErr := ErrorHandler(err); checkErr != nil {
-				return nil, checkErr
-			}
-			return nil, err
+		err := row.Scan(&publication.ID,
+			&publication.ChannelID,
+			&publication.PublicationStatus,
+			&publication.PublicationDate,
+			&publication.DeleteDate,
+			&deleteTTLSeconds,
+			&publication.SentAt,
+			&publication.Text,
+			&publication.Media,
+			&publication.Buttons,
+			&publication.MessageIDs,
+			&publication.Recurrence,
+			&publication.TelegramChannelID,
+			&publication.ChannelName,
+			&publication.ChannelURL)
+		if deleteTTLSeconds != nil {
+			deleteTTL := time.Duration(*deleteTTLSeconds) * time.Second
+			publication.DeleteTTL = &deleteTTL
 		}
+		return publication, err
+	})
+}
 
-		publications = append(publications, publication)
+// listCondition - условие фильтра списка по статусу публикации p
+func listCondition(status entity.PublicationListStatus) string {
+	switch status {
+	case entity.ListAwaits:
+		return `p.publication_status = 'awaits' and p.publication_date is not null`
+	case entity.ListSent:
+		return `p.publication_status in ('sent', 'deleted_by_bot')`
+	case entity.ListFailed:
+		return `p.publication_status in ('error_on_sending', 'error_on_deleting')`
+	case entity.ListDrafts:
+		return `p.publication_status = 'awaits' and p.publication_date is null`
+	default:
+		return `true`
 	}
-	if rows.Err() != nil {
-		return nil, err
+}
+
+// likeEscaper - символы шаблона like во фразе поиска ищутся как обычные
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (p *publicationRepo) CountList(ctx context.Context, list entity.PublicationList, search string) (int, error) {
+	query := `select count(*)
+				from publication p
+				where ($1 = 0 or p.channel_id = $1)
+				  and ($2 = '' or p.text ilike '%' || $2 || '%')
+				  and ` + listCondition(list.Status)
+
+	var count int
+	err := p.Pool.QueryRow(ctx, query, list.ChannelID, likeEscaper.Replace(search)).Scan(&count)
+	return count, err
+}
+
+func (p *publicationRepo) GetList(ctx context.Context, list entity.PublicationList, search string, limit int, offset int) ([]entity.Publication, error) {
+	order := "desc"
+	if list.Asc {
+		order = "asc"
 	}
+	query := `select p.id, p.channel_id, p.text, p.publication_date, p.publication_status, c.channel_name
+				from publication p
+				join channel c on p.channel_id = c.id
+				where ($1 = 0 or p.channel_id = $1)
+				  and ($2 = '' or p.text ilike '%' || $2 || '%')
+				  and ` + listCondition(list.Status) + `
+				order by p.publication_date ` + order + ` nulls last, p.id ` + order + `
+				limit $3 offset $4`
 
-	return publications, nil
+	rows, err := p.Pool.Query(ctx, query, list.ChannelID, likeEscaper.Replace(search), limit, offset)
+	if err != nil {
+		return nil, err
+	}
 
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Publication, error) {
+		var publication entity.Publication
+		err := row.Scan(&publication.ID,
+			&publication.ChannelID,
+			&publication.Text,
+			&publication.PublicationDate,
+			&publication.PublicationStatus,
+			&publication.ChannelName)
+		return publication, err
+	})
 }
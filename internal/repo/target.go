@@ -0,0 +1,121 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type TargetRepo interface {
+	Create(ctx context.Context, publicationID int, channelID int) (int, error)
+	GetByID(ctx context.Context, id int) (*entity.Target, error)
+	GetByPublicationID(ctx context.Context, publicationID int) ([]entity.Target, error)
+	DeleteByID(ctx context.Context, id int) error
+
+	UpdateSent(ctx context.Context, id int, messageIDs []int64, sentAt time.Time) error
+	UpdateStatus(ctx context.Context, id int, status entity.PublicationStatus, lastError *string) error
+	UpdateOffsets(ctx context.Context, id int, sendOffsetMinutes int, deleteOffsetMinutes int) error
+}
+
+type targetRepo struct {
+	*postgres.Postgres
+}
+
+func NewTargetRepo(pg *postgres.Postgres) (TargetRepo, error) {
+	if pg == nil {
+		return nil, errors.New("postgres connection is nil")
+	}
+
+	return &targetRepo{
+		pg,
+	}, nil
+}
+
+const targetColumns = `t.id, t.publication_id, t.channel_id, t.status, t.message_ids, t.send_offset_minutes,
+		t.delete_offset_minutes, t.sent_at, t.error, c.tg_id, c.channel_name`
+
+func (t *targetRepo) collectRow(row pgx.Row) (*entity.Target, error) {
+	var target entity.Target
+	err := row.Scan(&target.ID,
+		&target.PublicationID,
+		&target.ChannelID,
+		&target.Status,
+		&target.MessageIDs,
+		&target.SendOffsetMinutes,
+		&target.DeleteOffsetMinutes,
+		&target.SentAt,
+		&target.Error,
+		&target.TelegramChannelID,
+		&target.ChannelName)
+	if checkErr := ErrorHandler(err); checkErr != nil {
+		return nil, checkErr
+	}
+	return &target, nil
+}
+
+func (t *targetRepo) Create(ctx context.Context, publicationID int, channelID int) (int, error) {
+	query := `insert into publication_target (publication_id, channel_id) values ($1,$2) returning id`
+	var id int
+
+	err := t.Pool.QueryRow(ctx, query, publicationID, channelID).Scan(&id)
+	return id, ErrorHandler(err)
+}
+
+func (t *targetRepo) GetByID(ctx context.Context, id int) (*entity.Target, error) {
+	query := `select ` + targetColumns + ` from publication_target t
+				join channel c on t.channel_id = c.id
+				where t.id = $1`
+
+	return t.collectRow(t.Pool.QueryRow(ctx, query, id))
+}
+
+func (t *targetRepo) GetByPublicationID(ctx context.Context, publicationID int) ([]entity.Target, error) {
+	query := `select ` + targetColumns + ` from publication_target t
+				join channel c on t.channel_id = c.id
+				where t.publication_id = $1
+				order by t.id`
+
+	rows, err := t.Pool.Query(ctx, query, publicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Target, error) {
+		target, err := t.collectRow(row)
+		if err != nil {
+			return entity.Target{}, err
+		}
+		return *target, nil
+	})
+}
+
+func (t *targetRepo) DeleteByID(ctx context.Context, id int) error {
+	query := `delete from publication_target where id = $1`
+
+	_, err := t.Pool.Exec(ctx, query, id)
+	return err
+}
+
+func (t *targetRepo) UpdateSent(ctx context.Context, id int, messageIDs []int64, sentAt time.Time) error {
+	query := `update publication_target set status = 'sent', message_ids = $1, sent_at = $2, error = null where id = $3`
+
+	_, err := t.Pool.Exec(ctx, query, messageIDs, sentAt, id)
+	return err
+}
+
+func (t *targetRepo) UpdateStatus(ctx context.Context, id int, status entity.PublicationStatus, lastError *string) error {
+	query := `update publication_target set status = $1, error = $2 where id = $3`
+
+	_, err := t.Pool.Exec(ctx, query, status, lastError, id)
+	return err
+}
+
+func (t *targetRepo) UpdateOffsets(ctx context.Context, id int, sendOffsetMinutes int, deleteOffsetMinutes int) error {
+	query := `update publication_target set send_offset_minutes = $1, delete_offset_minutes = $2 where id = $3`
+
+	_, err := t.Pool.Exec(ctx, query, sendOffsetMinutes, deleteOffsetMinutes, id)
+	return err
+}
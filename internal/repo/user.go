@@ -20,6 +20,7 @@ type UserRepo interface {
 	IsUserExistByUserID(ctx context.Context, userID int64) (bool, error)
 
 	UpdateRoleByUsername(ctx context.Context, role entity.UserRole, username string) error
+	UpdateTimezone(ctx context.Context, id int64, timezone string) error
 }
 
 type userRepo struct {
@@ -36,9 +37,11 @@ func NewUserRepo(pg *postgres.Postgres) (UserRepo, error) {
 	}, nil
 }
 
+const userColumns = `id,tg_username,created_at,channel_from,user_role,timezone`
+
 func (u *userRepo) collectRow(row pgx.Row) (*entity.User, error) {
 	var user entity.User
-	err := row.Scan(&user.ID, &user.TGUsername, &user.CreatedAt, &user.ChannelFrom, &user.UserRole)
+	err := row.Scan(&user.ID, &user.TGUsername, &user.CreatedAt, &user.ChannelFrom, &user.UserRole, &user.Timezone)
 	if checkErr := ErrorHandler(err); checkErr != nil {
 		return nil, checkErr
 	}
@@ -54,7 +57,7 @@ func (u *userRepo) collectRows(rows pgx.Rows) ([]entity.User, error) {
 }
 
 func (u *userRepo) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
-	query := `select * from "user" where tg_username = $1`
+	query := `select ` + userColumns + ` from "user" where tg_username = $1`
 
 	row := u.Pool.QueryRow(ctx, query, username)
 	return u.collectRow(row)
@@ -68,7 +71,7 @@ func (u *userRepo) CreateUser(ctx context.Context, user *entity.User) error {
 }
 
 func (u *userRepo) GetAllUsers(ctx context.Context) ([]entity.User, error) {
-	query := `select * from "user"`
+	query := `select ` + userColumns + ` from "user"`
 
 	rows, err := u.Pool.Query(ctx, query)
 	if err != nil {
@@ -78,7 +81,7 @@ func (u *userRepo) GetAllUsers(ctx context.Context) ([]entity.User, error) {
 }
 
 func (u *userRepo) GetUserByID(ctx context.Context, id int64) (*entity.User, error) {
-	query := `select * from "user" where id = $1`
+	query := `select ` + userColumns + ` from "user" where id = $1`
 
 	row := u.Pool.QueryRow(ctx, query, id)
 	return u.collectRow(row)
@@ -104,7 +107,7 @@ func (u *userRepo) IsUserExistByUsernameTg(ctx context.Context, usernameTg strin
 }
 
 func (u *userRepo) GetAllAdmin(ctx context.Context) ([]entity.User, error) {
-	query := `select * from "user" where user_role = 'admin' or user_role = 'superAdmin'`
+	query := `select ` + userColumns + ` from "user" where user_role = 'admin' or user_role = 'superAdmin'`
 
 	rows, err := u.Pool.Query(ctx, query)
 	if err != nil {
@@ -124,3 +127,10 @@ func (u *userRepo) IsUserExistByUserID(ctx context.Context, userID int64) (bool,
 
 	return isExist, nil
 }
+
+func (u *userRepo) UpdateTimezone(ctx context.Context, id int64, timezone string) error {
+	query := `update "user" set timezone = $1 where id = $2`
+
+	_, err := u.Pool.Exec(ctx, query, timezone, id)
+	return err
+}
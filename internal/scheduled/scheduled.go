@@ -3,292 +3,605 @@ package scheduled
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/Enthreeka/tg-posting-bot/internal/entity"
 	service "github.com/Enthreeka/tg-posting-bot/internal/usecase"
-	store "github.com/Enthreeka/tg-posting-bot/pkg/local_storage"
+	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
 	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
 	customMsg "github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"math/rand/v2"
 	"sync"
 	"time"
 )
 
+const (
+	// pollInterval max sleep of scheduler, jobs can be added by another process directly in database
+	pollInterval = 30 * time.Second
+	// jobLease time for which claimed job is locked, after it job can be claimed again
+	jobLease = 5 * time.Minute
+	// claimLimit max jobs claimed at once
+	claimLimit = 50
+	// retryBaseDelay delay before second attempt, doubled for each next attempt up to retryMaxDelay
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = time.Hour
+	// catchUpGrace - на сколько задача может опоздать сверх максимального опоздания канала, прежде чем к ней
+	// применится политика просроченных публикаций, например при смене лидера
+	catchUpGrace = time.Minute
+)
+
 type Schedule interface {
-	LoadDatabaseInPubDelStore(ctx context.Context) error
+	CatchUpOverdue(ctx context.Context) error
 	StartDel(ctx context.Context) error
 	StartPub(ctx context.Context) error
 }
 
 type schedule struct {
 	publicationService service.PublicationService
+	userService        service.UserService
+	jobService         service.JobService
+	seriesService      service.SeriesService
+	windowService      service.PostingWindowService
+	targetService      service.TargetService
 	tgMsg              customMsg.Message
-	pubStore           *store.PublicationArray
 	log                *logger.Logger
 }
 
 func NewSchedule(publicationService service.PublicationService,
+	userService service.UserService,
+	jobService service.JobService,
+	seriesService service.SeriesService,
+	windowService service.PostingWindowService,
+	targetService service.TargetService,
 	tgMsg customMsg.Message,
-	pubStore *store.PublicationArray,
 	log *logger.Logger) (Schedule, error) {
 	if tgMsg == nil {
 		return nil, errors.New("tgMsg cannot be nil")
 	}
-	if pubStore == nil {
-		return nil, errors.New("pubStore cannot be nil")
+	if jobService == nil {
+		return nil, errors.New("jobService cannot be nil")
 	}
 	if publicationService == nil {
 		return nil, errors.New("publicationService cannot be nil")
 	}
+	if seriesService == nil {
+		return nil, errors.New("seriesService cannot be nil")
+	}
+	if userService == nil {
+		return nil, errors.New("userService cannot be nil")
+	}
+	if windowService == nil {
+		return nil, errors.New("windowService cannot be nil")
+	}
+	if targetService == nil {
+		return nil, errors.New("targetService cannot be nil")
+	}
 	if log == nil {
 		return nil, errors.New("log cannot be nil")
 	}
 
 	return &schedule{
+		userService:        userService,
+		jobService:         jobService,
+		seriesService:      seriesService,
+		windowService:      windowService,
+		targetService:      targetService,
 		tgMsg:              tgMsg,
-		pubStore:           pubStore,
 		log:                log,
 		publicationService: publicationService,
 	}, nil
 }
 
-func (s *schedule) LoadDatabaseInPubDelStore(ctx context.Context) error {
-	publications, err := s.publicationService.GetAwaitingPublication(ctx)
+// CatchUpOverdue applies channel catch-up policy for jobs which became overdue while bot was down,
+// slightly late jobs and jobs waiting for retry are executed as usual
+func (s *schedule) CatchUpOverdue(ctx context.Context) error {
+	jobs, err := s.jobService.GetOverdue(ctx, time.Now().Add(-catchUpGrace))
 	if err != nil {
-		s.log.Error("Failed to get pub publications", "err", err)
+		s.log.Error("Failed to get overdue jobs: %v", err)
 		return err
 	}
 
-	loc, err := time.LoadLocation("Europe/Moscow")
-	if err != nil {
-		s.log.Error("Failed to load time location", "err", err)
-		return err
+	var countLate int
+	for _, job := range jobs {
+		if s.catchUp(ctx, job) {
+			countLate++
+		}
 	}
+	s.log.Info("Overdue jobs - %d, will be executed late - %d", len(jobs), countLate)
 
-	var countPubs int
-	for _, value := range publications {
-		if value.PublicationDate == nil {
-			continue
+	return nil
+}
+
+// catchUp returns true if overdue job must be executed late
+func (s *schedule) catchUp(ctx context.Context, job entity.Job) bool {
+	lateness := time.Since(job.RunAt)
+
+	switch job.CatchUpPolicy {
+	case entity.CatchUpSendLate:
+		// удаление с опозданием не вредит, ограничение только на отправку
+		if job.Kind == entity.JobDelete || lateness <= job.MaxLateness() {
+			s.log.Info("Overdue job will be executed late: %s, lateness - %v", job, lateness)
+			return true
+		}
+		s.log.Info("Overdue job exceeded max lateness: %s, lateness - %v", job, lateness)
+	case entity.CatchUpAsk:
+		if err := s.jobService.Hold(ctx, job.ID, entity.HoldOverdue); err != nil {
+			s.log.Error("Failed to hold job: %s, err - %v", job, err)
+			return false
 		}
 
-		if value.PublicationDate.Before(time.Now().In(loc)) {
-			go func() {
-				if err := s.publicationService.UpdatePublicationStatus(ctx, value.ID, entity.StatusErrorOnSending); err != nil {
-					s.log.Error("Failed to update publication status err: ", err)
-				}
-			}()
-			continue
+		if job.Kind == entity.JobDelete {
+			s.notifyAdmins(ctx, func(loc *time.Location) string {
+				return fmt.Sprintf("Публикация #%d в канале %s должна была быть удалена %s, опоздание %s.\n\nЧто сделать с публикацией?",
+					job.PublicationID, job.ChannelName, entity.FormatTime(&job.RunAt, loc), lateness.Round(time.Minute))
+			}, markup.OverdueDelete(job))
+		} else {
+			s.notifyAdmins(ctx, func(loc *time.Location) string {
+				return fmt.Sprintf("Публикация #%d в канале %s должна была выйти %s, опоздание %s.\n\nЧто сделать с публикацией?",
+					job.PublicationID, job.ChannelName, entity.FormatTime(&job.RunAt, loc), lateness.Round(time.Minute))
+			}, markup.OverduePublication(job))
 		}
-		s.pubStore.AppendPub(&store.PubData{
-			PublicationID: value.ID,
-			PubDate:       *value.PublicationDate,
-		})
-		countPubs++
+		return false
+	}
+
+	status := entity.StatusErrorOnSending
+	if job.Kind == entity.JobDelete {
+		status = entity.StatusErrorOnDeleting
 	}
-	s.log.Info("Successfully loaded publications count - %d", countPubs)
+	s.failJob(ctx, job, status, fmt.Errorf("overdue by %v, catch-up policy %s", lateness.Round(time.Minute), job.CatchUpPolicy))
+	return false
+}
 
-	delPublications, err := s.publicationService.GetSentAndWaitingToDeletePublication(ctx)
+// notifyAdmins - text строится для каждого администратора, чтобы время было в его часовом поясе
+func (s *schedule) notifyAdmins(ctx context.Context, text func(loc *time.Location) string, keyMarkup tgbotapi.InlineKeyboardMarkup) {
+	admins, err := s.userService.GetAllAdmin(ctx)
 	if err != nil {
-		s.log.Error("Failed to get del publications", "err", err)
-		return err
+		s.log.Error("Failed to get admins: %v", err)
+		return
 	}
 
-	var countDelPubs int
-	for _, value := range delPublications {
-		if value.DeleteDate.Before(time.Now().In(loc)) {
-			go func() {
-				if err := s.publicationService.UpdatePublicationStatus(ctx, value.ID, entity.StatusErrorOnDeleting); err != nil {
-					s.log.Error("Failed to update publication status err: ", err)
-				}
-			}()
-			continue
+	for _, admin := range admins {
+		if _, err := s.tgMsg.SendNewMessage(admin.ID, &keyMarkup, text(admin.Location())); err != nil {
+			s.log.Error("Failed to notify admin - %d, err - %v", admin.ID, err)
+		}
+	}
+}
+
+func (s *schedule) failJob(ctx context.Context, job entity.Job, status entity.PublicationStatus, cause error) {
+	if err := s.jobService.Fail(ctx, job, cause); err != nil {
+		s.log.Error("Failed to mark job failed: %s, err - %v", job, err)
+	}
+
+	// ошибка доставки в дополнительный канал не меняет статус публикации
+	if job.TargetID != nil {
+		if err := s.targetService.UpdateStatus(ctx, *job.TargetID, status, cause); err != nil {
+			s.log.Error("Failed to update target - %d, err - %v", *job.TargetID, err)
 		}
-		s.pubStore.AppendDel(&store.PubData{
-			PublicationID: value.ID,
-			DelDate:       *value.DeleteDate,
-			SentMsgID:     int(value.MessageID),
-		})
-		countDelPubs++
+		return
 	}
 
-	s.log.Info("Successfully loaded del publications count - %d", countDelPubs)
+	// у серии ошибка относится только к одному вхождению, серия продолжается
+	if job.OccurrenceID != nil {
+		if err := s.seriesService.UpdateOccurrenceStatus(ctx, *job.OccurrenceID, entity.OccurrenceErrorOnDeleting, cause); err != nil {
+			s.log.Error("Failed to update occurrence - %d, err - %v", *job.OccurrenceID, err)
+		}
+		return
+	}
+	if job.Kind == entity.JobPublish {
+		publication, err := s.publicationService.GetPublicationAndChannel(ctx, job.PublicationID)
+		if err != nil {
+			s.log.Error("Failed to get publication by publicationID: publicationID - %d, err - %v", job.PublicationID, err)
+		}
+		if err == nil && publication.IsSeries() {
+			errText := cause.Error()
+			if _, err := s.seriesService.CreateOccurrence(ctx, &entity.Occurrence{
+				PublicationID: job.PublicationID,
+				RunAt:         job.RunAt,
+				Status:        entity.OccurrenceErrorOnSending,
+				Error:         &errText,
+			}); err != nil {
+				s.log.Error("Failed to create occurrence of publication - %d, err - %v", job.PublicationID, err)
+			}
+			s.nextOccurrence(ctx, job, publication)
+			return
+		}
+	}
 
-	return nil
+	if err := s.publicationService.UpdatePublicationStatus(ctx, job.PublicationID, status); err != nil {
+		s.log.Error("Failed to update publication, publicationID - %d, status - %v, err - %v", job.PublicationID, status, err)
+	}
 }
 
-func (s *schedule) StartDel(ctx context.Context) error {
-	timeTicker := time.NewTicker(time.Minute)
-	defer func() {
-		timeTicker.Stop()
-		s.log.Info("Scheduler del stopped")
-	}()
+// retryOrFail reschedules job with backoff if error is temporary and attempts are left, otherwise job is failed
+func (s *schedule) retryOrFail(ctx context.Context, job entity.Job, status entity.PublicationStatus, cause error) {
+	retry, retryAfter := customMsg.Retryable(cause)
+	s.retryOrFailWith(ctx, job, status, cause, retry, retryAfter)
+}
 
-	loc, err := time.LoadLocation("Europe/Moscow")
-	if err != nil {
-		return err
+// retryOrFailWith - retryOrFail for errors which are not returned by telegram
+func (s *schedule) retryOrFailWith(ctx context.Context, job entity.Job, status entity.PublicationStatus, cause error,
+	retry bool, retryAfter time.Duration) {
+	if !retry || job.Attempts >= job.MaxAttempts {
+		s.log.Info("Job failed after %d attempts: %s, err - %v", job.Attempts, job, cause)
+		s.failJob(ctx, job, status, cause)
+		return
 	}
 
-	for {
-		select {
-		case <-timeTicker.C:
-			s.log.Info("count queue delete publication: %d", s.pubStore.LenDel())
+	delay := backoff(job.Attempts, retryAfter)
+	if err := s.jobService.Retry(ctx, job, time.Now().Add(delay), cause); err != nil {
+		s.log.Error("Failed to reschedule job: %s, err - %v", job, err)
+		return
+	}
+	s.log.Info("Job will be retried in %v, attempt %d of %d: %s", delay.Round(time.Second), job.Attempts, job.MaxAttempts, job)
+}
 
-			copyDelData := make([]*store.PubData, 0, s.pubStore.LenDel())
-			copyDelData = append(copyDelData, s.pubStore.GetDel()...)
+// backoff returns delay before next attempt, telegram retry_after has priority over exponential delay
+func backoff(attempt int, retryAfter time.Duration) time.Duration {
+	delay := retryAfter
+	if delay == 0 {
+		delay = retryMaxDelay
+		if attempt < 12 {
+			delay = min(retryBaseDelay<<max(attempt-1, 0), retryMaxDelay)
+		}
+	}
+	// jitter до 20%, чтобы повторы разных публикаций не совпадали
+	return delay + rand.N(delay/5+1)
+}
 
-			var wg sync.WaitGroup
+// messageIDs - альбом отправляется несколькими сообщениями, задача удаления хранит их все
+func messageIDs(sentIDs []int) []int64 {
+	ids := make([]int64, 0, len(sentIDs))
+	for _, id := range sentIDs {
+		ids = append(ids, int64(id))
+	}
+	return ids
+}
 
-			now := time.Now().In(loc).Round(time.Minute)
-			for _, value := range copyDelData {
-				if value == nil {
-					continue
-				}
+// waitTimer returns timer which fires when nearest job is due, but not later than pollInterval
+func (s *schedule) waitTimer(ctx context.Context, kind entity.JobKind) *time.Timer {
+	next, err := s.jobService.NextRunAt(ctx, kind)
+	if err != nil {
+		s.log.Error("Failed to get next run of %s jobs: %v", kind, err)
+	}
+	if err != nil || next == nil {
+		return time.NewTimer(pollInterval)
+	}
+	return time.NewTimer(min(max(time.Until(*next), 0), pollInterval))
+}
 
-				if now.Equal(value.DelDate.Round(time.Minute)) {
-					s.log.Info("started delete publication: %v", value)
+// run sleeps until next due job of kind, claims all due jobs and executes them
+func (s *schedule) run(ctx context.Context, kind entity.JobKind, execute func(ctx context.Context, job entity.Job)) error {
+	for {
+		timer := s.waitTimer(ctx, kind)
 
+		select {
+		case <-timer.C:
+			for {
+				jobs, err := s.jobService.Claim(ctx, kind, jobLease, claimLimit)
+				if err != nil {
+					s.log.Error("Failed to claim %s jobs: %v", kind, err)
+					break
+				}
+				if len(jobs) == 0 {
+					break
+				}
+				s.log.Info("claimed %s jobs: %d", kind, len(jobs))
+
+				var wg sync.WaitGroup
+				for _, job := range jobs {
 					wg.Add(1)
-					go func(channelID int64, sentMsgID int, pubID int, value *store.PubData) {
+					go func(job entity.Job) {
 						defer wg.Done()
-						s.pubStore.RemoveDel(value)
-
-						if channelID == 0 {
-							publication, err := s.publicationService.GetPublicationAndChannel(ctx, pubID)
-							if err != nil {
-								s.log.Error("Failed to get publication by publicationID: publicationID - %d, err - %v", pubID, err)
-							}
-							channelID = publication.TelegramChannelID
-						}
-
-						var status entity.PublicationStatus
-						if err = s.tgMsg.DeleteMessage(channelID, sentMsgID); err != nil {
-							s.log.Error("Failed to delete message from channel - %d, sentMsgID -%d, err - %v", channelID, sentMsgID, err)
-							status = entity.StatusErrorOnDeleting
-						}
-						if err == nil {
-							status = entity.StatusDeletedByBot
-						}
-
-						if err = s.publicationService.UpdatePublicationStatus(ctx, pubID, status); err != nil {
-							s.log.Error("Failed to update publication, publicationID - %d, status - %v, err - %v", pubID, status, err)
-						}
-
-						if status == entity.StatusDeletedByBot {
-							if err := s.publicationService.DeletePublication(ctx, pubID); err != nil {
-								s.log.Error("Failed to delete publication, publicationID - %d, status - %v, err - %v", pubID, status, err)
-							}
-						}
-
-						s.log.Info("Deleted publication for publicationID %d", pubID)
-					}(value.ChannelID, value.SentMsgID, value.PublicationID, value)
+						execute(ctx, job)
+					}(job)
 				}
+				wg.Wait()
 			}
 
-			wg.Wait()
-			copyDelData = nil
+		case <-s.jobService.Wake(kind):
+			timer.Stop()
 
 		case <-ctx.Done():
+			timer.Stop()
 			s.log.Error("context canceled")
 			return ctx.Err()
 		}
 	}
 }
 
+func (s *schedule) StartDel(ctx context.Context) error {
+	defer s.log.Info("Scheduler del stopped")
+	return s.run(ctx, entity.JobDelete, s.deletePublication)
+}
+
 func (s *schedule) StartPub(ctx context.Context) error {
-	timeTicker := time.NewTicker(time.Minute)
-	defer func() {
-		timeTicker.Stop()
-		s.log.Info("Scheduler pub stopped")
-	}()
+	defer s.log.Info("Scheduler pub stopped")
+	return s.run(ctx, entity.JobPublish, s.sendPublication)
+}
 
-	loc, err := time.LoadLocation("Europe/Moscow")
+func (s *schedule) deletePublication(ctx context.Context, job entity.Job) {
+	s.log.Info("started delete publication: %s", job)
+
+	if job.ChatID == nil || len(job.MessageIDs) == 0 {
+		s.failJob(ctx, job, entity.StatusErrorOnDeleting, errors.New("delete job without chat or message id"))
+		return
+	}
+
+	for _, msgID := range job.MessageIDs {
+		if err := s.tgMsg.DeleteMessage(*job.ChatID, int(msgID)); err != nil {
+			// сообщение уже удалено вручную или предыдущей попыткой
+			if customMsg.IsMessageNotFound(err) {
+				continue
+			}
+			s.log.Error("Failed to delete message from channel - %d, sentMsgID -%d, err - %v", *job.ChatID, msgID, err)
+			s.retryOrFail(ctx, job, entity.StatusErrorOnDeleting, err)
+			return
+		}
+	}
+
+	if err := s.jobService.Complete(ctx, job.ID); err != nil {
+		s.log.Error("Failed to complete job: %s, err - %v", job, err)
+	}
+
+	if job.OccurrenceID != nil {
+		if err := s.seriesService.UpdateOccurrenceStatus(ctx, *job.OccurrenceID, entity.OccurrenceDeletedByBot, nil); err != nil {
+			s.log.Error("Failed to update occurrence - %d, err - %v", *job.OccurrenceID, err)
+		}
+		s.log.Info("Deleted occurrence %d of publicationID %d", *job.OccurrenceID, job.PublicationID)
+		return
+	}
+
+	if job.TargetID != nil {
+		if err := s.targetService.UpdateStatus(ctx, *job.TargetID, entity.StatusDeletedByBot, nil); err != nil {
+			s.log.Error("Failed to update target - %d, err - %v", *job.TargetID, err)
+		}
+		s.log.Info("Deleted target %d of publicationID %d", *job.TargetID, job.PublicationID)
+		s.releaseTarget(ctx, job.PublicationID)
+		return
+	}
+
+	s.releasePublication(ctx, job.PublicationID, entity.StatusDeletedByBot)
+	s.log.Info("Deleted publication for publicationID %d", job.PublicationID)
+}
+
+// releasePublication deletes publication which delivery is finished,
+// while deliveries to additional channels are pending publication is kept with status
+func (s *schedule) releasePublication(ctx context.Context, publicationID int, status entity.PublicationStatus) {
+	active, err := s.jobService.HasActive(ctx, publicationID)
 	if err != nil {
-		return err
+		s.log.Error("Failed to check jobs of publication - %d, err - %v", publicationID, err)
+		return
 	}
 
-	for {
-		select {
-		case <-timeTicker.C:
-			s.log.Info("count queue publication: %d", s.pubStore.LenPub())
+	if active {
+		if err := s.publicationService.UpdatePublicationStatus(ctx, publicationID, status); err != nil {
+			s.log.Error("Failed to update publication, publicationID - %d, status - %v, err - %v", publicationID, status, err)
+		}
+		return
+	}
 
-			now := time.Now().In(loc).Round(time.Minute)
+	if err := s.publicationService.DeletePublication(ctx, publicationID); err != nil {
+		s.log.Error("Failed to delete publication, publicationID - %d, err - %v", publicationID, err)
+	}
+}
 
-			copyPubData := make([]*store.PubData, 0, s.pubStore.LenPub())
-			copyPubData = append(copyPubData, s.pubStore.GetPub()...)
+// releaseTarget deletes publication after last delivery to additional channel if its own channel is already done
+func (s *schedule) releaseTarget(ctx context.Context, publicationID int) {
+	publication, err := s.publicationService.GetPublicationAndChannel(ctx, publicationID)
+	if err != nil {
+		s.log.Error("Failed to get publication by publicationID: publicationID - %d, err - %v", publicationID, err)
+		return
+	}
 
-			var wg sync.WaitGroup
+	if publication.PublicationStatus == entity.StatusSent || publication.PublicationStatus == entity.StatusDeletedByBot {
+		s.releasePublication(ctx, publicationID, publication.PublicationStatus)
+	}
+}
 
-			for _, value := range copyPubData {
-				if value == nil {
-					continue
-				}
+func (s *schedule) sendPublication(ctx context.Context, job entity.Job) {
+	s.log.Info("started send publication: %s", job)
 
-				pubDate := value.PubDate.Round(time.Minute)
-				if now.Equal(pubDate) {
-					s.log.Info("started send publication: %v", value)
+	publication, err := s.publicationService.GetPublicationAndChannel(ctx, job.PublicationID)
+	if err != nil {
+		s.log.Error("Failed to get publication by publicationID: publicationID - %d, err - %v",
+			job.PublicationID, err)
+		// ошибка базы временная и повторяется, пока есть попытки, удаленную публикацию отправить нельзя
+		s.retryOrFailWith(ctx, job, entity.StatusErrorOnSending, err, !errors.Is(err, customErr.ErrNoRows), 0)
+		return
+	}
 
-					wg.Add(1)
-					go func(value *store.PubData) {
-						defer wg.Done()
+	if job.TargetID != nil {
+		s.sendTarget(ctx, job, publication)
+		return
+	}
 
-						s.pubStore.RemovePub(value)
-
-						publication, err := s.publicationService.GetPublicationAndChannel(ctx, value.PublicationID)
-						if err != nil {
-							s.log.Error("Failed to get publication by publicationID: publicationID - %d, err - %v",
-								value.PublicationID, err)
-						}
-
-						if publication != nil && publication.PublicationStatus != entity.StatusSent {
-							var (
-								status    entity.PublicationStatus
-								isDelDate bool
-							)
-							msgID, err := s.tgMsg.SendMessageToUser(publication.TelegramChannelID, publication)
-							if err != nil {
-								s.log.Error("Failed to send message to channel - %d, err - %v", publication.ChannelID, err)
-								status = entity.StatusErrorOnSending
-							}
-							if err == nil {
-								status = entity.StatusSent
-								if publication.DeleteDate != nil {
-									isDelDate = true
-									s.pubStore.AppendDel(&store.PubData{
-										PublicationID: publication.ID,
-										DelDate:       *publication.DeleteDate,
-										SentMsgID:     msgID,
-										ChannelID:     publication.TelegramChannelID,
-									})
-
-									if err := s.publicationService.UpdateMessageID(ctx, publication.ID, int64(msgID)); err != nil {
-										s.log.Error("Failed to update message id - %d, err - %v", publication.ID, err)
-									}
-								}
-							}
-
-							// если сообщение нужно удалить через отложенное удаление, то обновляем его статус
-							// иначе удаляем его из базы
-							if isDelDate == true {
-								if err = s.publicationService.UpdatePublicationStatus(ctx, publication.ID, status); err != nil {
-									s.log.Error("Failed to update publication, publicationID - %d, status - %v, err - %v", value.PublicationID, status, err)
-								}
-							} else {
-								if err = s.publicationService.DeletePublication(ctx, publication.ID); err != nil {
-									s.log.Error("Failed to delete publication, publicationID - %d, status - %v, err - %v", value.PublicationID, status, err)
-								}
-							}
-
-							s.log.Info("Sent publication for publicationID: %d, channel_id: %d, msg_id: %d",
-								value.PublicationID, publication.TelegramChannelID, msgID)
-						}
-					}(value)
-				}
-			}
+	if publication.PublicationStatus == entity.StatusSent {
+		if err := s.jobService.Complete(ctx, job.ID); err != nil {
+			s.log.Error("Failed to complete job: %s, err - %v", job, err)
+		}
+		return
+	}
 
-			wg.Wait()
-			copyPubData = nil
+	if s.inBlackout(ctx, job, int(publication.ChannelID), publication.ChannelName) {
+		return
+	}
 
-		case <-ctx.Done():
-			s.log.Error("context canceled")
-			return ctx.Err()
+	sentIDs, err := s.tgMsg.SendMessageToUser(publication.TelegramChannelID, publication)
+	if err != nil {
+		s.log.Error("Failed to send message to channel - %d, err - %v", publication.ChannelID, err)
+		s.deletePartial(publication.TelegramChannelID, sentIDs)
+		s.retryOrFail(ctx, job, entity.StatusErrorOnSending, err)
+		return
+	}
+	msgIDs := messageIDs(sentIDs)
+
+	if publication.IsSeries() {
+		s.sentOccurrence(ctx, job, publication, msgIDs)
+		return
+	}
+
+	if err := s.jobService.Complete(ctx, job.ID); err != nil {
+		s.log.Error("Failed to complete job: %s, err - %v", job, err)
+	}
+
+	// если сообщение нужно удалить через отложенное удаление, то обновляем его статус
+	// иначе удаляем его из базы
+	// время жизни сообщения отсчитывается от фактической отправки, а не от запланированной
+	if deleteAt, ok := publication.DeleteAt(time.Now()); ok {
+		if err := s.jobService.ScheduleDelete(ctx, publication.ID, deleteAt,
+			publication.TelegramChannelID, msgIDs); err != nil {
+			s.log.Error("Failed to schedule delete of publication - %d, err - %v", publication.ID, err)
+		}
+
+		if err := s.publicationService.UpdateMessageIDs(ctx, publication.ID, msgIDs); err != nil {
+			s.log.Error("Failed to update message id - %d, err - %v", publication.ID, err)
+		}
+
+		if err = s.publicationService.UpdatePublicationStatus(ctx, publication.ID, entity.StatusSent); err != nil {
+			s.log.Error("Failed to update publication, publicationID - %d, status - %v, err - %v", publication.ID, entity.StatusSent, err)
+		}
+	} else {
+		s.releasePublication(ctx, publication.ID, entity.StatusSent)
+	}
+
+	s.log.Info("Sent publication for publicationID: %d, channel_id: %d, msg_ids: %v",
+		publication.ID, publication.TelegramChannelID, msgIDs)
+}
+
+// deletePartial deletes part of publication which was sent before error, retry sends publication again from start
+func (s *schedule) deletePartial(chatID int64, sentIDs []int) {
+	for _, msgID := range sentIDs {
+		if err := s.tgMsg.DeleteMessage(chatID, msgID); err != nil {
+			s.log.Error("Failed to delete partially sent message from channel - %d, sentMsgID -%d, err - %v", chatID, msgID, err)
+		}
+	}
+}
+
+// sendTarget sends publication to its additional channel and schedules deletion there
+func (s *schedule) sendTarget(ctx context.Context, job entity.Job, publication *entity.Publication) {
+	target, err := s.targetService.GetByID(ctx, *job.TargetID)
+	if err != nil {
+		s.log.Error("Failed to get target - %d, err - %v", *job.TargetID, err)
+		// канал удален из кросс-постинга, отправлять некуда
+		s.retryOrFailWith(ctx, job, entity.StatusErrorOnSending, err, !errors.Is(err, customErr.ErrNoRows), 0)
+		return
+	}
+
+	if target.Status == entity.StatusSent {
+		if err := s.jobService.Complete(ctx, job.ID); err != nil {
+			s.log.Error("Failed to complete job: %s, err - %v", job, err)
 		}
+		return
+	}
+
+	if s.inBlackout(ctx, job, target.ChannelID, target.ChannelName) {
+		return
+	}
+
+	sentIDs, err := s.tgMsg.SendMessageToUser(target.TelegramChannelID, publication)
+	if err != nil {
+		s.log.Error("Failed to send message to channel - %d, err - %v", target.ChannelID, err)
+		s.deletePartial(target.TelegramChannelID, sentIDs)
+		s.retryOrFail(ctx, job, entity.StatusErrorOnSending, err)
+		return
+	}
+	msgIDs := messageIDs(sentIDs)
+
+	if err := s.jobService.Complete(ctx, job.ID); err != nil {
+		s.log.Error("Failed to complete job: %s, err - %v", job, err)
+	}
+
+	sentAt := time.Now()
+	if err := s.targetService.MarkSent(ctx, target.ID, msgIDs, sentAt); err != nil {
+		s.log.Error("Failed to update target - %d, err - %v", target.ID, err)
+	}
+	s.log.Info("Sent publication for publicationID: %d to target: %d, channel_id: %d, msg_ids: %v",
+		publication.ID, target.ID, target.TelegramChannelID, msgIDs)
+
+	if deleteAt, ok := target.DeleteAt(*publication, sentAt); ok {
+		if err := s.jobService.ScheduleTargetDelete(ctx, publication.ID, target.ID, deleteAt,
+			target.TelegramChannelID, msgIDs); err != nil {
+			s.log.Error("Failed to schedule delete of target - %d, err - %v", target.ID, err)
+		}
+		return
+	}
+	s.releaseTarget(ctx, publication.ID)
+}
+
+// inBlackout holds publication if its channel entered blackout after publication was scheduled,
+// administrators decide whether to send it anyway, reschedule or drop it
+func (s *schedule) inBlackout(ctx context.Context, job entity.Job, channelID int, channelName string) bool {
+	if job.IgnoreBlackout {
+		return false
+	}
+
+	blackout, err := s.windowService.ActiveBlackout(ctx, channelID, time.Now())
+	if err != nil {
+		s.log.Error("Failed to get blackout of channel - %d, err - %v", channelID, err)
+		return false
+	}
+	if blackout == nil {
+		return false
+	}
+
+	if err := s.jobService.Hold(ctx, job.ID, entity.HoldBlackout); err != nil {
+		s.log.Error("Failed to hold job: %s, err - %v", job, err)
+		return true
+	}
+	s.log.Info("Job is held because of blackout of channel %s until %v: %s", channelName, blackout.EndsAt, job)
+
+	s.notifyAdmins(ctx, func(loc *time.Location) string {
+		return fmt.Sprintf("Публикация #%d в канале %s не отправлена: в канале период тишины %s.\n\nЧто сделать с публикацией?",
+			job.PublicationID, channelName, blackout.Text(loc))
+	}, markup.OverduePublication(job))
+	return true
+}
+
+// sentOccurrence records sent occurrence of series, schedules its deletion and next occurrence
+func (s *schedule) sentOccurrence(ctx context.Context, job entity.Job, publication *entity.Publication, msgIDs []int64) {
+	occurrenceID, err := s.seriesService.CreateOccurrence(ctx, &entity.Occurrence{
+		PublicationID: publication.ID,
+		RunAt:         job.RunAt,
+		Status:        entity.OccurrenceSent,
+		MessageIDs:    msgIDs,
+	})
+	if err != nil {
+		s.log.Error("Failed to create occurrence of publication - %d, err - %v", publication.ID, err)
+	}
+
+	if deleteAt, ok := publication.DeleteAt(time.Now()); ok && err == nil {
+		if err := s.jobService.ScheduleOccurrenceDelete(ctx, publication.ID, occurrenceID, deleteAt,
+			publication.TelegramChannelID, msgIDs); err != nil {
+			s.log.Error("Failed to schedule delete of occurrence - %d, err - %v", occurrenceID, err)
+		}
+	}
+
+	s.log.Info("Sent occurrence of publicationID: %d, channel_id: %d, msg_ids: %v",
+		publication.ID, publication.TelegramChannelID, msgIDs)
+	s.nextOccurrence(ctx, job, publication)
+}
+
+// nextOccurrence moves publish job of series to next occurrence, job of finished series is completed
+func (s *schedule) nextOccurrence(ctx context.Context, job entity.Job, publication *entity.Publication) {
+	ok, err := s.seriesService.ScheduleNext(ctx, publication, job.RunAt)
+	if err != nil {
+		// задача остается заблокированной и не должна быть выполнена повторно после истечения аренды
+		s.log.Error("Failed to schedule next occurrence of publication - %d, err - %v", publication.ID, err)
+		if err := s.jobService.Fail(ctx, job, err); err != nil {
+			s.log.Error("Failed to mark job failed: %s, err - %v", job, err)
+		}
+		return
+	}
+	if ok {
+		return
+	}
+
+	s.log.Info("Series of publicationID %d is finished", publication.ID)
+	if err := s.jobService.Complete(ctx, job.ID); err != nil {
+		s.log.Error("Failed to complete job: %s, err - %v", job, err)
+	}
+	if err := s.publicationService.UpdatePublicationStatus(ctx, publication.ID, entity.StatusSent); err != nil {
+		s.log.Error("Failed to update publication, publicationID - %d, status - %v, err - %v", publication.ID, entity.StatusSent, err)
 	}
 }
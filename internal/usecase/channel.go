@@ -8,6 +8,7 @@ import (
 	"github.com/Enthreeka/tg-posting-bot/internal/repo"
 	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
 	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/button"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -17,13 +18,30 @@ type ChannelService interface {
 
 	GetByID(ctx context.Context, id int) (*entity.Channel, error)
 	GetAll(ctx context.Context) ([]entity.Channel, error)
-	GetAllAdminChannel(ctx context.Context) (*tgbotapi.InlineKeyboardMarkup, error)
+	// GetAllAdminChannel returns page of channels where bot is administrator
+	GetAllAdminChannel(ctx context.Context, page int) (*tgbotapi.InlineKeyboardMarkup, error)
 	GetByChannelName(ctx context.Context, channelName string) (*entity.Channel, error)
 
 	DeleteByID(ctx context.Context, id int) error
 	ChatMember(ctx context.Context, channel *entity.Channel) error
+
+	UpdateCatchUpPolicy(ctx context.Context, id int, policy entity.CatchUpPolicy) error
+	UpdateMaxLateness(ctx context.Context, id int, minutes int) error
+	UpdateMaxSendAttempts(ctx context.Context, id int, attempts int) error
+	UpdateTimezone(ctx context.Context, id int, timezone string) error
+
+	CreateGroup(ctx context.Context, name string) (int, error)
+	GetGroup(ctx context.Context, id int) (*entity.ChannelGroup, error)
+	GetGroups(ctx context.Context) ([]entity.ChannelGroup, error)
+	DeleteGroup(ctx context.Context, id int) error
+	// ToggleGroupMember adds channel to group or removes it, returns true if channel was added
+	ToggleGroupMember(ctx context.Context, groupID int, channelID int) (bool, error)
+	GroupsMarkup(groups []entity.ChannelGroup) *tgbotapi.InlineKeyboardMarkup
+	GroupMarkup(ctx context.Context, group *entity.ChannelGroup) (*tgbotapi.InlineKeyboardMarkup, error)
 }
 
+var ErrGroupChannelNotAdmin = errors.New("bot is not administrator of channel")
+
 type channelService struct {
 	channelRepo repo.ChannelRepo
 	log         *logger.Logger
@@ -82,43 +100,163 @@ func (c *channelService) ChatMember(ctx context.Context, channel *entity.Channel
 		c.log.Error("channelRepo.UpdateStatusByTgID: failed to update channel status: %v", err)
 		return err
 	}
+
+	// публиковать в канал, где бот больше не администратор, невозможно - канал выводится из всех групп
+	if channel.ChannelStatus != entity.StatusAdministrator {
+		if err = c.channelRepo.DeleteFromGroupsByTgID(ctx, channel.TgID); err != nil {
+			c.log.Error("channelRepo.DeleteFromGroupsByTgID: failed to delete channel from groups: %v", err)
+			return err
+		}
+	}
 	return nil
 }
 
-func (c *channelService) GetAllAdminChannel(ctx context.Context) (*tgbotapi.InlineKeyboardMarkup, error) {
+func (c *channelService) GetAllAdminChannel(ctx context.Context, page int) (*tgbotapi.InlineKeyboardMarkup, error) {
 	channel, err := c.channelRepo.GetAllAdminChannel(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.createChannelMarkup(channel, "get")
+	return c.createChannelMarkup(channel, "get", page)
 }
 
-func (c *channelService) createChannelMarkup(channel []entity.Channel, command string) (*tgbotapi.InlineKeyboardMarkup, error) {
-	var rows [][]tgbotapi.InlineKeyboardButton
-	var row []tgbotapi.InlineKeyboardButton
-
-	buttonsPerRow := 1
-	for i, el := range channel {
-		if el.TgID != 0 { // check for channel for global notification
-			btn := tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s", el.ChannelName),
-				fmt.Sprintf("channel_%s_%d", command, el.ID))
+func (c *channelService) createChannelMarkup(channel []entity.Channel, command string, page int) (*tgbotapi.InlineKeyboardMarkup, error) {
+	// канал для глобальных уведомлений не выводится
+	channels := make([]entity.Channel, 0, len(channel))
+	for _, el := range channel {
+		if el.TgID != 0 {
+			channels = append(channels, el)
+		}
+	}
 
-			row = append(row, btn)
+	pager := markup.Paginator{
+		Page:  page,
+		Total: len(channels),
+		Data:  func(page int) string { return fmt.Sprintf("show_channels_%d", page) },
+	}
+	from, to := pager.Bounds()
 
-			if (i+1)%buttonsPerRow == 0 || i == len(channel)-1 {
-				rows = append(rows, row)
-				row = []tgbotapi.InlineKeyboardButton{}
-			}
-		}
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, to-from+3)
+	for _, el := range channels[from:to] {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(el.ChannelName,
+			fmt.Sprintf("channel_%s_%d", command, el.ID))))
+	}
+	if row := pager.Row(); row != nil {
+		rows = append(rows, row)
 	}
 
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Группы каналов", "group_list")))
 	rows = append(rows, []tgbotapi.InlineKeyboardButton{button.MainMenuButton})
-	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	channelMarkup := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
-	return &markup, nil
+	return &channelMarkup, nil
 }
 
 func (c *channelService) GetByChannelName(ctx context.Context, channelName string) (*entity.Channel, error) {
 	return c.channelRepo.GetByChannelName(ctx, channelName)
 }
+
+func (c *channelService) UpdateCatchUpPolicy(ctx context.Context, id int, policy entity.CatchUpPolicy) error {
+	return c.channelRepo.UpdateCatchUpPolicy(ctx, id, policy)
+}
+
+func (c *channelService) UpdateMaxLateness(ctx context.Context, id int, minutes int) error {
+	return c.channelRepo.UpdateMaxLateness(ctx, id, minutes)
+}
+
+func (c *channelService) UpdateMaxSendAttempts(ctx context.Context, id int, attempts int) error {
+	return c.channelRepo.UpdateMaxSendAttempts(ctx, id, attempts)
+}
+
+func (c *channelService) UpdateTimezone(ctx context.Context, id int, timezone string) error {
+	return c.channelRepo.UpdateTimezone(ctx, id, timezone)
+}
+
+func (c *channelService) CreateGroup(ctx context.Context, name string) (int, error) {
+	return c.channelRepo.CreateGroup(ctx, name)
+}
+
+func (c *channelService) GetGroup(ctx context.Context, id int) (*entity.ChannelGroup, error) {
+	return c.channelRepo.GetGroupByID(ctx, id)
+}
+
+func (c *channelService) GetGroups(ctx context.Context) ([]entity.ChannelGroup, error) {
+	return c.channelRepo.GetAllGroups(ctx)
+}
+
+func (c *channelService) DeleteGroup(ctx context.Context, id int) error {
+	return c.channelRepo.DeleteGroupByID(ctx, id)
+}
+
+func (c *channelService) ToggleGroupMember(ctx context.Context, groupID int, channelID int) (bool, error) {
+	group, err := c.channelRepo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return false, err
+	}
+	for _, channel := range group.Channels {
+		if channel.ID == channelID {
+			return false, c.channelRepo.DeleteGroupMember(ctx, groupID, channelID)
+		}
+	}
+
+	channel, err := c.channelRepo.GetByID(ctx, channelID)
+	if err != nil {
+		return false, err
+	}
+	if channel.ChannelStatus != entity.StatusAdministrator {
+		return false, ErrGroupChannelNotAdmin
+	}
+	return true, c.channelRepo.AddGroupMember(ctx, groupID, channelID)
+}
+
+func (c *channelService) GroupsMarkup(groups []entity.ChannelGroup) *tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(groups)+2)
+	for _, group := range groups {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(group.Name, fmt.Sprintf("group_get_%d", group.ID))))
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Создать группу", "group_create")),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Вернуться назад", "show_channels")))
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return &markup
+}
+
+func (c *channelService) GroupMarkup(ctx context.Context, group *entity.ChannelGroup) (*tgbotapi.InlineKeyboardMarkup, error) {
+	channels, err := c.channelRepo.GetAllAdminChannel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[int]bool, len(group.Channels))
+	for _, channel := range group.Channels {
+		members[channel.ID] = true
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, channel := range channels {
+		if channel.TgID == 0 { // check for channel for global notification
+			continue
+		}
+		mark := "⬜ "
+		if members[channel.ID] {
+			mark = "✅ "
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(mark+channel.ChannelName, fmt.Sprintf("group_member_%d_%d", group.ID, channel.ID))))
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Рассылка по группе", fmt.Sprintf("group_broadcast_%d", group.ID))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Перенести публикации группы", fmt.Sprintf("group_reschedule_%d", group.ID))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Удалить группу", fmt.Sprintf("group_delete_%d", group.ID))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Вернуться назад", "group_list")))
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return &markup, nil
+}
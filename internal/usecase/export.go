@@ -0,0 +1,278 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/repo"
+	"github.com/Enthreeka/tg-posting-bot/pkg/ical"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// eventDuration - длительность события публикации в календаре
+const eventDuration = 15 * time.Minute
+
+// ExportService builds files with publications for spreadsheets, repeated import and calendars
+type ExportService interface {
+	// Preview returns title of exported channels and number of publications matching filter
+	Preview(ctx context.Context, filter entity.ExportFilter, loc *time.Location) (string, int, error)
+	// Export returns file name and content, dates are written in location loc
+	Export(ctx context.Context, filter entity.ExportFilter, format entity.ExportFormat, loc *time.Location) (string, []byte, error)
+}
+
+type exportService struct {
+	publicationRepo repo.PublicationRepo
+	channelRepo     repo.ChannelRepo
+	log             *logger.Logger
+}
+
+func NewExportService(publicationRepo repo.PublicationRepo, channelRepo repo.ChannelRepo, log *logger.Logger) (ExportService, error) {
+	if log == nil {
+		return nil, errors.New("log is nil")
+	}
+	if publicationRepo == nil {
+		return nil, errors.New("publicationRepo is nil")
+	}
+	if channelRepo == nil {
+		return nil, errors.New("channelRepo is nil")
+	}
+
+	return &exportService{
+		publicationRepo: publicationRepo,
+		channelRepo:     channelRepo,
+		log:             log,
+	}, nil
+}
+
+func (e *exportService) Preview(ctx context.Context, filter entity.ExportFilter, loc *time.Location) (string, int, error) {
+	title := "все каналы"
+	if filter.ChannelID != 0 {
+		channel, err := e.channelRepo.GetByID(ctx, filter.ChannelID)
+		if err != nil {
+			return "", 0, err
+		}
+		title = channel.ChannelName
+	}
+
+	publications, err := e.publications(ctx, filter, loc)
+	return title, len(publications), err
+}
+
+func (e *exportService) Export(ctx context.Context, filter entity.ExportFilter, format entity.ExportFormat, loc *time.Location) (string, []byte, error) {
+	publications, err := e.publications(ctx, filter, loc)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var data []byte
+	switch format {
+	case entity.ExportJSON:
+		data, err = exportJSON(publications, loc)
+	case entity.ExportICS:
+		data = exportICS(publications, loc)
+	default:
+		data, err = exportCSV(publications, loc)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	fileName := fmt.Sprintf("publications_%s.%s", time.Now().In(loc).Format("2006-01-02_15-04"), format.Extension())
+	e.log.Info("exported %d publications to %s", len(publications), fileName)
+	return fileName, data, nil
+}
+
+func (e *exportService) publications(ctx context.Context, filter entity.ExportFilter, loc *time.Location) ([]entity.Publication, error) {
+	from, to := filter.Period(loc)
+	return e.publicationRepo.GetForExport(ctx, filter.ChannelID, from, to, filter.PublicationStatus())
+}
+
+// exportDate - дата в формате импорта, пустая строка для незаданной даты
+func exportDate(t *time.Time, loc *time.Location) string {
+	if t == nil {
+		return ""
+	}
+	return t.In(loc).Format(entity.DateLayout)
+}
+
+func exportDeleteDate(publication entity.Publication, loc *time.Location) string {
+	deleteAt, ok := publication.PlannedDeleteDate()
+	if !ok {
+		return ""
+	}
+	return exportDate(&deleteAt, loc)
+}
+
+// csvColumns - колонки импорта, затем справочные колонки, которые импорт пропускает
+var csvColumns = []string{"канал", "текст", "вложения", "дата_публикации", "дата_удаления", "кнопки",
+	"id", "статус", "отправлена", "ссылки"}
+
+func exportCSV(publications []entity.Publication, loc *time.Location) ([]byte, error) {
+	var buf bytes.Buffer
+	// BOM нужен табличным редакторам, чтобы открыть файл в utf-8
+	buf.WriteString("\xef\xbb\xbf")
+	writer := csv.NewWriter(&buf)
+	writer.Comma = ';'
+
+	if err := writer.Write(csvColumns); err != nil {
+		return nil, err
+	}
+	for _, publication := range publications {
+		media := make([]string, 0, len(publication.Media))
+		for _, m := range publication.Media {
+			media = append(media, fmt.Sprintf("%s:%s", m.Type, m.FileID))
+		}
+
+		record := []string{
+			publication.ChannelRef(),
+			publication.Text,
+			strings.Join(media, " "),
+			exportDate(publication.PublicationDate, loc),
+			exportDeleteDate(publication, loc),
+			publication.Buttons.InputText(),
+			strconv.Itoa(publication.ID),
+			publication.PublicationStatus.Title(),
+			exportDate(publication.SentAt, loc),
+			strings.Join(publication.MessageLinks(), " "),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+type exportMedia struct {
+	Type   entity.MediaType `json:"тип"`
+	FileID string           `json:"file_id"`
+}
+
+type exportButton struct {
+	Text string `json:"текст"`
+	URL  string `json:"ссылка"`
+}
+
+// exportPublication - публикация в формате импорта, справочные поля импорт пропускает
+type exportPublication struct {
+	Channel         string           `json:"канал"`
+	Text            string           `json:"текст"`
+	Media           []exportMedia    `json:"вложения,omitempty"`
+	PublicationDate string           `json:"дата_публикации"`
+	DeleteDate      string           `json:"дата_удаления,omitempty"`
+	Buttons         [][]exportButton `json:"кнопки,omitempty"`
+
+	ID     int      `json:"id"`
+	Status string   `json:"статус"`
+	SentAt string   `json:"отправлена,omitempty"`
+	Links  []string `json:"ссылки,omitempty"`
+}
+
+func exportJSON(publications []entity.Publication, loc *time.Location) ([]byte, error) {
+	items := make([]exportPublication, 0, len(publications))
+	for _, publication := range publications {
+		item := exportPublication{
+			Channel:         publication.ChannelRef(),
+			Text:            publication.Text,
+			PublicationDate: exportDate(publication.PublicationDate, loc),
+			DeleteDate:      exportDeleteDate(publication, loc),
+			ID:              publication.ID,
+			Status:          publication.PublicationStatus.Title(),
+			SentAt:          exportDate(publication.SentAt, loc),
+			Links:           publication.MessageLinks(),
+		}
+		for _, m := range publication.Media {
+			item.Media = append(item.Media, exportMedia{Type: m.Type, FileID: m.FileID})
+		}
+		for _, row := range publication.Buttons {
+			buttons := make([]exportButton, 0, len(row))
+			for _, button := range row {
+				buttons = append(buttons, exportButton{Text: button.Text, URL: button.URL})
+			}
+			item.Buttons = append(item.Buttons, buttons)
+		}
+		items = append(items, item)
+	}
+
+	return json.MarshalIndent(items, "", "  ")
+}
+
+func exportICS(publications []entity.Publication, loc *time.Location) []byte {
+	calendar := ical.Calendar{
+		ProdID: "-//tg-posting-bot//export//RU",
+		Name:   "Публикации",
+	}
+
+	for _, publication := range publications {
+		start := publication.PublicationDate
+		if publication.SentAt != nil {
+			start = publication.SentAt
+		}
+		if start == nil {
+			continue
+		}
+
+		description := []string{"Статус: " + publication.PublicationStatus.Title()}
+		if deleteDate := exportDeleteDate(publication, loc); deleteDate != "" {
+			description = append(description, "Удаление: "+deleteDate)
+		}
+		if publication.IsSeries() {
+			description = append(description, "Повторяется: "+*publication.Recurrence)
+		}
+		if publication.Text != "" {
+			description = append(description, "", publication.Text)
+		}
+		if len(publication.Buttons) > 0 {
+			description = append(description, "", "Кнопки:", publication.Buttons.InputText())
+		}
+
+		event := ical.Event{
+			UID:         fmt.Sprintf("publication-%d@tg-posting-bot", publication.ID),
+			Start:       *start,
+			End:         start.Add(eventDuration),
+			Summary:     eventSummary(publication),
+			Description: strings.Join(description, "\n"),
+			Status:      eventStatus(publication.PublicationStatus),
+		}
+		if links := publication.MessageLinks(); len(links) > 0 {
+			event.URL = links[0]
+		}
+		calendar.Events = append(calendar.Events, event)
+	}
+
+	return calendar.Bytes(time.Now())
+}
+
+// eventSummary - название канала и начало первой строки текста
+func eventSummary(publication entity.Publication) string {
+	const maxRunes = 60
+
+	line, _, _ := strings.Cut(strings.TrimSpace(publication.Text), "\n")
+	if line == "" {
+		return fmt.Sprintf("%s: публикация #%d", publication.ChannelName, publication.ID)
+	}
+	if utf8.RuneCountInString(line) > maxRunes {
+		line = string([]rune(line)[:maxRunes]) + "…"
+	}
+	return publication.ChannelName + ": " + line
+}
+
+func eventStatus(status entity.PublicationStatus) string {
+	switch status {
+	case entity.StatusAwaits:
+		return ical.StatusTentative
+	case entity.StatusErrorOnSending:
+		return ical.StatusCancelled
+	default:
+		return ical.StatusConfirmed
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/repo"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	"time"
+)
+
+var ErrGroupEmpty = errors.New("channel group is empty")
+
+// GroupService runs bulk operations over channels of group
+type GroupService interface {
+	// Broadcast creates publication in first channel of group with other channels as targets and sends it right now
+	Broadcast(ctx context.Context, groupID int, text string, entities []entity.MessageEntity) (int, error)
+	// Reschedule shifts awaiting publications of group channels, returns number of moved publications
+	Reschedule(ctx context.Context, groupID int, shift time.Duration) (int, error)
+}
+
+type groupService struct {
+	channelRepo     repo.ChannelRepo
+	publicationRepo repo.PublicationRepo
+	targetService   TargetService
+	jobService      JobService
+	log             *logger.Logger
+}
+
+func NewGroupService(channelRepo repo.ChannelRepo,
+	publicationRepo repo.PublicationRepo,
+	targetService TargetService,
+	jobService JobService,
+	log *logger.Logger) (GroupService, error) {
+	if log == nil {
+		return nil, errors.New("log is nil")
+	}
+	if channelRepo == nil {
+		return nil, errors.New("channelRepo is nil")
+	}
+	if publicationRepo == nil {
+		return nil, errors.New("publicationRepo is nil")
+	}
+	if targetService == nil {
+		return nil, errors.New("targetService is nil")
+	}
+	if jobService == nil {
+		return nil, errors.New("jobService is nil")
+	}
+
+	return &groupService{
+		channelRepo:     channelRepo,
+		publicationRepo: publicationRepo,
+		targetService:   targetService,
+		jobService:      jobService,
+		log:             log,
+	}, nil
+}
+
+func (g *groupService) Broadcast(ctx context.Context, groupID int, text string, entities []entity.MessageEntity) (int, error) {
+	group, err := g.channelRepo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return 0, err
+	}
+	if len(group.Channels) == 0 {
+		return 0, ErrGroupEmpty
+	}
+
+	now := time.Now()
+	publicationID, err := g.publicationRepo.CreatePublication(ctx, &entity.Publication{
+		ChannelID:       int64(group.Channels[0].ID),
+		Text:            text,
+		Entities:        entities,
+		PublicationDate: &now,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// задачи дополнительных каналов создаются вместе с задачей основного
+	if _, err = g.targetService.AddGroup(ctx, publicationID, groupID); err != nil {
+		return publicationID, err
+	}
+	return publicationID, g.jobService.SchedulePublish(ctx, publicationID, now)
+}
+
+func (g *groupService) Reschedule(ctx context.Context, groupID int, shift time.Duration) (int, error) {
+	group, err := g.channelRepo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return 0, err
+	}
+	if len(group.Channels) == 0 {
+		return 0, ErrGroupEmpty
+	}
+
+	now := time.Now()
+	publications, err := g.publicationRepo.GetAwaiting(ctx, group.ChannelIDs(), now)
+	if err != nil {
+		return 0, err
+	}
+
+	var moved int
+	for _, publication := range publications {
+		date := publication.PublicationDate.Add(shift)
+		// перенос назад не должен отправлять публикации в прошлое
+		if date.Before(now) {
+			g.log.Info("publication %d is not rescheduled to past: %v", publication.ID, date)
+			continue
+		}
+
+		if err = g.publicationRepo.UpdatePublicationDate(ctx, publication.ID, date); err != nil {
+			return moved, err
+		}
+		if err = g.jobService.SchedulePublish(ctx, publication.ID, date); err != nil {
+			return moved, err
+		}
+		moved++
+	}
+	return moved, nil
+}
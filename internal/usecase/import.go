@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/repo"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+)
+
+// ImportService keeps validated publications from file until admin confirms import
+type ImportService interface {
+	Prepare(ctx context.Context, userID int64, publications []entity.Publication) (int, error)
+	// Commit creates all publications of import in one transaction, returns number of created publications
+	Commit(ctx context.Context, id int, userID int64) (int, error)
+	Cancel(ctx context.Context, id int, userID int64) error
+}
+
+type importService struct {
+	importRepo repo.ImportRepo
+	jobService JobService
+	log        *logger.Logger
+}
+
+func NewImportService(importRepo repo.ImportRepo, jobService JobService, log *logger.Logger) (ImportService, error) {
+	if log == nil {
+		return nil, errors.New("log is nil")
+	}
+	if importRepo == nil {
+		return nil, errors.New("importRepo is nil")
+	}
+	if jobService == nil {
+		return nil, errors.New("jobService is nil")
+	}
+
+	return &importService{
+		importRepo: importRepo,
+		jobService: jobService,
+		log:        log,
+	}, nil
+}
+
+func (i *importService) Prepare(ctx context.Context, userID int64, publications []entity.Publication) (int, error) {
+	return i.importRepo.Create(ctx, userID, publications)
+}
+
+func (i *importService) Commit(ctx context.Context, id int, userID int64) (int, error) {
+	count, err := i.importRepo.Commit(ctx, id, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	i.log.Info("import %d committed: %d publications", id, count)
+	i.jobService.Notify(entity.JobPublish)
+	return count, nil
+}
+
+func (i *importService) Cancel(ctx context.Context, id int, userID int64) error {
+	return i.importRepo.Delete(ctx, id, userID)
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/repo"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	"time"
+)
+
+type JobService interface {
+	// SchedulePublish schedules sending of publication to its channel and to all not sent targets
+	SchedulePublish(ctx context.Context, publicationID int, runAt time.Time) error
+	ScheduleTargets(ctx context.Context, publicationID int, runAt time.Time) error
+	ScheduleDelete(ctx context.Context, publicationID int, runAt time.Time, chatID int64, messageIDs []int64) error
+	ScheduleOccurrenceDelete(ctx context.Context, publicationID int, occurrenceID int, runAt time.Time, chatID int64, messageIDs []int64) error
+	ScheduleTargetDelete(ctx context.Context, publicationID int, targetID int, runAt time.Time, chatID int64, messageIDs []int64) error
+
+	Claim(ctx context.Context, kind entity.JobKind, lease time.Duration, limit int) ([]entity.Job, error)
+	NextRunAt(ctx context.Context, kind entity.JobKind) (*time.Time, error)
+	GetOverdue(ctx context.Context, before time.Time) ([]entity.Job, error)
+	GetByID(ctx context.Context, jobID int) (*entity.Job, error)
+	GetByPublicationID(ctx context.Context, publicationID int, kind entity.JobKind) (*entity.Job, error)
+	GetAttempts(ctx context.Context, publicationID int) ([]entity.JobAttempt, error)
+	// HasActive checks that something is still to be sent or deleted for publication
+	HasActive(ctx context.Context, publicationID int) (bool, error)
+
+	Complete(ctx context.Context, jobID int) error
+	Fail(ctx context.Context, job entity.Job, err error) error
+	Retry(ctx context.Context, job entity.Job, runAt time.Time, err error) error
+	// Hold, Release, Force, Cancel change only this job: held job of one target doesn't affect other channels.
+	// Release, Force and Cancel apply only to held job
+	Hold(ctx context.Context, jobID int, reason entity.HoldReason) error
+	Release(ctx context.Context, jobID int, kind entity.JobKind, runAt time.Time) error
+	// Force releases job for execution right now even if channel is in blackout
+	Force(ctx context.Context, jobID int, kind entity.JobKind) error
+	Cancel(ctx context.Context, jobID int, kind entity.JobKind) error
+	// HoldPublication, CancelPublication - pending and held jobs of publication in its channel and targets,
+	// e.g. for pause and end of series
+	HoldPublication(ctx context.Context, publicationID int, kind entity.JobKind) error
+	CancelPublication(ctx context.Context, publicationID int, kind entity.JobKind) error
+
+	// Wake signals scheduler of this process that jobs of kind were changed
+	Wake(kind entity.JobKind) <-chan struct{}
+	// Notify wakes scheduler after jobs were created bypassing service, e.g. in transaction of import
+	Notify(kind entity.JobKind)
+}
+
+type jobService struct {
+	jobRepo repo.JobRepo
+	log     *logger.Logger
+
+	wake map[entity.JobKind]chan struct{}
+}
+
+func NewJobService(jobRepo repo.JobRepo, log *logger.Logger) (JobService, error) {
+	if log == nil {
+		return nil, errors.New("log is nil")
+	}
+	if jobRepo == nil {
+		return nil, errors.New("jobRepo is nil")
+	}
+
+	return &jobService{
+		jobRepo: jobRepo,
+		log:     log,
+		wake: map[entity.JobKind]chan struct{}{
+			entity.JobPublish: make(chan struct{}, 1),
+			entity.JobDelete:  make(chan struct{}, 1),
+		},
+	}, nil
+}
+
+func (j *jobService) notify(kind entity.JobKind) {
+	select {
+	case j.wake[kind] <- struct{}{}:
+	default:
+	}
+}
+
+func (j *jobService) Notify(kind entity.JobKind) {
+	j.notify(kind)
+}
+
+func (j *jobService) Wake(kind entity.JobKind) <-chan struct{} {
+	return j.wake[kind]
+}
+
+func (j *jobService) SchedulePublish(ctx context.Context, publicationID int, runAt time.Time) error {
+	err := j.jobRepo.Upsert(ctx, &entity.Job{
+		PublicationID: publicationID,
+		Kind:          entity.JobPublish,
+		RunAt:         runAt,
+	})
+	if err != nil {
+		return err
+	}
+	return j.ScheduleTargets(ctx, publicationID, runAt)
+}
+
+func (j *jobService) ScheduleTargets(ctx context.Context, publicationID int, runAt time.Time) error {
+	err := j.jobRepo.UpsertTargets(ctx, publicationID, runAt)
+	if err == nil {
+		j.notify(entity.JobPublish)
+	}
+	return err
+}
+
+func (j *jobService) ScheduleDelete(ctx context.Context, publicationID int, runAt time.Time, chatID int64, messageIDs []int64) error {
+	err := j.jobRepo.Upsert(ctx, &entity.Job{
+		PublicationID: publicationID,
+		Kind:          entity.JobDelete,
+		RunAt:         runAt,
+		ChatID:        &chatID,
+		MessageIDs:    messageIDs,
+	})
+	if err == nil {
+		j.notify(entity.JobDelete)
+	}
+	return err
+}
+
+func (j *jobService) ScheduleOccurrenceDelete(ctx context.Context, publicationID int, occurrenceID int, runAt time.Time, chatID int64, messageIDs []int64) error {
+	err := j.jobRepo.Upsert(ctx, &entity.Job{
+		PublicationID: publicationID,
+		Kind:          entity.JobDelete,
+		RunAt:         runAt,
+		ChatID:        &chatID,
+		MessageIDs:    messageIDs,
+		OccurrenceID:  &occurrenceID,
+	})
+	if err == nil {
+		j.notify(entity.JobDelete)
+	}
+	return err
+}
+
+func (j *jobService) ScheduleTargetDelete(ctx context.Context, publicationID int, targetID int, runAt time.Time, chatID int64, messageIDs []int64) error {
+	err := j.jobRepo.Upsert(ctx, &entity.Job{
+		PublicationID: publicationID,
+		Kind:          entity.JobDelete,
+		RunAt:         runAt,
+		ChatID:        &chatID,
+		MessageIDs:    messageIDs,
+		TargetID:      &targetID,
+	})
+	if err == nil {
+		j.notify(entity.JobDelete)
+	}
+	return err
+}
+
+func (j *jobService) Claim(ctx context.Context, kind entity.JobKind, lease time.Duration, limit int) ([]entity.Job, error) {
+	return j.jobRepo.Claim(ctx, kind, lease, limit)
+}
+
+func (j *jobService) NextRunAt(ctx context.Context, kind entity.JobKind) (*time.Time, error) {
+	return j.jobRepo.NextRunAt(ctx, kind)
+}
+
+func (j *jobService) GetOverdue(ctx context.Context, before time.Time) ([]entity.Job, error) {
+	return j.jobRepo.GetOverdue(ctx, before)
+}
+
+func (j *jobService) GetByID(ctx context.Context, jobID int) (*entity.Job, error) {
+	return j.jobRepo.GetByID(ctx, jobID)
+}
+
+func (j *jobService) GetByPublicationID(ctx context.Context, publicationID int, kind entity.JobKind) (*entity.Job, error) {
+	return j.jobRepo.GetByPublicationID(ctx, publicationID, kind)
+}
+
+func (j *jobService) HasActive(ctx context.Context, publicationID int) (bool, error) {
+	return j.jobRepo.HasActive(ctx, publicationID)
+}
+
+func (j *jobService) Complete(ctx context.Context, jobID int) error {
+	return j.jobRepo.Complete(ctx, jobID)
+}
+
+// Fail marks job as permanently failed, error is kept in attempts history
+func (j *jobService) Fail(ctx context.Context, job entity.Job, err error) error {
+	j.addAttempt(ctx, job, err)
+	return j.jobRepo.Fail(ctx, job.ID, err.Error())
+}
+
+// Retry reschedules failed job for next attempt, error is kept in attempts history
+func (j *jobService) Retry(ctx context.Context, job entity.Job, runAt time.Time, err error) error {
+	j.addAttempt(ctx, job, err)
+	if err := j.jobRepo.Retry(ctx, job.ID, runAt, err.Error()); err != nil {
+		return err
+	}
+	j.notify(job.Kind)
+	return nil
+}
+
+func (j *jobService) addAttempt(ctx context.Context, job entity.Job, err error) {
+	if addErr := j.jobRepo.AddAttempt(ctx, &entity.JobAttempt{
+		PublicationID: job.PublicationID,
+		JobID:         &job.ID,
+		TargetID:      job.TargetID,
+		Kind:          job.Kind,
+		Attempt:       job.Attempts,
+		Error:         err.Error(),
+	}); addErr != nil {
+		j.log.Error("failed to save attempt of job %s: %v", job, addErr)
+	}
+}
+
+// attemptsShown - сколько последних ошибок показывать администратору
+const attemptsShown = 5
+
+func (j *jobService) GetAttempts(ctx context.Context, publicationID int) ([]entity.JobAttempt, error) {
+	return j.jobRepo.GetAttempts(ctx, publicationID, attemptsShown)
+}
+
+func (j *jobService) Hold(ctx context.Context, jobID int, reason entity.HoldReason) error {
+	return j.jobRepo.Hold(ctx, jobID, reason)
+}
+
+func (j *jobService) Release(ctx context.Context, jobID int, kind entity.JobKind, runAt time.Time) error {
+	err := j.jobRepo.Release(ctx, jobID, runAt)
+	if err == nil {
+		j.notify(kind)
+	}
+	return err
+}
+
+func (j *jobService) Force(ctx context.Context, jobID int, kind entity.JobKind) error {
+	err := j.jobRepo.Force(ctx, jobID, time.Now())
+	if err == nil {
+		j.notify(kind)
+	}
+	return err
+}
+
+func (j *jobService) Cancel(ctx context.Context, jobID int, kind entity.JobKind) error {
+	err := j.jobRepo.Delete(ctx, jobID)
+	if err == nil {
+		j.notify(kind)
+	}
+	return err
+}
+
+func (j *jobService) HoldPublication(ctx context.Context, publicationID int, kind entity.JobKind) error {
+	return j.jobRepo.HoldPublication(ctx, publicationID, kind)
+}
+
+func (j *jobService) CancelPublication(ctx context.Context, publicationID int, kind entity.JobKind) error {
+	err := j.jobRepo.DeletePublication(ctx, publicationID, kind)
+	if err == nil {
+		j.notify(kind)
+	}
+	return err
+}
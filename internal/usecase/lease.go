@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/repo"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	"time"
+)
+
+// LeaderLeaseName - имя аренды лидера, которая дает право запускать планировщик и получать обновления
+const LeaderLeaseName = "bot_leader"
+
+type LeaseService interface {
+	TryAcquire(ctx context.Context, holder string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, holder string) error
+	GetCurrent(ctx context.Context) (*entity.Lease, error)
+}
+
+type leaseService struct {
+	leaseRepo repo.LeaseRepo
+	log       *logger.Logger
+}
+
+func NewLeaseService(leaseRepo repo.LeaseRepo, log *logger.Logger) (LeaseService, error) {
+	if log == nil {
+		return nil, errors.New("log is nil")
+	}
+	if leaseRepo == nil {
+		return nil, errors.New("leaseRepo is nil")
+	}
+
+	return &leaseService{
+		leaseRepo: leaseRepo,
+		log:       log,
+	}, nil
+}
+
+func (l *leaseService) TryAcquire(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	return l.leaseRepo.TryAcquire(ctx, LeaderLeaseName, holder, ttl)
+}
+
+func (l *leaseService) Release(ctx context.Context, holder string) error {
+	return l.leaseRepo.Release(ctx, LeaderLeaseName, holder)
+}
+
+func (l *leaseService) GetCurrent(ctx context.Context) (*entity.Lease, error) {
+	return l.leaseRepo.Get(ctx, LeaderLeaseName)
+}
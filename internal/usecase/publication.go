@@ -8,36 +8,51 @@ import (
 	"github.com/Enthreeka/tg-posting-bot/internal/repo"
 	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
 	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/button"
+	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/markup"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"time"
-	"unicode/utf8"
 )
 
 type PublicationService interface {
 	CreatePublication(ctx context.Context, publication *entity.Publication) (int, error)
-	CreatePublicationOnlyWithText(ctx context.Context, text string, id int) (int, error)
+	CreatePublicationOnlyWithText(ctx context.Context, text string, entities []entity.MessageEntity, id int) (int, error)
 
 	DeletePublication(ctx context.Context, channelId int) error
 
-	GetAllPublicationsByChannelID(ctx context.Context, channelID int, command string) (*tgbotapi.InlineKeyboardMarkup, error)
+	// GetPublicationList returns page of list with filters and navigation and number of publications in list,
+	// search is used for list of all channels
+	GetPublicationList(ctx context.Context, list entity.PublicationList, search string, loc *time.Location) (*tgbotapi.InlineKeyboardMarkup, int, error)
 	GetPublicationByPublicationID(ctx context.Context, publicationID int) (*entity.Publication, error)
-	GetAwaitingPublication(ctx context.Context) ([]*entity.Publication, error)
 	GetPublicationAndChannel(ctx context.Context, publicationID int) (*entity.Publication, error)
-	GetMarkupPublication(publication []entity.Publication, command string) (*tgbotapi.InlineKeyboardMarkup, error)
-	GetAllPublicationByID(ctx context.Context, publicationID int) ([]entity.Publication, error)
 	GetOnePublicationByID(ctx context.Context, publicationID int) (*entity.Publication, error)
-	GetSentAndWaitingToDeletePublication(ctx context.Context) ([]*entity.Publication, error)
 
-	UpdatePublicationButtonText(ctx context.Context, publicationID int, buttonText string) error
-	UpdatePublicationButtonLink(ctx context.Context, publicationID int, buttonLink string) error
-	UpdatePublicationText(ctx context.Context, publicationID int, text string) error
+	// AddButtons appends rows of buttons to the end of publication buttons
+	AddButtons(ctx context.Context, publicationID int, rows entity.Buttons) error
+	DeleteButton(ctx context.Context, publicationID int, row int, col int) error
+	// ToggleOption switches delivery option of publication
+	ToggleOption(ctx context.Context, publicationID int, option entity.DeliveryOption) error
+	// UpdatePreviewURL sets link for preview, empty url means first link of text
+	UpdatePreviewURL(ctx context.Context, publicationID int, url string) error
+	// MoveButtonRow moves row of buttons up (shift -1) or down (shift 1)
+	MoveButtonRow(ctx context.Context, publicationID int, row int, shift int) error
+	UpdatePublicationText(ctx context.Context, publicationID int, text string, entities []entity.MessageEntity) error
 	UpdatePublicationStatus(ctx context.Context, publicationID int, status entity.PublicationStatus) error
-	UpdatePublicationImage(ctx context.Context, publicationID int, image *string) error
+	// AddMedia - одиночный файл заменяет вложения публикации, файлы одного альбома добавляются друг за другом,
+	// возвращает количество вложений публикации
+	AddMedia(ctx context.Context, publicationID int, media entity.Media) (int, error)
 	UpdatePublicationDate(ctx context.Context, publicationID int, date time.Time) error
 	UpdateDeleteDate(ctx context.Context, publicationID int, date time.Time) error
-	UpdateMessageID(ctx context.Context, publicationID int, messageID int64) error
+	UpdateDeleteTTL(ctx context.Context, publicationID int, ttl time.Duration) error
+	ResetDeleteDate(ctx context.Context, publicationID int) error
+	UpdateMessageIDs(ctx context.Context, publicationID int, messageIDs []int64) error
+	UpdateStorageMessageIDs(ctx context.Context, publicationID int, messageIDs []int64) error
 }
 
+var (
+	ErrAlbumFull   = errors.New("album can not contain more files")
+	ErrTextTooLong = errors.New("text does not fit into publication")
+)
+
 type publicationService struct {
 	publicationRepo repo.PublicationRepo
 	log             *logger.Logger
@@ -57,22 +72,18 @@ func NewPublicationService(publicationRepo repo.PublicationRepo, log *logger.Log
 	}, nil
 }
 
-func (p *publicationService) GetSentAndWaitingToDeletePublication(ctx context.Context) ([]*entity.Publication, error) {
-	return p.publicationRepo.GetSentAndWaitingToDeletePublication(ctx)
+func (p *publicationService) UpdateMessageIDs(ctx context.Context, publicationID int, messageIDs []int64) error {
+	return p.publicationRepo.UpdateMessageIDs(ctx, publicationID, messageIDs)
 }
 
-func (p *publicationService) UpdateMessageID(ctx context.Context, publicationID int, messageID int64) error {
-	return p.publicationRepo.UpdateMessageID(ctx, publicationID, messageID)
+func (p *publicationService) UpdateStorageMessageIDs(ctx context.Context, publicationID int, messageIDs []int64) error {
+	return p.publicationRepo.UpdateStorageMessageIDs(ctx, publicationID, messageIDs)
 }
 
 func (p *publicationService) GetOnePublicationByID(ctx context.Context, publicationID int) (*entity.Publication, error) {
 	return p.publicationRepo.GetOnePublicationByID(ctx, publicationID)
 }
 
-func (p *publicationService) GetAllPublicationByID(ctx context.Context, publicationID int) ([]entity.Publication, error) {
-	return p.publicationRepo.GetAllPublicationByID(ctx, publicationID)
-}
-
 func (p *publicationService) CreatePublication(ctx context.Context, publication *entity.Publication) (int, error) {
 	return p.publicationRepo.CreatePublication(ctx, publication)
 }
@@ -91,37 +102,128 @@ func (p *publicationService) GetPublicationAndChannel(ctx context.Context, publi
 	return publication, nil
 }
 
-func (p *publicationService) UpdatePublicationButtonText(ctx context.Context, publicationID int, buttonText string) error {
-	return p.publicationRepo.UpdatePublicationButtonText(ctx, publicationID, buttonText)
+func (p *publicationService) AddButtons(ctx context.Context, publicationID int, rows entity.Buttons) error {
+	publication, err := p.publicationRepo.GetPublicationByPublicationID(ctx, publicationID)
+	if err != nil {
+		return err
+	}
+
+	buttons, err := publication.Buttons.Append(rows)
+	if err != nil {
+		return err
+	}
+	return p.publicationRepo.UpdateButtons(ctx, publicationID, buttons)
+}
+
+func (p *publicationService) DeleteButton(ctx context.Context, publicationID int, row int, col int) error {
+	publication, err := p.publicationRepo.GetPublicationByPublicationID(ctx, publicationID)
+	if err != nil {
+		return err
+	}
+
+	// кнопка уже удалена повторным нажатием
+	buttons, ok := publication.Buttons.Delete(row, col)
+	if !ok {
+		return nil
+	}
+	return p.publicationRepo.UpdateButtons(ctx, publicationID, buttons)
+}
+
+func (p *publicationService) ToggleOption(ctx context.Context, publicationID int, option entity.DeliveryOption) error {
+	publication, err := p.publicationRepo.GetPublicationByPublicationID(ctx, publicationID)
+	if err != nil {
+		return err
+	}
+
+	if !publication.Options.Toggle(option) {
+		return fmt.Errorf("unknown delivery option %q", option)
+	}
+	// без разделения длинный текст не будет отправлен
+	if option == entity.OptionSplit && !publication.FitsText() {
+		return ErrTextTooLong
+	}
+	if err = p.publicationRepo.UpdateOptions(ctx, publicationID, publication.Options); err != nil || !option.KeptByDraft() {
+		return err
+	}
+	return p.publicationRepo.UpdateStorageMessageIDs(ctx, publicationID, nil)
 }
 
-func (p *publicationService) UpdatePublicationButtonLink(ctx context.Context, publicationID int, buttonLink string) error {
-	return p.publicationRepo.UpdatePublicationButtonLink(ctx, publicationID, buttonLink)
+func (p *publicationService) UpdatePreviewURL(ctx context.Context, publicationID int, url string) error {
+	publication, err := p.publicationRepo.GetPublicationByPublicationID(ctx, publicationID)
+	if err != nil {
+		return err
+	}
+
+	// ссылка для превью имеет смысл только при включенном превью
+	publication.Options.Preview.URL = url
+	if url != "" {
+		publication.Options.Preview.Enabled = true
+	}
+	if err = p.publicationRepo.UpdateOptions(ctx, publicationID, publication.Options); err != nil {
+		return err
+	}
+	return p.publicationRepo.UpdateStorageMessageIDs(ctx, publicationID, nil)
+}
+
+func (p *publicationService) MoveButtonRow(ctx context.Context, publicationID int, row int, shift int) error {
+	publication, err := p.publicationRepo.GetPublicationByPublicationID(ctx, publicationID)
+	if err != nil {
+		return err
+	}
+
+	buttons, ok := publication.Buttons.Swap(row, row+shift)
+	if !ok {
+		return nil
+	}
+	return p.publicationRepo.UpdateButtons(ctx, publicationID, buttons)
 }
 
-func (p *publicationService) UpdatePublicationText(ctx context.Context, publicationID int, text string) error {
-	return p.publicationRepo.UpdatePublicationText(ctx, publicationID, text)
+func (p *publicationService) UpdatePublicationText(ctx context.Context, publicationID int, text string, entities []entity.MessageEntity) error {
+	return p.publicationRepo.UpdatePublicationText(ctx, publicationID, text, entities)
 }
 
 func (p *publicationService) UpdatePublicationStatus(ctx context.Context, publicationID int, status entity.PublicationStatus) error {
 	return p.publicationRepo.UpdatePublicationStatus(ctx, publicationID, status)
 }
 
-func (p *publicationService) UpdatePublicationImage(ctx context.Context, publicationID int, image *string) error {
-	return p.publicationRepo.UpdatePublicationImage(ctx, publicationID, image)
+func (p *publicationService) AddMedia(ctx context.Context, publicationID int, media entity.Media) (int, error) {
+	if media.MediaGroupID == "" {
+		return 1, p.publicationRepo.ReplaceMedia(ctx, publicationID, []entity.Media{media})
+	}
+
+	current, err := p.publicationRepo.GetMedia(ctx, publicationID)
+	if err != nil {
+		return 0, err
+	}
+	// первый файл нового альбома заменяет прежние вложения
+	if len(current) == 0 || current[len(current)-1].MediaGroupID != media.MediaGroupID {
+		return 1, p.publicationRepo.ReplaceMedia(ctx, publicationID, []entity.Media{media})
+	}
+	if len(current) >= entity.MaxAlbumSize {
+		return len(current), ErrAlbumFull
+	}
+	return len(current) + 1, p.publicationRepo.AddMedia(ctx, publicationID, media)
 }
 
-func (p *publicationService) GetAllPublicationsByChannelID(ctx context.Context, channelID int, command string) (*tgbotapi.InlineKeyboardMarkup, error) {
-	publication, err := p.publicationRepo.GetAllPublicationByChannelID(ctx, channelID)
+func (p *publicationService) GetPublicationList(ctx context.Context, list entity.PublicationList, search string, loc *time.Location) (*tgbotapi.InlineKeyboardMarkup, int, error) {
+	total, err := p.publicationRepo.CountList(ctx, list, search)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return p.createPublicationMarkup(publication, command)
-}
+	pager := markup.Paginator{
+		Page:  list.Page,
+		Total: total,
+		Data:  func(page int) string { return list.WithPage(page).Data() },
+	}
+	list.Page = pager.Current()
 
-func (p *publicationService) GetMarkupPublication(publication []entity.Publication, command string) (*tgbotapi.InlineKeyboardMarkup, error) {
-	return p.createPublicationMarkup(publication, command)
+	publication, err := p.publicationRepo.GetList(ctx, list, search, pager.Limit(), pager.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return p.createPublicationMarkup(publication, list, pager, loc), total, nil
 }
 
 func (p *publicationService) UpdatePublicationDate(ctx context.Context, publicationID int, date time.Time) error {
@@ -132,73 +234,94 @@ func (p *publicationService) UpdateDeleteDate(ctx context.Context, publicationID
 	return p.publicationRepo.UpdateDeleteDate(ctx, publicationID, date)
 }
 
-func (p *publicationService) GetPublicationByPublicationID(ctx context.Context, publicationID int) (*entity.Publication, error) {
-	return p.publicationRepo.GetPublicationByPublicationID(ctx, publicationID)
+func (p *publicationService) UpdateDeleteTTL(ctx context.Context, publicationID int, ttl time.Duration) error {
+	return p.publicationRepo.UpdateDeleteTTL(ctx, publicationID, ttl)
+}
+
+func (p *publicationService) ResetDeleteDate(ctx context.Context, publicationID int) error {
+	return p.publicationRepo.ResetDeleteDate(ctx, publicationID)
 }
 
-func (p *publicationService) GetAwaitingPublication(ctx context.Context) ([]*entity.Publication, error) {
-	return p.publicationRepo.GetAwaitingPublication(ctx)
+func (p *publicationService) GetPublicationByPublicationID(ctx context.Context, publicationID int) (*entity.Publication, error) {
+	return p.publicationRepo.GetPublicationByPublicationID(ctx, publicationID)
 }
 
-func (p *publicationService) createPublicationMarkup(publication []entity.Publication, command string) (*tgbotapi.InlineKeyboardMarkup, error) {
-	var rows [][]tgbotapi.InlineKeyboardButton
-	var row []tgbotapi.InlineKeyboardButton
+func (p *publicationService) createPublicationMarkup(publication []entity.Publication, list entity.PublicationList, pager markup.Paginator, loc *time.Location) *tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(publication)+6)
+
+	for _, el := range publication {
+		var status string
+		switch el.PublicationStatus {
+		case entity.StatusSent:
+			status = `✅`
+		case entity.StatusAwaits:
+			status = `⏱`
+		case entity.StatusDeletedByBot:
+			status = `🗑`
+		case entity.StatusErrorOnSending, entity.StatusErrorOnDeleting:
+			status = `❌`
+		}
 
-	buttonsPerRow := 1
-	for i, el := range publication {
-		if el.ID != 0 {
-			var (
-				status string
-				text   string
-			)
-			if el.PublicationStatus == entity.StatusSent {
-				status = `✅`
-			}
+		var date string
+		if el.PublicationDate == nil {
+			date = "[Дата не назначена]"
 			if el.PublicationStatus == entity.StatusAwaits {
-				status = `⏱`
-			}
-			if el.PublicationStatus == entity.StatusDeletedByBot {
-				status = `🗑`
-			}
-
-			if el.PublicationStatus == entity.StatusErrorOnSending || el.PublicationStatus == entity.StatusErrorOnDeleting {
-				status = `❌`
+				status = `📝`
 			}
+		} else {
+			date = el.PublicationDate.In(loc).Format(entity.DateLayout)
+		}
 
-			switch {
-			case utf8.RuneCountInString(el.Text) == 0:
-				text = "Пусто"
-			case utf8.RuneCountInString(el.Text) < 10:
-				text = el.Text[:len(el.Text)-1]
-			default:
-				text = el.Text[:10]
-			}
+		text := []rune(el.Text)
+		switch {
+		case len(text) == 0:
+			text = []rune("Пусто")
+		case len(text) > 10:
+			text = text[:10]
+		}
 
-			var date string
-			if el.PublicationDate == nil {
-				date = "[Дата не назначена]"
-			} else {
-				date = el.PublicationDate.Format(time.DateTime)
-			}
+		// в результатах поиска публикации разных каналов
+		label := fmt.Sprintf("%s...%v %s", string(text), date, status)
+		if list.IsSearch() {
+			label = fmt.Sprintf("[%s] %s", el.ChannelName, label)
+		}
 
-			btn := tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s...%v %s", text, date, status),
-				fmt.Sprintf("publication_%s_%d", command, el.ID))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(label,
+			fmt.Sprintf("publication_%s_%d", list.Mode.Command(), el.ID))))
+	}
 
-			row = append(row, btn)
+	if row := pager.Row(); row != nil {
+		rows = append(rows, row)
+	}
 
-			if (i+1)%buttonsPerRow == 0 || i == len(publication)-1 {
-				rows = append(rows, row)
-				row = []tgbotapi.InlineKeyboardButton{}
-			}
+	statusRow := make([]tgbotapi.InlineKeyboardButton, 0, len(entity.ListStatusTitles))
+	for i, title := range entity.ListStatusTitles {
+		status := entity.PublicationListStatus(i)
+		if status == list.Status {
+			title = "• " + title
 		}
+		statusRow = append(statusRow, tgbotapi.NewInlineKeyboardButtonData(title, list.WithStatus(status).Data()))
+	}
+	rows = append(rows, statusRow)
+
+	sortTitle := "Сначала поздние"
+	if list.Asc {
+		sortTitle = "Сначала ранние"
 	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(sortTitle, list.Reversed().Data()),
+		tgbotapi.NewInlineKeyboardButtonData("Поиск", "pub_search")))
 
+	if !list.IsSearch() {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Вернуться назад", fmt.Sprintf("channel_get_%d", list.ChannelID))))
+	}
 	rows = append(rows, []tgbotapi.InlineKeyboardButton{button.MainMenuButton})
-	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	publicationMarkup := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
-	return &markup, nil
+	return &publicationMarkup
 }
 
-func (p *publicationService) CreatePublicationOnlyWithText(ctx context.Context, text string, id int) (int, error) {
-	return p.publicationRepo.CreatePublicationOnlyWithText(ctx, text, id)
+func (p *publicationService) CreatePublicationOnlyWithText(ctx context.Context, text string, entities []entity.MessageEntity, id int) (int, error) {
+	return p.publicationRepo.CreatePublicationOnlyWithText(ctx, text, entities, id)
 }
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/repo"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	"github.com/Enthreeka/tg-posting-bot/pkg/window"
+	"strings"
+	"time"
+)
+
+// maxSlotsSearched - how many slots are checked for free one, at 4 slots a day it is about a year
+const maxSlotsSearched = 1500
+
+var (
+	ErrNoQueueSlots = errors.New("queue slots of channel are not set")
+	ErrQueueSeries  = errors.New("recurring publication can not be queued")
+	ErrQueueFull    = errors.New("no free queue slot")
+)
+
+// QueueService places publications into daily slot grid of channel
+type QueueService interface {
+	// UpdateSlots saves slot grid of channel, empty spec disables queue
+	UpdateSlots(ctx context.Context, channelID int, spec string) error
+	// Add puts publication into next free slot of its channel queue and returns slot
+	Add(ctx context.Context, publicationID int) (time.Time, error)
+	// Shift moves queued publications after freed slot one slot up, returns number of moved publications
+	Shift(ctx context.Context, channelID int, freed time.Time) (int, error)
+}
+
+type queueService struct {
+	channelRepo     repo.ChannelRepo
+	publicationRepo repo.PublicationRepo
+	windowService   PostingWindowService
+	jobService      JobService
+	log             *logger.Logger
+}
+
+func NewQueueService(channelRepo repo.ChannelRepo,
+	publicationRepo repo.PublicationRepo,
+	windowService PostingWindowService,
+	jobService JobService,
+	log *logger.Logger) (QueueService, error) {
+	if log == nil {
+		return nil, errors.New("log is nil")
+	}
+	if channelRepo == nil {
+		return nil, errors.New("channelRepo is nil")
+	}
+	if publicationRepo == nil {
+		return nil, errors.New("publicationRepo is nil")
+	}
+	if windowService == nil {
+		return nil, errors.New("windowService is nil")
+	}
+	if jobService == nil {
+		return nil, errors.New("jobService is nil")
+	}
+
+	return &queueService{
+		channelRepo:     channelRepo,
+		publicationRepo: publicationRepo,
+		windowService:   windowService,
+		jobService:      jobService,
+		log:             log,
+	}, nil
+}
+
+func (q *queueService) UpdateSlots(ctx context.Context, channelID int, spec string) error {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return q.channelRepo.UpdateQueueSlots(ctx, channelID, nil)
+	}
+
+	slots, err := window.ParseSlots(spec)
+	if err != nil {
+		return err
+	}
+	spec = window.FormatSlots(slots)
+	return q.channelRepo.UpdateQueueSlots(ctx, channelID, &spec)
+}
+
+func (q *queueService) Add(ctx context.Context, publicationID int) (time.Time, error) {
+	publication, err := q.publicationRepo.GetPublicationAndChannel(ctx, publicationID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if publication.IsSeries() {
+		return time.Time{}, ErrQueueSeries
+	}
+	channel, err := q.channelRepo.GetByID(ctx, int(publication.ChannelID))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if channel.QueueSlots == nil {
+		return time.Time{}, ErrNoQueueSlots
+	}
+	slots, err := window.ParseSlots(*channel.QueueSlots)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now()
+	dates, err := q.publicationRepo.GetScheduledDates(ctx, channel.ID, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	taken := make(map[int64]bool, len(dates))
+	for _, date := range dates {
+		taken[date.Unix()] = true
+	}
+	// повторное добавление переносит публикацию в ближайший свободный слот, ее текущий слот не считается занятым
+	if publication.PublicationDate != nil && publication.PublicationStatus == entity.StatusAwaits {
+		delete(taken, publication.PublicationDate.Unix())
+	}
+
+	schedule, err := q.windowService.Schedule(ctx, channel.ID, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	slot := now
+	for i := 0; i < maxSlotsSearched; i++ {
+		slot = window.NextSlot(slots, channel.Location(), slot)
+		if taken[slot.Unix()] || !schedule.Allowed(slot) {
+			continue
+		}
+
+		if err = q.publicationRepo.UpdateQueueDate(ctx, publicationID, slot); err != nil {
+			return time.Time{}, err
+		}
+		if err = q.jobService.SchedulePublish(ctx, publicationID, slot); err != nil {
+			return time.Time{}, err
+		}
+		return slot, nil
+	}
+	return time.Time{}, ErrQueueFull
+}
+
+func (q *queueService) Shift(ctx context.Context, channelID int, freed time.Time) (int, error) {
+	publications, err := q.publicationRepo.GetQueued(ctx, channelID, freed)
+	if err != nil {
+		return 0, err
+	}
+
+	// каждая публикация занимает слот предыдущей, первая - освободившийся
+	slot := freed
+	for i, publication := range publications {
+		if err = q.publicationRepo.UpdateQueueDate(ctx, publication.ID, slot); err != nil {
+			return i, err
+		}
+		if err = q.jobService.SchedulePublish(ctx, publication.ID, slot); err != nil {
+			return i, err
+		}
+		slot = *publication.PublicationDate
+	}
+	return len(publications), nil
+}
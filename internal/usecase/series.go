@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/repo"
+	customErr "github.com/Enthreeka/tg-posting-bot/pkg/bot_error"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	"github.com/Enthreeka/tg-posting-bot/pkg/recurrence"
+	"strings"
+	"time"
+)
+
+// occurrencesShown - сколько последних вхождений серии показывать администратору
+const occurrencesShown = 5
+
+var ErrNoPublicationDate = errors.New("publication date is not set")
+
+// SeriesService manages recurring publications: rule of series, its next occurrence and history of occurrences
+type SeriesService interface {
+	SetRule(ctx context.Context, publicationID int, expr string) (time.Time, error)
+	ScheduleNext(ctx context.Context, publication *entity.Publication, after time.Time) (bool, error)
+	NextRun(ctx context.Context, publicationID int) (*time.Time, error)
+	// GetSeries returns publication with its next run and last occurrences, next run is nil if nothing is scheduled
+	GetSeries(ctx context.Context, publicationID int) (*entity.Publication, *time.Time, []entity.Occurrence, error)
+
+	Pause(ctx context.Context, publicationID int) error
+	Resume(ctx context.Context, publicationID int) (bool, error)
+	Skip(ctx context.Context, publicationID int) (bool, error)
+	End(ctx context.Context, publicationID int) error
+
+	CreateOccurrence(ctx context.Context, occurrence *entity.Occurrence) (int, error)
+	UpdateOccurrenceStatus(ctx context.Context, occurrenceID int, status entity.OccurrenceStatus, cause error) error
+	GetOccurrences(ctx context.Context, publicationID int) ([]entity.Occurrence, error)
+}
+
+type seriesService struct {
+	publicationRepo repo.PublicationRepo
+	occurrenceRepo  repo.OccurrenceRepo
+	jobService      JobService
+	log             *logger.Logger
+}
+
+func NewSeriesService(publicationRepo repo.PublicationRepo,
+	occurrenceRepo repo.OccurrenceRepo,
+	jobService JobService,
+	log *logger.Logger) (SeriesService, error) {
+	if log == nil {
+		return nil, errors.New("log is nil")
+	}
+	if publicationRepo == nil {
+		return nil, errors.New("publicationRepo is nil")
+	}
+	if occurrenceRepo == nil {
+		return nil, errors.New("occurrenceRepo is nil")
+	}
+	if jobService == nil {
+		return nil, errors.New("jobService is nil")
+	}
+
+	return &seriesService{
+		publicationRepo: publicationRepo,
+		occurrenceRepo:  occurrenceRepo,
+		jobService:      jobService,
+		log:             log,
+	}, nil
+}
+
+func (s *seriesService) rule(publication *entity.Publication) (recurrence.Rule, error) {
+	if publication.PublicationDate == nil {
+		return nil, ErrNoPublicationDate
+	}
+	return recurrence.Parse(*publication.Recurrence, publication.PublicationDate.In(publication.Location()))
+}
+
+// SetRule saves recurrence rule and schedules first occurrence, publication date is start of series
+func (s *seriesService) SetRule(ctx context.Context, publicationID int, expr string) (time.Time, error) {
+	publication, err := s.publicationRepo.GetPublicationAndChannel(ctx, publicationID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if publication.PublicationDate == nil {
+		return time.Time{}, ErrNoPublicationDate
+	}
+
+	// время правила задается в часовом поясе канала, переходы на летнее время не сдвигают его
+	rule, err := recurrence.Parse(expr, publication.PublicationDate.In(publication.Location()))
+	if err != nil {
+		return time.Time{}, err
+	}
+	// первое вхождение - сама дата публикации, если она в будущем и подходит под правило
+	from := publication.PublicationDate.Add(-time.Nanosecond)
+	if now := time.Now(); now.After(from) {
+		from = now
+	}
+	next, ok := rule.Next(from)
+	if !ok {
+		return time.Time{}, recurrence.ErrNoOccurrence
+	}
+
+	expr = strings.TrimSpace(expr)
+	if err := s.publicationRepo.UpdateRecurrence(ctx, publicationID, &expr); err != nil {
+		return time.Time{}, err
+	}
+	if err := s.jobService.SchedulePublish(ctx, publicationID, next); err != nil {
+		return time.Time{}, err
+	}
+	return next, nil
+}
+
+// ScheduleNext schedules first occurrence of series after given time, occurrences missed in past are not sent.
+// Returns false if series is finished.
+func (s *seriesService) ScheduleNext(ctx context.Context, publication *entity.Publication, after time.Time) (bool, error) {
+	rule, err := s.rule(publication)
+	if err != nil {
+		return false, err
+	}
+
+	if now := time.Now(); now.After(after) {
+		after = now
+	}
+	next, ok := rule.Next(after)
+	if !ok {
+		return false, nil
+	}
+
+	if err := s.jobService.SchedulePublish(ctx, publication.ID, next); err != nil {
+		return false, err
+	}
+	if publication.SeriesPaused {
+		return true, s.jobService.HoldPublication(ctx, publication.ID, entity.JobPublish)
+	}
+	return true, nil
+}
+
+func (s *seriesService) NextRun(ctx context.Context, publicationID int) (*time.Time, error) {
+	job, err := s.jobService.GetByPublicationID(ctx, publicationID, entity.JobPublish)
+	if err != nil {
+		return nil, err
+	}
+	return &job.RunAt, nil
+}
+
+func (s *seriesService) GetSeries(ctx context.Context, publicationID int) (*entity.Publication, *time.Time, []entity.Occurrence, error) {
+	publication, err := s.publicationRepo.GetPublicationAndChannel(ctx, publicationID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	next, err := s.NextRun(ctx, publicationID)
+	if err != nil && !errors.Is(err, customErr.ErrNoRows) {
+		return nil, nil, nil, err
+	}
+
+	occurrences, err := s.GetOccurrences(ctx, publicationID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return publication, next, occurrences, nil
+}
+
+func (s *seriesService) Pause(ctx context.Context, publicationID int) error {
+	if err := s.publicationRepo.UpdateSeriesPaused(ctx, publicationID, true); err != nil {
+		return err
+	}
+	return s.jobService.HoldPublication(ctx, publicationID, entity.JobPublish)
+}
+
+// Resume continues series from nearest future occurrence, returns false if series is finished
+func (s *seriesService) Resume(ctx context.Context, publicationID int) (bool, error) {
+	if err := s.publicationRepo.UpdateSeriesPaused(ctx, publicationID, false); err != nil {
+		return false, err
+	}
+
+	publication, err := s.publicationRepo.GetPublicationAndChannel(ctx, publicationID)
+	if err != nil {
+		return false, err
+	}
+	return s.ScheduleNext(ctx, publication, time.Now())
+}
+
+// Skip marks next occurrence as skipped and schedules the following one, returns false if series is finished
+func (s *seriesService) Skip(ctx context.Context, publicationID int) (bool, error) {
+	publication, err := s.publicationRepo.GetPublicationAndChannel(ctx, publicationID)
+	if err != nil {
+		return false, err
+	}
+	job, err := s.jobService.GetByPublicationID(ctx, publicationID, entity.JobPublish)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := s.CreateOccurrence(ctx, &entity.Occurrence{
+		PublicationID: publicationID,
+		RunAt:         job.RunAt,
+		Status:        entity.OccurrenceSkipped,
+	}); err != nil {
+		return false, err
+	}
+
+	ok, err := s.ScheduleNext(ctx, publication, job.RunAt)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, s.End(ctx, publicationID)
+	}
+	return true, nil
+}
+
+// End stops series, already sent occurrences remain in channel until their delete date
+func (s *seriesService) End(ctx context.Context, publicationID int) error {
+	if err := s.publicationRepo.UpdateRecurrence(ctx, publicationID, nil); err != nil {
+		return err
+	}
+	return s.jobService.CancelPublication(ctx, publicationID, entity.JobPublish)
+}
+
+func (s *seriesService) CreateOccurrence(ctx context.Context, occurrence *entity.Occurrence) (int, error) {
+	return s.occurrenceRepo.Create(ctx, occurrence)
+}
+
+func (s *seriesService) UpdateOccurrenceStatus(ctx context.Context, occurrenceID int, status entity.OccurrenceStatus, cause error) error {
+	var lastError *string
+	if cause != nil {
+		text := cause.Error()
+		lastError = &text
+	}
+	return s.occurrenceRepo.UpdateStatus(ctx, occurrenceID, status, lastError)
+}
+
+func (s *seriesService) GetOccurrences(ctx context.Context, publicationID int) ([]entity.Occurrence, error) {
+	occurrences, err := s.occurrenceRepo.GetLast(ctx, publicationID, occurrencesShown)
+	if err != nil {
+		return nil, fmt.Errorf("get occurrences of publication %d: %w", publicationID, err)
+	}
+	return occurrences, nil
+}
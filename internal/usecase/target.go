@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/repo"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"time"
+)
+
+var (
+	ErrTargetPrimary = errors.New("channel is primary channel of publication")
+	ErrTargetSeries  = errors.New("recurring publication can not be cross-posted")
+	ErrTargetSent    = errors.New("publication is already sent to channel")
+)
+
+// TargetService manages additional channels of publication (cross-posting) and delivery to them
+type TargetService interface {
+	GetByID(ctx context.Context, id int) (*entity.Target, error)
+	// GetTargets returns publication with its additional channels
+	GetTargets(ctx context.Context, publicationID int) (*entity.Publication, []entity.Target, error)
+	// TargetsMarkup - список каналов администратора с отметками выбранных для публикации
+	TargetsMarkup(ctx context.Context, publication *entity.Publication, targets []entity.Target) (*tgbotapi.InlineKeyboardMarkup, error)
+
+	// Toggle adds channel to targets of publication or removes not sent one, returns true if channel was added
+	Toggle(ctx context.Context, publicationID int, channelID int) (bool, error)
+	// AddGroup adds all channels of group to targets of publication, returns number of added channels
+	AddGroup(ctx context.Context, publicationID int, groupID int) (int, error)
+	UpdateOffsets(ctx context.Context, targetID int, sendOffsetMinutes int, deleteOffsetMinutes int) error
+	// RescheduleDeletes moves deletion of messages already sent to additional channels after delete date of publication is changed
+	RescheduleDeletes(ctx context.Context, publication *entity.Publication) error
+
+	MarkSent(ctx context.Context, targetID int, messageIDs []int64, sentAt time.Time) error
+	UpdateStatus(ctx context.Context, targetID int, status entity.PublicationStatus, cause error) error
+}
+
+type targetService struct {
+	targetRepo      repo.TargetRepo
+	publicationRepo repo.PublicationRepo
+	channelRepo     repo.ChannelRepo
+	jobService      JobService
+	log             *logger.Logger
+}
+
+func NewTargetService(targetRepo repo.TargetRepo,
+	publicationRepo repo.PublicationRepo,
+	channelRepo repo.ChannelRepo,
+	jobService JobService,
+	log *logger.Logger) (TargetService, error) {
+	if log == nil {
+		return nil, errors.New("log is nil")
+	}
+	if targetRepo == nil {
+		return nil, errors.New("targetRepo is nil")
+	}
+	if publicationRepo == nil {
+		return nil, errors.New("publicationRepo is nil")
+	}
+	if channelRepo == nil {
+		return nil, errors.New("channelRepo is nil")
+	}
+	if jobService == nil {
+		return nil, errors.New("jobService is nil")
+	}
+
+	return &targetService{
+		targetRepo:      targetRepo,
+		publicationRepo: publicationRepo,
+		channelRepo:     channelRepo,
+		jobService:      jobService,
+		log:             log,
+	}, nil
+}
+
+func (t *targetService) GetByID(ctx context.Context, id int) (*entity.Target, error) {
+	return t.targetRepo.GetByID(ctx, id)
+}
+
+func (t *targetService) GetTargets(ctx context.Context, publicationID int) (*entity.Publication, []entity.Target, error) {
+	publication, err := t.publicationRepo.GetPublicationAndChannel(ctx, publicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targets, err := t.targetRepo.GetByPublicationID(ctx, publicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return publication, targets, nil
+}
+
+func (t *targetService) TargetsMarkup(ctx context.Context, publication *entity.Publication, targets []entity.Target) (*tgbotapi.InlineKeyboardMarkup, error) {
+	channels, err := t.channelRepo.GetAllAdminChannel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[int]entity.Target, len(targets))
+	for _, target := range targets {
+		selected[target.ChannelID] = target
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, channel := range channels {
+		if channel.TgID == 0 { // check for channel for global notification
+			continue
+		}
+
+		if int64(channel.ID) == publication.ChannelID {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("📌 "+channel.ChannelName, fmt.Sprintf("target_get_%d", publication.ID))))
+			continue
+		}
+
+		target, ok := selected[channel.ID]
+		if !ok {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("⬜ "+channel.ChannelName, fmt.Sprintf("target_toggle_%d_%d", publication.ID, channel.ID))))
+			continue
+		}
+
+		row := tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ "+channel.ChannelName, fmt.Sprintf("target_toggle_%d_%d", publication.ID, channel.ID)))
+		if target.Status == entity.StatusAwaits {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("Смещение", fmt.Sprintf("target_offset_%d", target.ID)))
+		}
+		rows = append(rows, row)
+	}
+
+	groups, err := t.channelRepo.GetAllGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Добавить группу: "+group.Name, fmt.Sprintf("group_target_%d_%d", publication.ID, group.ID))))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Вернуться назад", fmt.Sprintf("publication_get_%d", publication.ID))))
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return &markup, nil
+}
+
+func (t *targetService) Toggle(ctx context.Context, publicationID int, channelID int) (bool, error) {
+	publication, targets, err := t.GetTargets(ctx, publicationID)
+	if err != nil {
+		return false, err
+	}
+	if int64(channelID) == publication.ChannelID {
+		return false, ErrTargetPrimary
+	}
+
+	for _, target := range targets {
+		if target.ChannelID != channelID {
+			continue
+		}
+		// отправленное сообщение осталось бы в канале без задачи на удаление
+		if target.Status != entity.StatusAwaits {
+			return false, ErrTargetSent
+		}
+		// задачи канала удаляются каскадно
+		return false, t.targetRepo.DeleteByID(ctx, target.ID)
+	}
+
+	if publication.IsSeries() {
+		return false, ErrTargetSeries
+	}
+	if _, err = t.targetRepo.Create(ctx, publicationID, channelID); err != nil {
+		return false, err
+	}
+	return true, t.schedule(ctx, publication)
+}
+
+func (t *targetService) AddGroup(ctx context.Context, publicationID int, groupID int) (int, error) {
+	publication, targets, err := t.GetTargets(ctx, publicationID)
+	if err != nil {
+		return 0, err
+	}
+	if publication.IsSeries() {
+		return 0, ErrTargetSeries
+	}
+	group, err := t.channelRepo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return 0, err
+	}
+
+	selected := make(map[int]bool, len(targets)+1)
+	selected[int(publication.ChannelID)] = true
+	for _, target := range targets {
+		selected[target.ChannelID] = true
+	}
+
+	var added int
+	for _, channelID := range group.ChannelIDs() {
+		if selected[channelID] {
+			continue
+		}
+		if _, err = t.targetRepo.Create(ctx, publicationID, channelID); err != nil {
+			return added, err
+		}
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	return added, t.schedule(ctx, publication)
+}
+
+func (t *targetService) UpdateOffsets(ctx context.Context, targetID int, sendOffsetMinutes int, deleteOffsetMinutes int) error {
+	target, err := t.targetRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return err
+	}
+	if target.Status != entity.StatusAwaits {
+		return ErrTargetSent
+	}
+	if err = t.targetRepo.UpdateOffsets(ctx, targetID, sendOffsetMinutes, deleteOffsetMinutes); err != nil {
+		return err
+	}
+
+	publication, err := t.publicationRepo.GetPublicationAndChannel(ctx, target.PublicationID)
+	if err != nil {
+		return err
+	}
+	return t.schedule(ctx, publication)
+}
+
+// schedule moves publish jobs of targets according to publication date, nothing is scheduled for publication without date
+func (t *targetService) schedule(ctx context.Context, publication *entity.Publication) error {
+	if publication.PublicationDate == nil {
+		return nil
+	}
+	// основной канал мог быть уже отправлен, дополнительные каналы отправляются по дате публикации со смещением
+	if publication.PublicationStatus != entity.StatusAwaits && publication.PublicationStatus != entity.StatusSent {
+		return nil
+	}
+	return t.jobService.ScheduleTargets(ctx, publication.ID, *publication.PublicationDate)
+}
+
+func (t *targetService) RescheduleDeletes(ctx context.Context, publication *entity.Publication) error {
+	targets, err := t.targetRepo.GetByPublicationID(ctx, publication.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		if target.Status != entity.StatusSent || len(target.MessageIDs) == 0 || target.SentAt == nil {
+			continue
+		}
+		if deleteAt, ok := target.DeleteAt(*publication, *target.SentAt); ok {
+			if err = t.jobService.ScheduleTargetDelete(ctx, publication.ID, target.ID, deleteAt,
+				target.TelegramChannelID, target.MessageIDs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *targetService) MarkSent(ctx context.Context, targetID int, messageIDs []int64, sentAt time.Time) error {
+	return t.targetRepo.UpdateSent(ctx, targetID, messageIDs, sentAt)
+}
+
+func (t *targetService) UpdateStatus(ctx context.Context, targetID int, status entity.PublicationStatus, cause error) error {
+	var errText *string
+	if cause != nil {
+		text := cause.Error()
+		errText = &text
+	}
+	return t.targetRepo.UpdateStatus(ctx, targetID, status, errText)
+}
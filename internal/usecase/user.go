@@ -16,6 +16,7 @@ type UserService interface {
 	CreateUserIFNotExist(ctx context.Context, user *entity.User) error
 
 	UpdateRoleByUsername(ctx context.Context, role entity.UserRole, username string) error
+	UpdateTimezone(ctx context.Context, id int64, timezone string) error
 }
 
 type userService struct {
@@ -71,3 +72,7 @@ func (u *userService) GetAllUsers(ctx context.Context) ([]entity.User, error) {
 func (u *userService) UpdateRoleByUsername(ctx context.Context, role entity.UserRole, username string) error {
 	return u.userRepo.UpdateRoleByUsername(ctx, role, username)
 }
+
+func (u *userService) UpdateTimezone(ctx context.Context, id int64, timezone string) error {
+	return u.userRepo.UpdateTimezone(ctx, id, timezone)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/internal/repo"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	"github.com/Enthreeka/tg-posting-bot/pkg/window"
+	"strings"
+	"time"
+)
+
+// PostingWindowService checks publication time against posting windows and blackouts of channel
+type PostingWindowService interface {
+	// UpdateWindows saves posting windows of channel, empty spec allows posting at any time
+	UpdateWindows(ctx context.Context, channelID int, spec string) error
+
+	AddBlackout(ctx context.Context, blackout *entity.Blackout) (int, error)
+	GetBlackout(ctx context.Context, id int) (*entity.Blackout, error)
+	GetBlackouts(ctx context.Context, channelID int) ([]entity.Blackout, error)
+	DeleteBlackout(ctx context.Context, id int) error
+
+	// Check returns true if publication can be sent at t, otherwise nearest allowed time.
+	// window.ErrNoSlot is returned if there is no allowed time at all.
+	Check(ctx context.Context, channelID int, t time.Time) (bool, time.Time, error)
+	// Schedule returns posting windows and blackouts of channel which are actual after from
+	Schedule(ctx context.Context, channelID int, from time.Time) (*window.Schedule, error)
+	// ActiveBlackout returns blackout of channel which contains t, nil if there is none
+	ActiveBlackout(ctx context.Context, channelID int, t time.Time) (*entity.Blackout, error)
+}
+
+type postingWindowService struct {
+	channelRepo  repo.ChannelRepo
+	blackoutRepo repo.BlackoutRepo
+	log          *logger.Logger
+}
+
+func NewPostingWindowService(channelRepo repo.ChannelRepo, blackoutRepo repo.BlackoutRepo, log *logger.Logger) (PostingWindowService, error) {
+	if log == nil {
+		return nil, errors.New("log is nil")
+	}
+	if channelRepo == nil {
+		return nil, errors.New("channelRepo is nil")
+	}
+	if blackoutRepo == nil {
+		return nil, errors.New("blackoutRepo is nil")
+	}
+
+	return &postingWindowService{
+		channelRepo:  channelRepo,
+		blackoutRepo: blackoutRepo,
+		log:          log,
+	}, nil
+}
+
+func (p *postingWindowService) UpdateWindows(ctx context.Context, channelID int, spec string) error {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return p.channelRepo.UpdatePostingWindows(ctx, channelID, nil)
+	}
+
+	windows, err := window.Parse(spec)
+	if err != nil {
+		return err
+	}
+	// сохраняется в едином виде, чтобы одинаково отображаться в настройках
+	spec = window.Format(windows)
+	return p.channelRepo.UpdatePostingWindows(ctx, channelID, &spec)
+}
+
+func (p *postingWindowService) AddBlackout(ctx context.Context, blackout *entity.Blackout) (int, error) {
+	if !blackout.StartsAt.Before(blackout.EndsAt) {
+		return 0, errors.New("blackout must start before its end")
+	}
+	return p.blackoutRepo.Create(ctx, blackout)
+}
+
+func (p *postingWindowService) GetBlackout(ctx context.Context, id int) (*entity.Blackout, error) {
+	return p.blackoutRepo.GetByID(ctx, id)
+}
+
+func (p *postingWindowService) GetBlackouts(ctx context.Context, channelID int) ([]entity.Blackout, error) {
+	return p.blackoutRepo.GetActual(ctx, channelID, time.Now())
+}
+
+func (p *postingWindowService) DeleteBlackout(ctx context.Context, id int) error {
+	return p.blackoutRepo.DeleteByID(ctx, id)
+}
+
+func (p *postingWindowService) Schedule(ctx context.Context, channelID int, t time.Time) (*window.Schedule, error) {
+	channel, err := p.channelRepo.GetByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []window.Window
+	if channel.PostingWindows != nil {
+		// окна проверяются при сохранении, испорченное значение не должно блокировать публикации
+		if windows, err = window.Parse(*channel.PostingWindows); err != nil {
+			p.log.Error("invalid posting windows of channel %d: %v", channelID, err)
+		}
+	}
+
+	blackouts, err := p.blackoutRepo.GetActual(ctx, channelID, t)
+	if err != nil {
+		return nil, err
+	}
+	periods := make([]window.Blackout, 0, len(blackouts))
+	for _, blackout := range blackouts {
+		periods = append(periods, window.Blackout{From: blackout.StartsAt, To: blackout.EndsAt})
+	}
+
+	return window.New(windows, periods, channel.Location()), nil
+}
+
+func (p *postingWindowService) Check(ctx context.Context, channelID int, t time.Time) (bool, time.Time, error) {
+	schedule, err := p.Schedule(ctx, channelID, t)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	if schedule.Allowed(t) {
+		return true, t, nil
+	}
+
+	next, ok := schedule.Next(t)
+	if !ok {
+		return false, time.Time{}, window.ErrNoSlot
+	}
+	return false, next, nil
+}
+
+func (p *postingWindowService) ActiveBlackout(ctx context.Context, channelID int, t time.Time) (*entity.Blackout, error) {
+	blackouts, err := p.blackoutRepo.GetActual(ctx, channelID, t)
+	if err != nil {
+		return nil, err
+	}
+	for _, blackout := range blackouts {
+		if !t.Before(blackout.StartsAt) {
+			return &blackout, nil
+		}
+	}
+	return nil, nil
+}
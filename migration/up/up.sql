@@ -1,4 +1,4 @@
-set timezone = 'Europe/Moscow';
+set timezone = 'UTC';
 
 DO $$
     BEGIN
@@ -63,3 +63,252 @@ ALTER TABLE publication
     ALTER COLUMN publication_date DROP NOT NULL;
 
 alter table publication add column message_id bigint default null;
+
+DO $$
+    BEGIN
+        IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'catch_up_policy') THEN
+            CREATE TYPE catch_up_policy AS ENUM ('send_late','skip','ask');
+        END IF;
+    END $$;
+
+alter table channel add column if not exists catch_up_policy catch_up_policy default 'skip' not null;
+alter table channel add column if not exists max_lateness_minutes int default 60 not null;
+
+DO $$
+    BEGIN
+        IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'job_kind') THEN
+            CREATE TYPE job_kind AS ENUM ('publish','delete');
+        END IF;
+    END $$;
+
+DO $$
+    BEGIN
+        IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'job_state') THEN
+            CREATE TYPE job_state AS ENUM ('pending','held','failed');
+        END IF;
+    END $$;
+
+create table if not exists publication_job(
+    id int generated always as identity,
+    publication_id int not null,
+    kind job_kind not null,
+    state job_state default 'pending' not null,
+    run_at timestamp with time zone not null,
+    chat_id bigint null,
+    message_ids bigint[] null,
+    attempts int default 0 not null,
+    locked_until timestamp with time zone null,
+    last_error text null,
+    created_at timestamp with time zone default now() not null,
+    primary key (id),
+    foreign key (publication_id)
+        references publication (id) on delete cascade
+);
+
+create unique index if not exists publication_job_publication_kind_idx on publication_job (publication_id, kind);
+create index if not exists publication_job_due_idx on publication_job (kind, run_at) where state = 'pending';
+
+insert into publication_job (publication_id, kind, run_at)
+select id, 'publish', publication_date from publication
+where publication_status = 'awaits' and publication_date is not null
+on conflict do nothing;
+
+insert into publication_job (publication_id, kind, run_at, chat_id, message_ids)
+select p.id, 'delete', p.delete_date, c.tg_id, array[p.message_id] from publication p
+join channel c on p.channel_id = c.id
+where p.publication_status = 'sent' and p.delete_date is not null and p.message_id is not null
+on conflict do nothing;
+
+create table if not exists leader_lease(
+    name varchar(50) not null,
+    holder varchar(150) not null,
+    acquired_at timestamp with time zone not null,
+    renewed_at timestamp with time zone not null,
+    expires_at timestamp with time zone not null,
+    primary key (name)
+);
+
+alter table channel add column if not exists max_send_attempts int default 5 not null;
+
+create table if not exists publication_job_attempt(
+    id int generated always as identity,
+    publication_id int not null,
+    kind job_kind not null,
+    attempt int not null,
+    error text not null,
+    created_at timestamp with time zone default now() not null,
+    primary key (id),
+    foreign key (publication_id)
+        references publication (id) on delete cascade
+);
+
+create index if not exists publication_job_attempt_publication_idx on publication_job_attempt (publication_id);
+
+alter table publication add column if not exists recurrence text default null;
+alter table publication add column if not exists series_paused boolean default false not null;
+
+DO $$
+    BEGIN
+        IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'occurrence_status') THEN
+            CREATE TYPE occurrence_status AS ENUM ('sent','skipped','error_on_sending','deleted_by_bot','error_on_deleting');
+        END IF;
+    END $$;
+
+create table if not exists publication_occurrence(
+    id int generated always as identity,
+    publication_id int not null,
+    run_at timestamp with time zone not null,
+    status occurrence_status not null,
+    message_id bigint null,
+    error text null,
+    created_at timestamp with time zone default now() not null,
+    updated_at timestamp with time zone default now() not null,
+    primary key (id),
+    foreign key (publication_id)
+        references publication (id) on delete cascade
+);
+
+create index if not exists publication_occurrence_publication_idx on publication_occurrence (publication_id, run_at);
+
+alter table publication_job add column if not exists occurrence_id int null
+    references publication_occurrence (id) on delete cascade;
+
+drop index if exists publication_job_publication_kind_idx;
+create unique index if not exists publication_job_publication_kind_occurrence_idx
+    on publication_job (publication_id, kind, coalesce(occurrence_id, 0));
+
+alter table publication add column if not exists delete_ttl_seconds bigint default null;
+alter table publication add column if not exists sent_at timestamp with time zone default null;
+
+alter table channel add column if not exists timezone varchar(64) default 'Europe/Moscow' not null;
+alter table "user" add column if not exists timezone varchar(64) default 'Europe/Moscow' not null;
+
+alter table channel add column if not exists posting_windows text default null;
+
+create table if not exists channel_blackout(
+    id int generated always as identity,
+    channel_id int not null,
+    starts_at timestamp with time zone not null,
+    ends_at timestamp with time zone not null,
+    reason text null,
+    created_at timestamp with time zone default now() not null,
+    primary key (id),
+    foreign key (channel_id)
+        references channel (id) on delete cascade,
+    check (starts_at < ends_at)
+);
+
+create index if not exists channel_blackout_channel_idx on channel_blackout (channel_id, ends_at);
+
+alter table publication_job add column if not exists ignore_blackout boolean default false not null;
+
+alter table channel add column if not exists queue_slots text default null;
+alter table publication add column if not exists queued boolean default false not null;
+
+create index if not exists publication_channel_date_idx on publication (channel_id, publication_date)
+    where publication_status = 'awaits';
+
+create table if not exists publication_target(
+    id int generated always as identity,
+    publication_id int not null,
+    channel_id int not null,
+    status pub_status default 'awaits' not null,
+    message_id bigint default null,
+    send_offset_minutes int default 0 not null,
+    delete_offset_minutes int default 0 not null,
+    sent_at timestamp with time zone default null,
+    error text null,
+    primary key (id),
+    foreign key (publication_id)
+        references publication (id) on delete cascade,
+    foreign key (channel_id)
+        references channel (id) on delete cascade,
+    unique (publication_id, channel_id)
+);
+
+alter table publication_job add column if not exists target_id int null
+    references publication_target (id) on delete cascade;
+
+drop index if exists publication_job_publication_kind_occurrence_idx;
+create unique index if not exists publication_job_publication_kind_occurrence_target_idx
+    on publication_job (publication_id, kind, coalesce(occurrence_id, 0), coalesce(target_id, 0));
+
+create table if not exists channel_group(
+    id int generated always as identity,
+    name varchar(150) unique not null,
+    created_at timestamp with time zone default now() not null,
+    primary key (id)
+);
+
+create table if not exists channel_group_member(
+    group_id int not null,
+    channel_id int not null,
+    primary key (group_id, channel_id),
+    foreign key (group_id)
+        references channel_group (id) on delete cascade,
+    foreign key (channel_id)
+        references channel (id) on delete cascade
+);
+
+create table if not exists publication_media(
+    publication_id int not null,
+    position int not null,
+    type varchar(20) default 'photo' not null,
+    file_id varchar(200) not null,
+    media_group_id varchar(64) default null,
+    primary key (publication_id, position),
+    foreign key (publication_id)
+        references publication (id) on delete cascade
+);
+
+insert into publication_media (publication_id, position, file_id)
+select id, 0, image from publication where image is not null
+on conflict do nothing;
+update publication set image = null where image is not null;
+
+alter table publication add column if not exists message_ids bigint[] default null;
+alter table publication_target add column if not exists message_ids bigint[] default null;
+alter table publication_occurrence add column if not exists message_ids bigint[] default null;
+
+update publication set message_ids = array[message_id] where message_id is not null and message_ids is null;
+update publication_target set message_ids = array[message_id] where message_id is not null and message_ids is null;
+update publication_occurrence set message_ids = array[message_id] where message_id is not null and message_ids is null;
+
+alter table publication add column if not exists buttons jsonb default null;
+
+update publication set buttons = jsonb_build_array(jsonb_build_array(jsonb_build_object('text', button_text, 'url', button_url)))
+where button_text is not null and button_url is not null and buttons is null;
+update publication set button_text = null, button_url = null where buttons is not null;
+
+alter table publication add column if not exists options jsonb default null;
+
+-- текст публикаций хранится вместе с entities, прежние публикации остаются в MarkdownV2
+-- и отправляются с parse_mode, пока текст не будет изменен
+DO $$
+    BEGIN
+        IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                       WHERE table_name = 'publication' AND column_name = 'entities') THEN
+            ALTER TABLE publication ADD COLUMN entities jsonb default null;
+            ALTER TABLE publication ADD COLUMN parse_mode varchar(20) default null;
+            UPDATE publication SET parse_mode = 'MarkdownV2';
+        END IF;
+    END $$;
+
+-- копии черновиков публикаций в канале-хранилище
+alter table publication add column if not exists storage_message_ids bigint[] default null;
+
+-- импорт публикаций из файла ждет подтверждения администратора
+create table if not exists publication_import(
+    id int generated always as identity,
+    user_id bigint not null,
+    publications jsonb not null,
+    created_at timestamp with time zone default now() not null,
+    primary key (id)
+);
+
+-- причина остановки задачи: после периода тишины опоздание не ограничивает отправку
+alter table publication_job add column if not exists hold_reason varchar(20) default null;
+
+-- попытки задач дополнительных каналов отличаются от попыток основного канала
+alter table publication_job_attempt add column if not exists job_id int default null;
+alter table publication_job_attempt add column if not exists target_id int default null;
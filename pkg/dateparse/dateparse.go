@@ -0,0 +1,207 @@
+package dateparse
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/pkg/ttl"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrEmpty = errors.New("date is empty")
+
+// layouts - absolute dates with year, date without time keeps current time of day
+var layouts = []string{"2006-01-02 15:04", "02.01.2006 15:04", "2006-01-02", "02.01.2006"}
+
+// days - words of day relative to today
+var days = map[string]int{
+	"сегодня":     0,
+	"today":       0,
+	"завтра":      1,
+	"tomorrow":    1,
+	"послезавтра": 2,
+}
+
+var weekdays = map[string]time.Weekday{
+	"пн": time.Monday, "понедельник": time.Monday, "mon": time.Monday, "monday": time.Monday,
+	"вт": time.Tuesday, "вторник": time.Tuesday, "tue": time.Tuesday, "tuesday": time.Tuesday,
+	"ср": time.Wednesday, "среда": time.Wednesday, "среду": time.Wednesday, "wed": time.Wednesday, "wednesday": time.Wednesday,
+	"чт": time.Thursday, "четверг": time.Thursday, "thu": time.Thursday, "thursday": time.Thursday,
+	"пт": time.Friday, "пятница": time.Friday, "пятницу": time.Friday, "fri": time.Friday, "friday": time.Friday,
+	"сб": time.Saturday, "суббота": time.Saturday, "субботу": time.Saturday, "sat": time.Saturday, "saturday": time.Saturday,
+	"вс": time.Sunday, "воскресенье": time.Sunday, "sun": time.Sunday, "sunday": time.Sunday,
+}
+
+// units - russian word forms and english names of duration units
+var units = map[string]time.Duration{
+	"м": time.Minute, "мин": time.Minute, "минута": time.Minute, "минуту": time.Minute, "минуты": time.Minute, "минут": time.Minute,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"ч": time.Hour, "час": time.Hour, "часа": time.Hour, "часов": time.Hour,
+	"h": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"д": ttl.Day, "день": ttl.Day, "дня": ttl.Day, "дней": ttl.Day,
+	"d": ttl.Day, "day": ttl.Day, "days": ttl.Day,
+	"нед": ttl.Week, "неделя": ttl.Week, "неделю": ttl.Week, "недели": ttl.Week, "недель": ttl.Week,
+	"w": ttl.Week, "week": ttl.Week, "weeks": ttl.Week,
+}
+
+// fillers - words which don't change the meaning: "в 10:00", "at 10:00", "on monday"
+var fillers = map[string]bool{"в": true, "во": true, "at": true, "on": true, "a": true, "an": true}
+
+// Parse resolves date expression relative to now, result is in location of now and has no seconds.
+// Accepted expressions:
+//   - absolute: "2024-12-25 09:00", "25.12.2024 09:00", "25.12 09:00" (nearest future 25 december)
+//   - day and optional time: "завтра 10:00", "today at 18:00", "пн 18:30" (nearest future monday), "10:00"
+//   - offset from now: "+45m", "+1d12h", "через 2 часа", "через час 30 минут", "in 3 days"
+//
+// Time of day is kept from now if it is omitted.
+func Parse(text string, now time.Time) (time.Time, error) {
+	s := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(text)), "ё", "е")
+	if s == "" {
+		return time.Time{}, ErrEmpty
+	}
+	now = now.Truncate(time.Minute)
+
+	if offset, ok := strings.CutPrefix(s, "+"); ok {
+		d, err := ttl.Parse(offset)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid offset %q: %w", text, err)
+		}
+		return now.Add(d), nil
+	}
+
+	for _, layout := range layouts {
+		if date, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			if !strings.Contains(layout, ":") {
+				date = atClock(date, now.Hour()*60+now.Minute())
+			}
+			return date, nil
+		}
+	}
+
+	fields := make([]string, 0, 4)
+	for _, field := range strings.Fields(s) {
+		if !fillers[field] {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return time.Time{}, fmt.Errorf("unrecognized date %q", text)
+	}
+
+	if fields[0] == "через" || fields[0] == "in" {
+		d, err := parseDuration(fields[1:])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid offset %q: %w", text, err)
+		}
+		return now.Add(d), nil
+	}
+
+	return parseDay(fields, now, text)
+}
+
+// parseDay parses day followed by optional time, or time only
+func parseDay(fields []string, now time.Time, text string) (time.Time, error) {
+	clock := now.Hour()*60 + now.Minute()
+	explicitClock := false
+	if last := fields[len(fields)-1]; strings.Contains(last, ":") {
+		var err error
+		if clock, err = parseClock(last); err != nil {
+			return time.Time{}, fmt.Errorf("invalid time in %q: %w", text, err)
+		}
+		fields, explicitClock = fields[:len(fields)-1], true
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case len(fields) == 0 && explicitClock:
+		// только время - ближайшее в будущем
+		date := atClock(today, clock)
+		if !date.After(now) {
+			date = atClock(today.AddDate(0, 0, 1), clock)
+		}
+		return date, nil
+	case len(fields) != 1:
+		return time.Time{}, fmt.Errorf("unrecognized date %q", text)
+	}
+
+	if n, ok := days[fields[0]]; ok {
+		return atClock(today.AddDate(0, 0, n), clock), nil
+	}
+
+	if weekday, ok := weekdays[fields[0]]; ok {
+		shift := (int(weekday) - int(today.Weekday()) + 7) % 7
+		date := atClock(today.AddDate(0, 0, shift), clock)
+		if !date.After(now) {
+			date = date.AddDate(0, 0, 7)
+		}
+		return date, nil
+	}
+
+	// день и месяц без года - ближайшая такая дата в будущем
+	if day, err := time.ParseInLocation("02.01", fields[0], now.Location()); err == nil {
+		for year := now.Year(); year <= now.Year()+4; year++ {
+			date := time.Date(year, day.Month(), day.Day(), 0, clock, 0, 0, now.Location())
+			// 29.02 существует не каждый год
+			if date.Day() == day.Day() && date.After(now) {
+				return date, nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date %q", text)
+}
+
+// parseDuration parses sequence of amounts and units: "2 часа", "час 30 минут", "3 days", "2h30m"
+func parseDuration(fields []string) (time.Duration, error) {
+	var total time.Duration
+	for i := 0; i < len(fields); i++ {
+		if unit, ok := units[fields[i]]; ok {
+			// "через час", "in a week" - количество не указано
+			total += unit
+			continue
+		}
+
+		n, err := strconv.Atoi(fields[i])
+		if err != nil {
+			d, ttlErr := ttl.Parse(fields[i])
+			if ttlErr != nil {
+				return 0, fmt.Errorf("unknown amount %q", fields[i])
+			}
+			total += d
+			continue
+		}
+		if i+1 >= len(fields) {
+			return 0, fmt.Errorf("no unit after %d", n)
+		}
+		unit, ok := units[fields[i+1]]
+		if !ok {
+			return 0, fmt.Errorf("unknown unit %q", fields[i+1])
+		}
+		total += time.Duration(n) * unit
+		i++
+	}
+
+	if total <= 0 {
+		return 0, ErrEmpty
+	}
+	return total, nil
+}
+
+// parseClock parses time of day "9:00" or "18:30" into minutes
+func parseClock(s string) (int, error) {
+	hours, minutes, _ := strings.Cut(s, ":")
+	h, err := strconv.Atoi(hours)
+	if err != nil || h < 0 || h > 23 {
+		return 0, fmt.Errorf("invalid hours %q", hours)
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || len(minutes) != 2 || m < 0 || m > 59 {
+		return 0, fmt.Errorf("invalid minutes %q", minutes)
+	}
+	return h*60 + m, nil
+}
+
+func atClock(day time.Time, minutes int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, minutes, 0, 0, day.Location())
+}
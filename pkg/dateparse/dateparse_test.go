@@ -0,0 +1,74 @@
+package dateparse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	// среда 11 сентября 2024, 14:20:45
+	now := time.Date(2024, 9, 11, 14, 20, 45, 0, msk)
+	at := func(month time.Month, day int, hour int, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, msk)
+	}
+
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{in: "2024-12-25 09:00", want: at(12, 25, 9, 0)},
+		{in: "25.12.2024 09:00", want: at(12, 25, 9, 0)},
+		{in: "25.12.2024", want: at(12, 25, 14, 20)},
+		{in: "25.12 09:00", want: at(12, 25, 9, 0)},
+		{in: "01.03 10:00", want: time.Date(2025, 3, 1, 10, 0, 0, 0, msk)},
+		{in: "завтра 10:00", want: at(9, 12, 10, 0)},
+		{in: "Завтра в 10:00", want: at(9, 12, 10, 0)},
+		{in: "tomorrow at 9:30", want: at(9, 12, 9, 30)},
+		{in: "послезавтра", want: at(9, 13, 14, 20)},
+		{in: "сегодня 18:00", want: at(9, 11, 18, 0)},
+		{in: "18:00", want: at(9, 11, 18, 0)},
+		{in: "10:00", want: at(9, 12, 10, 0)},
+		{in: "пн 18:30", want: at(9, 16, 18, 30)},
+		{in: "в пятницу 08:15", want: at(9, 13, 8, 15)},
+		{in: "on monday at 10:00", want: at(9, 16, 10, 0)},
+		{in: "ср 15:00", want: at(9, 11, 15, 0)},
+		{in: "ср 12:00", want: at(9, 18, 12, 0)},
+		{in: "+45m", want: at(9, 11, 15, 5)},
+		{in: "+1d2h", want: at(9, 12, 16, 20)},
+		{in: "через 2 часа", want: at(9, 11, 16, 20)},
+		{in: "через час 30 минут", want: at(9, 11, 15, 50)},
+		{in: "через неделю", want: at(9, 18, 14, 20)},
+		{in: "через 3 дня", want: at(9, 14, 14, 20)},
+		{in: "через 90m", want: at(9, 11, 15, 50)},
+		{in: "in 2 hours", want: at(9, 11, 16, 20)},
+		{in: "in a day", want: at(9, 12, 14, 20)},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("Parse(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+		if err == nil && got.Location() != msk {
+			t.Errorf("Parse(%q) location = %v; want %v", tt.in, got.Location(), msk)
+		}
+	}
+}
+
+func TestParseLeapDay(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	got, err := Parse("29.02 10:00", now)
+	if want := time.Date(2028, 2, 29, 10, 0, 0, 0, time.UTC); err != nil || !got.Equal(want) {
+		t.Errorf("Parse(29.02) = %v, %v; want %v", got, err, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	now := time.Date(2024, 9, 11, 14, 20, 0, 0, time.UTC)
+	for _, in := range []string{"", "   ", "вчера", "завтра 25:00", "завтра 10:5", "через", "через 2",
+		"через 2 года", "+", "+2y", "пн вт 10:00", "32.12 10:00", "2024-13-01 10:00"} {
+		if got, err := Parse(in, now); err == nil {
+			t.Errorf("Parse(%q) = %v; must fail", in, got)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets - RFC 5545 limits content line to 75 octets, longer lines are folded
+const maxLineOctets = 75
+
+const timeLayout = "20060102T150405Z"
+
+// Event statuses of VEVENT
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+type Event struct {
+	// UID must be unique and stable so that repeated import updates event instead of duplicating it
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	URL         string
+	Status      string
+}
+
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Bytes encodes calendar as iCalendar, stamp is DTSTAMP of all events
+func (c Calendar) Bytes(stamp time.Time) []byte {
+	var buf bytes.Buffer
+	line := func(name string, value string) {
+		writeFolded(&buf, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", Escape(c.ProdID))
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", Escape(c.Name))
+	}
+
+	for _, event := range c.Events {
+		end := event.End
+		if !end.After(event.Start) {
+			end = event.Start
+		}
+
+		line("BEGIN", "VEVENT")
+		line("UID", Escape(event.UID))
+		line("DTSTAMP", stamp.UTC().Format(timeLayout))
+		line("DTSTART", event.Start.UTC().Format(timeLayout))
+		line("DTEND", end.UTC().Format(timeLayout))
+		line("SUMMARY", Escape(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", Escape(event.Description))
+		}
+		if event.URL != "" {
+			line("URL", event.URL)
+		}
+		if event.Status != "" {
+			line("STATUS", event.Status)
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return buf.Bytes()
+}
+
+// Escape escapes TEXT value: backslash, semicolon, comma and line breaks
+func Escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// writeFolded writes content line ending with CRLF, long line is split by CRLF and space without breaking utf-8 characters
+func writeFolded(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation line starts with space which counts in limit
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
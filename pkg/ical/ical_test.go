@@ -0,0 +1,56 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscape(t *testing.T) {
+	got := Escape("Анонс; встреча, в 10:00\nссылка \\ внизу")
+	want := `Анонс\; встреча\, в 10:00\nссылка \\ внизу`
+	if got != want {
+		t.Errorf("Escape() = %q; want %q", got, want)
+	}
+}
+
+func TestCalendarBytes(t *testing.T) {
+	start := time.Date(2024, 9, 1, 10, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	calendar := Calendar{
+		ProdID: "-//posting bot//RU",
+		Name:   "Контент-план",
+		Events: []Event{{
+			UID:         "publication-1@posting-bot",
+			Start:       start,
+			Summary:     "Новости",
+			Description: strings.Repeat("Длинное описание публикации, ", 10),
+			URL:         "https://t.me/news/15",
+			Status:      StatusConfirmed,
+		}},
+	}
+
+	data := string(calendar.Bytes(start))
+	if !strings.HasPrefix(data, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(data, "END:VCALENDAR\r\n") {
+		t.Fatalf("calendar is not wrapped in VCALENDAR:\n%s", data)
+	}
+	for _, want := range []string{"DTSTART:20240901T070000Z\r\n", "DTEND:20240901T070000Z\r\n", "STATUS:CONFIRMED\r\n"} {
+		if !strings.Contains(data, want) {
+			t.Errorf("calendar does not contain %q", want)
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line is longer than %d octets: %q", maxLineOctets, line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line is folded inside of utf-8 character: %q", line)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(data, "\r\n ", "")
+	if !strings.Contains(unfolded, "DESCRIPTION:"+Escape(calendar.Events[0].Description)+"\r\n") {
+		t.Errorf("folded description is not restored after unfolding:\n%s", unfolded)
+	}
+}
@@ -17,10 +17,30 @@ const (
 	PublicationDelete           TypeCommand = "delete_publication"
 	PublicationTextUpdate       TypeCommand = "update_publication_text"
 	PublicationImageUpdate      TypeCommand = "update_publication_image"
-	PublicationButtonTextUpdate TypeCommand = "update_publication_button_text"
 	PublicationSentDateUpdate   TypeCommand = "update_publication_sent_date"
 	PublicationDeleteDateUpdate TypeCommand = "update_publication_delete_date"
-	PublicationButtonLinkUpdate TypeCommand = "update_publication_button_link"
+	PublicationButtonsAdd       TypeCommand = "add_publication_buttons"
+	PublicationPreviewURLUpdate TypeCommand = "update_publication_preview_url"
+	PublicationRecurrenceUpdate TypeCommand = "update_publication_recurrence"
+	PublicationTargetOffset     TypeCommand = "update_publication_target_offset"
+	PublicationTargetReschedule TypeCommand = "reschedule_publication_target"
+
+	ChannelMaxLatenessUpdate TypeCommand = "update_channel_max_lateness"
+	ChannelMaxAttemptsUpdate TypeCommand = "update_channel_max_attempts"
+	ChannelTimezoneUpdate    TypeCommand = "update_channel_timezone"
+	ChannelWindowsUpdate     TypeCommand = "update_channel_windows"
+	ChannelBlackoutCreate    TypeCommand = "create_channel_blackout"
+	ChannelQueueSlotsUpdate  TypeCommand = "update_channel_queue_slots"
+
+	ChannelGroupCreate     TypeCommand = "create_channel_group"
+	ChannelGroupBroadcast  TypeCommand = "broadcast_channel_group"
+	ChannelGroupReschedule TypeCommand = "reschedule_channel_group"
+
+	UserTimezoneUpdate TypeCommand = "update_user_timezone"
+
+	PublicationImport TypeCommand = "import_publications"
+	PublicationExport TypeCommand = "export_publications_period"
+	PublicationSearch TypeCommand = "search_publications"
 )
 
 var MapTypes = map[TypeCommand]OperationType{
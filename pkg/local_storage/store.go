@@ -14,6 +14,8 @@ type Data struct {
 	PreferMsgID   int
 	CurrentMsgID  int
 	ChannelID     int
+	// PublicationID - публикация, которую изменяет операция
+	PublicationID int
 }
 
 func NewStore() *Store {
@@ -0,0 +1,159 @@
+package recurrence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type cron struct {
+	expr     string
+	minutes  []int
+	hours    []int
+	days     map[int]bool
+	months   map[int]bool
+	weekdays map[int]bool
+	// как в cron: если ограничены и день месяца, и день недели, подходит любой из них
+	anyDay     bool
+	anyWeekday bool
+	loc        *time.Location
+}
+
+func parseCron(expr string, loc *time.Location) (Rule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields, got %d", len(fields))
+	}
+
+	minutes, err := parseField(fields[0], 0, 59)
+	if err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	hours, err := parseField(fields[1], 0, 23)
+	if err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	days, err := parseField(fields[2], 1, 31)
+	if err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	months, err := parseField(fields[3], 1, 12)
+	if err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	weekdays, err := parseField(fields[4], 0, 7)
+	if err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+
+	c := &cron{
+		expr:       expr,
+		minutes:    minutes,
+		hours:      hours,
+		days:       toSet(days),
+		months:     toSet(months),
+		weekdays:   toSet(weekdays),
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+		loc:        loc,
+	}
+	// 7 и 0 - воскресенье
+	if c.weekdays[7] {
+		c.weekdays[0] = true
+	}
+	return c, nil
+}
+
+// parseField parses list of values, ranges and steps: "*", "5", "1-5", "*/15", "1-10/2", "1,15"
+func parseField(field string, minValue int, maxValue int) ([]int, error) {
+	set := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if rangePart, stepPart, ok := strings.Cut(part, "/"); ok {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
+			}
+			part = rangePart
+		}
+
+		low, high := minValue, maxValue
+		if part != "*" {
+			lowPart, highPart, isRange := strings.Cut(part, "-")
+			var err error
+			low, err = strconv.Atoi(lowPart)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", lowPart)
+			}
+			high = low
+			if isRange {
+				high, err = strconv.Atoi(highPart)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value %q", highPart)
+				}
+			} else if step > 1 {
+				high = maxValue
+			}
+		}
+		if low < minValue || high > maxValue || low > high {
+			return nil, fmt.Errorf("value out of range %d-%d: %q", minValue, maxValue, part)
+		}
+
+		for v := low; v <= high; v += step {
+			set[v] = true
+		}
+	}
+
+	values := make([]int, 0, len(set))
+	for v := minValue; v <= maxValue; v++ {
+		if set[v] {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+func toSet(values []int) map[int]bool {
+	set := make(map[int]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+func (c *cron) matchDay(day time.Time) bool {
+	if !c.months[int(day.Month())] {
+		return false
+	}
+
+	dayMatch := c.days[day.Day()]
+	weekdayMatch := c.weekdays[int(day.Weekday())]
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekdayMatch
+	case c.anyWeekday:
+		return dayMatch
+	default:
+		return dayMatch || weekdayMatch
+	}
+}
+
+func (c *cron) Next(t time.Time) (next time.Time, ok bool) {
+	t = t.In(c.loc)
+	dayIterator(t, func(day time.Time) bool {
+		if !c.matchDay(day) {
+			return false
+		}
+		next, ok = atTime(day, c.hours, c.minutes, t)
+		return ok
+	})
+	return next, ok
+}
+
+func (c *cron) String() string {
+	return c.expr
+}
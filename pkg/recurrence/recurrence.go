@@ -0,0 +1,69 @@
+package recurrence
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// searchLimit - max period in which next occurrence is searched, rules without occurrences in it are invalid
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+var ErrNoOccurrence = errors.New("recurrence rule has no occurrences")
+
+// Rule expands recurrence into concrete times
+type Rule interface {
+	// Next returns first occurrence strictly after t, ok is false if series is finished
+	Next(t time.Time) (next time.Time, ok bool)
+	String() string
+}
+
+// Parse parses cron expression (minute hour day-of-month month day-of-week)
+// or iCal RRULE subset (FREQ, INTERVAL, BYDAY, BYMONTHDAY, BYMONTH, BYHOUR, BYMINUTE, COUNT, UNTIL).
+// start is first occurrence of series, RRULE takes default time and day from it.
+func Parse(expr string, start time.Time) (Rule, error) {
+	expr = strings.TrimSpace(expr)
+
+	var (
+		rule Rule
+		err  error
+	)
+	upper := strings.ToUpper(expr)
+	if strings.HasPrefix(upper, "RRULE:") || strings.HasPrefix(upper, "FREQ=") {
+		rule, err = parseRRule(strings.TrimPrefix(upper, "RRULE:"), start)
+	} else {
+		rule, err = parseCron(expr, start.Location())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := rule.Next(start.Add(-time.Minute)); !ok {
+		return nil, ErrNoOccurrence
+	}
+	return rule, nil
+}
+
+// dayIterator calls fn for every day from day of t during searchLimit until fn returns true
+func dayIterator(t time.Time, fn func(day time.Time) bool) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	end := t.Add(searchLimit)
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if fn(day) {
+			return
+		}
+	}
+}
+
+// atTime returns first time of day built from hours and minutes which is after t
+func atTime(day time.Time, hours []int, minutes []int, t time.Time) (time.Time, bool) {
+	for _, hour := range hours {
+		for _, minute := range minutes {
+			candidate := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+			if candidate.After(t) {
+				return candidate, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
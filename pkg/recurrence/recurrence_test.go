@@ -0,0 +1,165 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	// понедельник
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, msk)
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  []time.Time
+	}{
+		{
+			name:  "cron every monday",
+			expr:  "0 10 * * 1",
+			after: start,
+			want: []time.Time{
+				time.Date(2024, 1, 8, 10, 0, 0, 0, msk),
+				time.Date(2024, 1, 15, 10, 0, 0, 0, msk),
+			},
+		},
+		{
+			name:  "cron first day of month",
+			expr:  "30 9 1 * *",
+			after: start,
+			want: []time.Time{
+				time.Date(2024, 2, 1, 9, 30, 0, 0, msk),
+				time.Date(2024, 3, 1, 9, 30, 0, 0, msk),
+			},
+		},
+		{
+			name:  "cron step and list",
+			expr:  "*/30 9,18 * * 1-5",
+			after: time.Date(2024, 1, 5, 18, 30, 0, 0, msk),
+			want: []time.Time{
+				time.Date(2024, 1, 8, 9, 0, 0, 0, msk),
+				time.Date(2024, 1, 8, 9, 30, 0, 0, msk),
+				time.Date(2024, 1, 8, 18, 0, 0, 0, msk),
+			},
+		},
+		{
+			name:  "rrule weekly takes time from start",
+			expr:  "RRULE:FREQ=WEEKLY;BYDAY=MO,TH",
+			after: start,
+			want: []time.Time{
+				time.Date(2024, 1, 4, 10, 0, 0, 0, msk),
+				time.Date(2024, 1, 8, 10, 0, 0, 0, msk),
+			},
+		},
+		{
+			name:  "rrule every second week",
+			expr:  "FREQ=WEEKLY;INTERVAL=2",
+			after: start.Add(-time.Minute),
+			want: []time.Time{
+				start,
+				time.Date(2024, 1, 15, 10, 0, 0, 0, msk),
+			},
+		},
+		{
+			name:  "rrule last friday of month",
+			expr:  "FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=19;BYMINUTE=0",
+			after: start,
+			want: []time.Time{
+				time.Date(2024, 1, 26, 19, 0, 0, 0, msk),
+				time.Date(2024, 2, 23, 19, 0, 0, 0, msk),
+			},
+		},
+		{
+			name:  "rrule last day of month",
+			expr:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			after: start,
+			want: []time.Time{
+				time.Date(2024, 1, 31, 10, 0, 0, 0, msk),
+				time.Date(2024, 2, 29, 10, 0, 0, 0, msk),
+			},
+		},
+		{
+			name:  "rrule count",
+			expr:  "FREQ=DAILY;COUNT=2",
+			after: start,
+			want: []time.Time{
+				time.Date(2024, 1, 2, 10, 0, 0, 0, msk),
+			},
+		},
+		{
+			name:  "rrule until",
+			expr:  "FREQ=DAILY;UNTIL=20240102",
+			after: start,
+			want: []time.Time{
+				time.Date(2024, 1, 2, 10, 0, 0, 0, msk),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.expr, start)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			after := tt.after
+			for _, want := range tt.want {
+				got, ok := rule.Next(after)
+				if !ok || !got.Equal(want) {
+					t.Fatalf("Next(%v) = %v, %v; want %v", after, got, ok, want)
+				}
+				after = got
+			}
+			if len(tt.want) == 1 {
+				if got, ok := rule.Next(after); ok {
+					t.Fatalf("series must be finished, got %v", got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	for _, expr := range []string{
+		"0 10 * *",
+		"60 10 * * *",
+		"0 10 31 2 *",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"BYDAY=MO",
+	} {
+		if _, err := Parse(expr, start); err == nil {
+			t.Errorf("Parse(%q) must fail", expr)
+		}
+	}
+}
+
+func TestNextKeepsLocalTimeAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	// перевод часов 31 марта 2024
+	start := time.Date(2024, 3, 30, 10, 0, 0, 0, berlin)
+
+	for _, expr := range []string{"0 10 * * *", "FREQ=DAILY"} {
+		rule, err := Parse(expr, start)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", expr, err)
+		}
+
+		got, ok := rule.Next(start)
+		want := time.Date(2024, 3, 31, 10, 0, 0, 0, berlin)
+		if !ok || !got.Equal(want) {
+			t.Fatalf("%q: Next() = %v; want %v", expr, got, want)
+		}
+		if got.Sub(start) != 23*time.Hour {
+			t.Fatalf("%q: occurrence must stay at 10:00 local time, got %v", expr, got.UTC())
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package recurrence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type frequency string
+
+const (
+	daily   frequency = "DAILY"
+	weekly  frequency = "WEEKLY"
+	monthly frequency = "MONTHLY"
+	yearly  frequency = "YEARLY"
+)
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// byDay - weekday with optional position in month: 1MO - first monday, -1FR - last friday
+type byDay struct {
+	n       int
+	weekday time.Weekday
+}
+
+type rrule struct {
+	expr  string
+	start time.Time
+
+	freq       frequency
+	interval   int
+	byDay      []byDay
+	byMonthDay []int
+	byMonth    map[int]bool
+	hours      []int
+	minutes    []int
+	count      int
+	until      *time.Time
+}
+
+func parseRRule(expr string, start time.Time) (Rule, error) {
+	r := &rrule{
+		expr:     expr,
+		start:    start.Truncate(time.Minute),
+		interval: 1,
+		hours:    []int{start.Hour()},
+		minutes:  []int{start.Minute()},
+	}
+
+	for _, part := range strings.Split(expr, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.freq = frequency(value)
+			if r.freq != daily && r.freq != weekly && r.freq != monthly && r.freq != yearly {
+				return nil, fmt.Errorf("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err == nil && r.interval <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(value)
+			if err == nil && r.count <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "UNTIL":
+			var until time.Time
+			until, err = parseUntil(value, start.Location())
+			r.until = &until
+		case "BYDAY":
+			r.byDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseInts(value, -31, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseInts(value, 1, 12)
+			r.byMonth = toSet(months)
+		case "BYHOUR":
+			r.hours, err = parseField(value, 0, 23)
+		case "BYMINUTE":
+			r.minutes, err = parseField(value, 0, 59)
+		default:
+			return nil, fmt.Errorf("unsupported rule part %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	if r.freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if r.count > 0 && r.until != nil {
+		return nil, fmt.Errorf("COUNT and UNTIL can not be used together")
+	}
+	r.setDefaults()
+
+	return r, nil
+}
+
+// setDefaults takes missing day of series from start as RFC 5545 does
+func (r *rrule) setDefaults() {
+	switch r.freq {
+	case weekly:
+		if len(r.byDay) == 0 {
+			r.byDay = []byDay{{weekday: r.start.Weekday()}}
+		}
+	case monthly:
+		if len(r.byDay) == 0 && len(r.byMonthDay) == 0 {
+			r.byMonthDay = []int{r.start.Day()}
+		}
+	case yearly:
+		if len(r.byMonth) == 0 {
+			r.byMonth = map[int]bool{int(r.start.Month()): true}
+		}
+		if len(r.byDay) == 0 && len(r.byMonthDay) == 0 {
+			r.byMonthDay = []int{r.start.Day()}
+		}
+	}
+}
+
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			if strings.HasSuffix(value, "Z") {
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+			}
+			if layout == "20060102" {
+				t = t.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+func parseByDay(value string) ([]byDay, error) {
+	days := make([]byDay, 0)
+	for _, part := range strings.Split(value, ",") {
+		if len(part) < 2 {
+			return nil, fmt.Errorf("invalid day %q", part)
+		}
+		weekday, ok := weekdays[part[len(part)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", part)
+		}
+
+		day := byDay{weekday: weekday}
+		if prefix := part[:len(part)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid day position %q", part)
+			}
+			day.n = n
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func parseInts(value string, minValue int, maxValue int) ([]int, error) {
+	values := make([]int, 0)
+	for _, part := range strings.Split(value, ",") {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < minValue || v > maxValue || v == 0 {
+			return nil, fmt.Errorf("invalid value %q", part)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func daysIn(day time.Time) int {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+}
+
+func dayNumber(t time.Time) int {
+	return int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60))
+}
+
+// inPeriod checks that day belongs to period of series according to INTERVAL
+func (r *rrule) inPeriod(day time.Time) bool {
+	var diff int
+	switch r.freq {
+	case daily:
+		diff = dayNumber(day) - dayNumber(r.start)
+	case weekly:
+		// недели начинаются с понедельника
+		monday := func(t time.Time) int { return dayNumber(t) - (int(t.Weekday())+6)%7 }
+		diff = (monday(day) - monday(r.start)) / 7
+	case monthly:
+		diff = (day.Year()-r.start.Year())*12 + int(day.Month()) - int(r.start.Month())
+	case yearly:
+		diff = day.Year() - r.start.Year()
+	}
+	return diff >= 0 && diff%r.interval == 0
+}
+
+func (r *rrule) matchDay(day time.Time) bool {
+	if !r.inPeriod(day) {
+		return false
+	}
+	if len(r.byMonth) > 0 && !r.byMonth[int(day.Month())] {
+		return false
+	}
+
+	if len(r.byMonthDay) > 0 {
+		var match bool
+		for _, d := range r.byMonthDay {
+			if d == day.Day() || d < 0 && daysIn(day)+d+1 == day.Day() {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
+	if len(r.byDay) > 0 {
+		var match bool
+		for _, d := range r.byDay {
+			if d.weekday != day.Weekday() {
+				continue
+			}
+			if d.n == 0 || r.freq == daily || r.freq == weekly ||
+				d.n > 0 && (day.Day()-1)/7+1 == d.n ||
+				d.n < 0 && (daysIn(day)-day.Day())/7+1 == -d.n {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+// next returns first occurrence after t ignoring COUNT and UNTIL
+func (r *rrule) next(t time.Time) (next time.Time, ok bool) {
+	t = t.In(r.start.Location())
+	if t.Before(r.start) {
+		t = r.start.Add(-time.Nanosecond)
+	}
+
+	dayIterator(t, func(day time.Time) bool {
+		if !r.matchDay(day) {
+			return false
+		}
+		next, ok = atTime(day, r.hours, r.minutes, t)
+		return ok
+	})
+	return next, ok
+}
+
+func (r *rrule) Next(t time.Time) (time.Time, bool) {
+	if r.count > 0 {
+		// номер вхождения можно узнать только перебором с начала серии
+		occurrence := r.start.Add(-time.Nanosecond)
+		for i := 0; i < r.count; i++ {
+			var ok bool
+			occurrence, ok = r.next(occurrence)
+			if !ok {
+				return time.Time{}, false
+			}
+			if occurrence.After(t) {
+				return occurrence, true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	next, ok := r.next(t)
+	if !ok || r.until != nil && next.After(*r.until) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+func (r *rrule) String() string {
+	return "RRULE:" + r.expr
+}
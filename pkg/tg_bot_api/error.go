@@ -0,0 +1,46 @@
+package tg_bot_api
+
+import (
+	"context"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Retryable reports whether failed telegram request can be repeated.
+// Flood-wait (429) and server errors are retryable, retryAfter is returned for flood-wait.
+// Of errors without telegram error code only network errors and timeouts are retryable.
+// Other errors (bad request, forbidden, invalid parameters, cancellation, etc.) are permanent.
+func Retryable(err error) (retry bool, retryAfter time.Duration) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false, 0
+	}
+
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded), 0
+	}
+
+	switch {
+	case tgErr.Code == http.StatusTooManyRequests:
+		return true, time.Duration(tgErr.RetryAfter) * time.Second
+	case tgErr.Code >= http.StatusInternalServerError:
+		return true, 0
+	default:
+		return false, 0
+	}
+}
+
+// IsMessageNotFound reports whether message was already deleted from chat
+func IsMessageNotFound(err error) bool {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return false
+	}
+	return tgErr.Code == http.StatusBadRequest && strings.Contains(tgErr.Message, "message to delete not found")
+}
@@ -0,0 +1,47 @@
+package tg_bot_api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"io"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		retry      bool
+		retryAfter time.Duration
+	}{
+		{name: "nil", err: nil},
+		{name: "network", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("i/o timeout")}, retry: true},
+		{name: "request", err: &url.Error{Op: "Post", URL: "https://api.telegram.org", Err: io.ErrUnexpectedEOF}, retry: true},
+		{name: "unexpected eof", err: fmt.Errorf("read: %w", io.ErrUnexpectedEOF), retry: true},
+		{name: "cancelled", err: &url.Error{Op: "Post", URL: "https://api.telegram.org", Err: context.Canceled}},
+		{name: "invalid parameters", err: errors.New("json: unsupported type: func()")},
+		{
+			name:       "flood wait",
+			err:        &tgbotapi.Error{Code: 429, Message: "Too Many Requests", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 7}},
+			retry:      true,
+			retryAfter: 7 * time.Second,
+		},
+		{name: "wrapped server error", err: fmt.Errorf("send: %w", &tgbotapi.Error{Code: 502}), retry: true},
+		{name: "bad request", err: &tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"}},
+		{name: "forbidden", err: &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was kicked"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retry, retryAfter := Retryable(tt.err)
+			if retry != tt.retry || retryAfter != tt.retryAfter {
+				t.Errorf("Retryable() = %v, %v; want %v, %v", retry, retryAfter, tt.retry, tt.retryAfter)
+			}
+		})
+	}
+}
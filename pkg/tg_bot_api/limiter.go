@@ -0,0 +1,230 @@
+package tg_bot_api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type Priority int
+
+const (
+	// PriorityLow - запросы админ-панели, уступают запланированным публикациям
+	PriorityLow Priority = iota
+	// PriorityHigh - отправка и удаление запланированных публикаций
+	PriorityHigh
+)
+
+// Telegram limits: about 30 messages per second for bot, 20 messages per minute in one group or channel,
+// about 1 message per second in private chat
+const (
+	globalRate   = 30
+	globalBurst  = 30
+	groupRate    = 20.0 / 60
+	groupBurst   = 20
+	privateRate  = 1
+	privateBurst = 3
+
+	// maxChats - after this count of chat buckets unused buckets are removed
+	maxChats = 1024
+)
+
+type Limiter interface {
+	// Wait blocks until request to chat is allowed. chatID 0 means unknown chat, only global limit is applied.
+	Wait(ctx context.Context, chatID int64, priority Priority) error
+	// Pause forbids requests to chat for duration, used after telegram flood-wait
+	Pause(chatID int64, d time.Duration)
+}
+
+type bucket struct {
+	tokens      float64
+	burst       float64
+	rate        float64 // tokens per second
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func newBucket(rate float64, burst float64, now time.Time) *bucket {
+	return &bucket{
+		tokens: burst,
+		burst:  burst,
+		rate:   rate,
+		last:   now,
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// delay returns time until one token is available
+func (b *bucket) delay(now time.Time) time.Duration {
+	b.refill(now)
+
+	var d time.Duration
+	if b.tokens < 1 {
+		d = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	}
+	return max(d, b.pausedUntil.Sub(now))
+}
+
+func (b *bucket) take() {
+	b.tokens--
+}
+
+func (b *bucket) unused(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.burst && !b.pausedUntil.After(now)
+}
+
+type limiter struct {
+	mu     sync.Mutex
+	global *bucket
+	chats  map[int64]*bucket
+
+	// highWaiting - high priority requests waiting for global limit, low priority requests wait until it is zero
+	highWaiting int
+	// changed is closed when highWaiting becomes zero
+	changed chan struct{}
+
+	now func() time.Time
+}
+
+func NewLimiter() Limiter {
+	return newLimiter(time.Now)
+}
+
+func newLimiter(now func() time.Time) *limiter {
+	return &limiter{
+		global:  newBucket(globalRate, globalBurst, now()),
+		chats:   make(map[int64]*bucket),
+		changed: make(chan struct{}),
+		now:     now,
+	}
+}
+
+func (l *limiter) chat(chatID int64, now time.Time) *bucket {
+	if chatID == 0 {
+		return nil
+	}
+
+	b, ok := l.chats[chatID]
+	if !ok {
+		if len(l.chats) >= maxChats {
+			for id, chat := range l.chats {
+				if chat.unused(now) {
+					delete(l.chats, id)
+				}
+			}
+		}
+
+		// отрицательный id у групп и каналов
+		if chatID < 0 {
+			b = newBucket(groupRate, groupBurst, now)
+		} else {
+			b = newBucket(privateRate, privateBurst, now)
+		}
+		l.chats[chatID] = b
+	}
+	return b
+}
+
+// reserve takes tokens if request is allowed now, otherwise returns delay of global and chat limits
+func (l *limiter) reserve(chatID int64, priority Priority) (globalDelay time.Duration, chatDelay time.Duration, ok bool) {
+	now := l.now()
+	chat := l.chat(chatID, now)
+
+	globalDelay = l.global.delay(now)
+	if chat != nil {
+		chatDelay = chat.delay(now)
+	}
+	if globalDelay > 0 || chatDelay > 0 {
+		return globalDelay, chatDelay, false
+	}
+	if priority == PriorityLow && l.highWaiting > 0 {
+		return 0, 0, false
+	}
+
+	l.global.take()
+	if chat != nil {
+		chat.take()
+	}
+	return 0, 0, true
+}
+
+func (l *limiter) Wait(ctx context.Context, chatID int64, priority Priority) error {
+	// high priority request blocks low ones only while it waits for global limit,
+	// waiting for own chat limit must not stop admin panel
+	var counted bool
+	setCounted := func(value bool) {
+		if counted == value {
+			return
+		}
+		counted = value
+		if value {
+			l.highWaiting++
+			return
+		}
+		l.highWaiting--
+		if l.highWaiting == 0 {
+			close(l.changed)
+			l.changed = make(chan struct{})
+		}
+	}
+	defer func() {
+		l.mu.Lock()
+		setCounted(false)
+		l.mu.Unlock()
+	}()
+
+	for {
+		l.mu.Lock()
+		globalDelay, chatDelay, ok := l.reserve(chatID, priority)
+		if ok {
+			setCounted(false)
+			l.mu.Unlock()
+			return nil
+		}
+		if priority == PriorityHigh {
+			setCounted(chatDelay == 0)
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		d := max(globalDelay, chatDelay)
+		if d == 0 {
+			// ожидание завершения запросов с высоким приоритетом
+			select {
+			case <-changed:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *limiter) Pause(chatID int64, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b := l.chat(chatID, now)
+	if b == nil {
+		b = l.global
+	}
+	if until := now.Add(d); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
@@ -0,0 +1,70 @@
+package tg_bot_api
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterReserve(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newLimiter(func() time.Time { return now })
+
+	const channelID = -100123
+	for i := 0; i < groupBurst; i++ {
+		if _, _, ok := l.reserve(channelID, PriorityHigh); !ok {
+			t.Fatalf("request %d to channel must be allowed", i)
+		}
+	}
+
+	globalDelay, chatDelay, ok := l.reserve(channelID, PriorityHigh)
+	if ok || globalDelay != 0 || chatDelay != 3*time.Second {
+		t.Fatalf("reserve() = %v, %v, %v; want chat delay 3s", globalDelay, chatDelay, ok)
+	}
+
+	// остальные чаты ограничены только общим лимитом
+	for i := 0; i < globalBurst-groupBurst; i++ {
+		if _, _, ok := l.reserve(int64(i+1), PriorityLow); !ok {
+			t.Fatalf("request %d to private chat must be allowed", i)
+		}
+	}
+	if globalDelay, _, ok := l.reserve(42, PriorityLow); ok || globalDelay == 0 {
+		t.Fatalf("global limit must be exceeded, got delay %v", globalDelay)
+	}
+
+	now = now.Add(3 * time.Second)
+	if _, _, ok := l.reserve(channelID, PriorityHigh); !ok {
+		t.Fatal("channel token must be refilled")
+	}
+}
+
+func TestLimiterPause(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newLimiter(func() time.Time { return now })
+
+	l.Pause(7, 10*time.Second)
+	if _, chatDelay, ok := l.reserve(7, PriorityHigh); ok || chatDelay != 10*time.Second {
+		t.Fatalf("paused chat: delay %v, ok %v", chatDelay, ok)
+	}
+	if _, _, ok := l.reserve(8, PriorityHigh); !ok {
+		t.Fatal("pause must affect only one chat")
+	}
+}
+
+func TestLimiterLowPriorityWaitsHigh(t *testing.T) {
+	l := newLimiter(time.Now)
+	l.highWaiting = 1
+
+	if _, _, ok := l.reserve(1, PriorityLow); ok {
+		t.Fatal("low priority request must wait for high priority ones")
+	}
+	if _, _, ok := l.reserve(2, PriorityHigh); !ok {
+		t.Fatal("high priority request must be allowed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, 1, PriorityLow); err == nil {
+		t.Fatal("low priority request must be blocked")
+	}
+}
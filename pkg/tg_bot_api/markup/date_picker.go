@@ -0,0 +1,192 @@
+package markup
+
+import (
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Действия календаря, данные кнопок: dp_{action}_{kind}_{id}_{date}_{minutes}
+const (
+	DatePickerMonth = "month"
+	DatePickerDay   = "day"
+	DatePickerSet   = "set"
+	// DatePickerNone - данные кнопок, нажатие на которые ничего не делает
+	DatePickerNone = "dp_none"
+)
+
+// Виды дат публикации, выбираемых календарем
+const (
+	PickSentDate = iota + 1
+	PickDeleteDate
+)
+
+const minutesInDay = 24 * 60
+
+var monthNames = [12]string{"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь",
+	"Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь"}
+
+var weekdayTitles = [7]string{"Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"}
+
+// DatePicker - выбор даты и времени на inline-кнопках: сетка дней месяца, затем шаги часов и минут.
+// Состояние целиком передается в данных кнопок, поэтому компонент ничего не хранит между нажатиями
+type DatePicker struct {
+	// Kind, ID - что выбирается и для какого объекта, возвращаются вызывающему коду в каждом нажатии
+	Kind int
+	ID   int
+	// Today - текущий день в часовом поясе администратора, более ранние дни недоступны
+	Today time.Time
+	// Disabled - дополнительно недоступные дни, nil разрешает все дни начиная с Today
+	Disabled func(day time.Time) bool
+	// Back - данные кнопки отмены
+	Back string
+}
+
+// DatePickerState - нажатие кнопки календаря
+type DatePickerState struct {
+	Action string
+	Kind   int
+	ID     int
+	// Date - день в виде 20240901, для перехода по месяцам - первый день месяца
+	Date int
+	// Minutes - выбранное время в минутах от начала дня
+	Minutes int
+}
+
+// ParseDatePicker parses callback data of date picker
+func ParseDatePicker(data string) (DatePickerState, bool) {
+	parts := strings.Split(data, "_")
+	if len(parts) != 6 || parts[0] != "dp" {
+		return DatePickerState{}, false
+	}
+
+	var (
+		state = DatePickerState{Action: parts[1]}
+		err   error
+	)
+	for i, value := range []*int{&state.Kind, &state.ID, &state.Date, &state.Minutes} {
+		if *value, err = strconv.Atoi(parts[i+2]); err != nil {
+			return DatePickerState{}, false
+		}
+	}
+	if _, ok := entity.ParseDateNumber(state.Date, time.UTC); !ok || state.Minutes < 0 || state.Minutes >= minutesInDay {
+		return DatePickerState{}, false
+	}
+	return state, true
+}
+
+// Day returns start of chosen day in location loc
+func (s DatePickerState) Day(loc *time.Location) time.Time {
+	day, _ := entity.ParseDateNumber(s.Date, loc)
+	return day
+}
+
+// Time returns chosen day and time in location loc
+func (s DatePickerState) Time(loc *time.Location) time.Time {
+	day := s.Day(loc)
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, s.Minutes, 0, 0, loc)
+}
+
+func (p DatePicker) data(action string, day time.Time, minutes int) string {
+	return fmt.Sprintf("dp_%s_%d_%d_%d_%d", action, p.Kind, p.ID, entity.DateNumber(day), minutes)
+}
+
+func (p DatePicker) disabled(day time.Time) bool {
+	today := time.Date(p.Today.Year(), p.Today.Month(), p.Today.Day(), 0, 0, 0, 0, day.Location())
+	return day.Before(today) || (p.Disabled != nil && p.Disabled(day))
+}
+
+// Month - сетка дней месяца month, minutes передается дальше в выбор времени
+func (p DatePicker) Month(month time.Time, minutes int) tgbotapi.InlineKeyboardMarkup {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	none := func(text string) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(text, DatePickerNone)
+	}
+
+	// в прошлые месяцы не переходим, в них все дни недоступны
+	prev := none(" ")
+	if first.After(time.Date(p.Today.Year(), p.Today.Month(), 1, 0, 0, 0, 0, month.Location())) {
+		prev = tgbotapi.NewInlineKeyboardButtonData("‹", p.data(DatePickerMonth, first.AddDate(0, -1, 0), minutes))
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			prev,
+			none(fmt.Sprintf("%s %d", monthNames[first.Month()-1], first.Year())),
+			tgbotapi.NewInlineKeyboardButtonData("›", p.data(DatePickerMonth, first.AddDate(0, 1, 0), minutes))),
+	}
+
+	header := make([]tgbotapi.InlineKeyboardButton, 0, len(weekdayTitles))
+	for _, title := range weekdayTitles {
+		header = append(header, none(title))
+	}
+	rows = append(rows, header)
+
+	// неделя начинается с понедельника
+	offset := (int(first.Weekday()) + 6) % 7
+	week := make([]tgbotapi.InlineKeyboardButton, 0, 7)
+	for i := 0; i < offset; i++ {
+		week = append(week, none(" "))
+	}
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		if p.disabled(day) {
+			week = append(week, none("·"))
+		} else {
+			week = append(week, tgbotapi.NewInlineKeyboardButtonData(strconv.Itoa(day.Day()), p.data(DatePickerDay, day, minutes)))
+		}
+		if len(week) == 7 {
+			rows = append(rows, week)
+			week = make([]tgbotapi.InlineKeyboardButton, 0, 7)
+		}
+	}
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, none(" "))
+		}
+		rows = append(rows, week)
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Отмена", p.Back)))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// Clock - выбор времени выбранного дня шагами часов и минут, время не переходит на соседние дни
+func (p DatePicker) Clock(day time.Time, minutes int) tgbotapi.InlineKeyboardMarkup {
+	step := func(title string, shift int) tgbotapi.InlineKeyboardButton {
+		value := ((minutes+shift)%minutesInDay + minutesInDay) % minutesInDay
+		return tgbotapi.NewInlineKeyboardButtonData(title, p.data(DatePickerDay, day, value))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			step("−1 ч", -60),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%02d:%02d", minutes/60, minutes%60), DatePickerNone),
+			step("+1 ч", 60)),
+		tgbotapi.NewInlineKeyboardRow(
+			step("−15 мин", -15),
+			step("−5 мин", -5),
+			step("+5 мин", 5),
+			step("+15 мин", 15)),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Другой день", p.data(DatePickerMonth, day, minutes))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Сохранить", p.data(DatePickerSet, day, minutes))),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Отмена", p.Back)),
+	)
+}
+
+// Confirm - подтверждение даты, введенной сообщением, или переход к настройке ее времени
+func (p DatePicker) Confirm(date time.Time) tgbotapi.InlineKeyboardMarkup {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	minutes := date.Hour()*60 + date.Minute()
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Подтвердить", p.data(DatePickerSet, day, minutes))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Настроить время", p.data(DatePickerDay, day, minutes))),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Отмена", p.Back)),
+	)
+}
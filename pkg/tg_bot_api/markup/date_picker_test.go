@@ -0,0 +1,67 @@
+package markup
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDatePickerMonth(t *testing.T) {
+	picker := DatePicker{
+		Kind:  1,
+		ID:    42,
+		Today: time.Date(2024, 9, 10, 15, 0, 0, 0, time.UTC),
+		// воскресенья недоступны
+		Disabled: func(day time.Time) bool { return day.Weekday() == time.Sunday },
+		Back:     "publication_get_42",
+	}
+
+	keyboard := picker.Month(time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), 630)
+	// месяц, дни недели, 6 недель сентября 2024, отмена
+	if len(keyboard.InlineKeyboard) != 9 {
+		t.Fatalf("got %d rows; want 9", len(keyboard.InlineKeyboard))
+	}
+	if data := *keyboard.InlineKeyboard[0][0].CallbackData; data != DatePickerNone {
+		t.Errorf("previous month of current month must be disabled, got %q", data)
+	}
+
+	enabled := make(map[string]string)
+	for _, row := range keyboard.InlineKeyboard[2:8] {
+		if len(row) != 7 {
+			t.Fatalf("week has %d days; want 7", len(row))
+		}
+		for _, button := range row {
+			if *button.CallbackData != DatePickerNone {
+				enabled[button.Text] = *button.CallbackData
+			}
+		}
+	}
+	// с 10 по 30 сентября без трех воскресений
+	if len(enabled) != 18 {
+		t.Errorf("got %d enabled days; want 18: %v", len(enabled), enabled)
+	}
+	if data := enabled["11"]; data != "dp_day_1_42_20240911_630" {
+		t.Errorf("day 11 data = %q", data)
+	}
+	for _, day := range []string{"9", "15"} {
+		if _, ok := enabled[day]; ok {
+			t.Errorf("day %s must be disabled", day)
+		}
+	}
+}
+
+func TestParseDatePicker(t *testing.T) {
+	state, ok := ParseDatePicker("dp_set_1_42_20240911_630")
+	if !ok {
+		t.Fatal("valid data is not parsed")
+	}
+	loc := time.FixedZone("MSK", 3*60*60)
+	if got, want := state.Time(loc), time.Date(2024, 9, 11, 10, 30, 0, 0, loc); !got.Equal(want) || state.Action != DatePickerSet {
+		t.Errorf("ParseDatePicker() = %+v, time %v; want %v", state, got, want)
+	}
+
+	for _, data := range []string{DatePickerNone, "dp_set_1_42_20240911", "dp_set_1_42_20241311_630", "dp_set_1_42_20240911_1440"} {
+		if _, ok := ParseDatePicker(data); ok {
+			t.Errorf("ParseDatePicker(%q) must fail", data)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package markup
 
 import (
 	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
 	"github.com/Enthreeka/tg-posting-bot/pkg/tg_bot_api/button"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"time"
 )
 
 var (
@@ -12,6 +14,28 @@ var (
 			tgbotapi.NewInlineKeyboardButtonData("Управление ботом", "show_channels")),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("Управление пользователями", "user_setting")),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Статус кластера", "cluster_status")),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Мой часовой пояс", "user_timezone")),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Импорт публикаций", "import_start")),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Экспорт публикаций", "export_get_0")),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Поиск публикаций", "pub_search")),
+	)
+
+	PublicationSearch = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Новый поиск", "pub_search")),
+		tgbotapi.NewInlineKeyboardRow(button.MainMenuButton),
+	)
+
+	ImportRetry = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Загрузить исправленный файл", "import_start")),
+		tgbotapi.NewInlineKeyboardRow(button.MainMenuButton),
 	)
 
 	UserSetting = tgbotapi.NewInlineKeyboardMarkup(
@@ -43,6 +67,9 @@ var (
 
 	MainMenu = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(button.MainMenuButton))
 
+	CancelCommandGroup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Отмена выполнения", "group_list")))
+
 	//CancelCommand = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(button.CancelButton))
 )
 
@@ -51,6 +78,15 @@ func CancelCommandCreate(channelID int) tgbotapi.InlineKeyboardMarkup {
 		tgbotapi.NewInlineKeyboardButtonData("Отмена выполнения", fmt.Sprintf("cancel_create_%d", channelID))))
 }
 
+func ImportConfirm(importID int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Подтвердить импорт", fmt.Sprintf("import_confirm_%d", importID))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Отменить импорт", fmt.Sprintf("import_cancel_%d", importID))),
+	)
+}
+
 func CancelCommandPublication(publicationId int) tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
 		tgbotapi.NewInlineKeyboardButtonData("Отмена выполнения", fmt.Sprintf("cancel_update_%d", publicationId))))
@@ -64,6 +100,22 @@ func ChannelSetting(channelID int) tgbotapi.InlineKeyboardMarkup {
 			tgbotapi.NewInlineKeyboardButtonData("Управление публикациями", fmt.Sprintf("publication_update_%d", channelID))),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("Отменить публикацию", fmt.Sprintf("publication_cancel_%d", channelID))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Просроченные публикации", fmt.Sprintf("catchup_update_%d", channelID))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Максимальное опоздание", fmt.Sprintf("lateness_update_%d", channelID))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Попытки отправки", fmt.Sprintf("attempts_update_%d", channelID))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Часовой пояс", fmt.Sprintf("timezone_update_%d", channelID))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Окна публикаций", fmt.Sprintf("window_update_%d", channelID))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Периоды тишины", fmt.Sprintf("blackout_get_%d", channelID))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Слоты очереди", fmt.Sprintf("slots_update_%d", channelID))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Экспорт", fmt.Sprintf("export_get_%d", channelID))),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("Вернуться назад", "show_channels")),
 	)
@@ -76,16 +128,236 @@ func UpdatePublicationSettings(publicationId int) tgbotapi.InlineKeyboardMarkup
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("Изменить фотографию", fmt.Sprintf("image_update_%d", publicationId))),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Добавить текст к кнопке", fmt.Sprintf("buttontext_update_%d", publicationId))),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Добавить ссылку к кнопке", fmt.Sprintf("buttonlink_update_%d", publicationId))),
+			tgbotapi.NewInlineKeyboardButtonData("Кнопки", fmt.Sprintf("buttons_get_%d", publicationId)),
+			tgbotapi.NewInlineKeyboardButtonData("Параметры доставки", fmt.Sprintf("options_get_%d", publicationId))),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Изменить дату отправки", fmt.Sprintf("sent-date_update_%d", publicationId))),
+			tgbotapi.NewInlineKeyboardButtonData("Изменить дату отправки", fmt.Sprintf("sent-date_update_%d", publicationId)),
+			tgbotapi.NewInlineKeyboardButtonData("В очередь", fmt.Sprintf("queue_add_%d", publicationId))),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("Изменить дату удаления", fmt.Sprintf("delete-date_update_%d", publicationId))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Повторение", fmt.Sprintf("series_get_%d", publicationId))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Каналы публикации", fmt.Sprintf("target_get_%d", publicationId))),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("Предварительный просмотр", fmt.Sprintf("check_publication_%d", publicationId))),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("Вернуться назад", fmt.Sprintf("back_setting_%d", publicationId))),
 	)
 }
+
+// PublicationButtons - кнопки публикации по рядам: нажатие на кнопку удаляет ее, стрелки перемещают ряд
+func PublicationButtons(publicationId int, buttons entity.Buttons) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(buttons)+2)
+	for i, buttonsRow := range buttons {
+		row := make([]tgbotapi.InlineKeyboardButton, 0, len(buttonsRow)+2)
+		for j, b := range buttonsRow {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("✖ "+b.Text, fmt.Sprintf("button_del_%d_%d_%d", publicationId, i, j)))
+		}
+		if i > 0 {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("⬆", fmt.Sprintf("buttons_up_%d_%d", publicationId, i)))
+		}
+		if i < len(buttons)-1 {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("⬇", fmt.Sprintf("buttons_down_%d_%d", publicationId, i)))
+		}
+		rows = append(rows, row)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Добавить кнопки", fmt.Sprintf("buttons_add_%d", publicationId))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Вернуться назад", fmt.Sprintf("publication_get_%d", publicationId))))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// DeliveryOptions - переключатели параметров отправки публикации
+func DeliveryOptions(publicationId int, options entity.DeliveryOptions) tgbotapi.InlineKeyboardMarkup {
+	toggle := func(title string, value bool, option entity.DeliveryOption) tgbotapi.InlineKeyboardButton {
+		mark := "⬜ "
+		if value {
+			mark = "✅ "
+		}
+		return tgbotapi.NewInlineKeyboardButtonData(mark+title, fmt.Sprintf("opt_%s_%d", option, publicationId))
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(toggle("Без звука", options.Silent, entity.OptionSilent)),
+		tgbotapi.NewInlineKeyboardRow(toggle("Защита от пересылки", options.Protected, entity.OptionProtected)),
+		tgbotapi.NewInlineKeyboardRow(toggle("Спойлер на медиа", options.Spoiler, entity.OptionSpoiler)),
+		tgbotapi.NewInlineKeyboardRow(toggle("Разделять длинный текст", options.Split, entity.OptionSplit)),
+		tgbotapi.NewInlineKeyboardRow(toggle("Превью ссылок", options.Preview.Enabled, entity.OptionPreview)),
+	}
+	if options.Preview.Enabled {
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				toggle("Превью над текстом", options.Preview.AboveText, entity.OptionPreviewAbove),
+				tgbotapi.NewInlineKeyboardButtonData("Размер: "+options.Preview.Size.Title(),
+					fmt.Sprintf("opt_%s_%d", entity.OptionPreviewSize, publicationId))),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("Ссылка превью", fmt.Sprintf("opt_%s_%d", entity.OptionPreviewURL, publicationId))))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Вернуться назад", fmt.Sprintf("publication_get_%d", publicationId))))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func SeriesSetting(publicationId int, isSeries bool, paused bool) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Изменить правило", fmt.Sprintf("series_rule_%d", publicationId))),
+	}
+	if isSeries {
+		if paused {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("Возобновить серию", fmt.Sprintf("series_resume_%d", publicationId))))
+		} else {
+			rows = append(rows,
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData("Приостановить серию", fmt.Sprintf("series_pause_%d", publicationId))),
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData("Пропустить следующую отправку", fmt.Sprintf("series_skip_%d", publicationId))))
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Завершить серию", fmt.Sprintf("series_end_%d", publicationId))))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Вернуться назад", fmt.Sprintf("publication_get_%d", publicationId))))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// BlackoutSetting - кнопки удаления периодов тишины канала по порядку из списка
+func BlackoutSetting(channelID int, blackoutIDs []int) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Добавить период", fmt.Sprintf("blackout_add_%d", channelID))),
+	}
+	for i, id := range blackoutIDs {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("Удалить период %d", i+1), fmt.Sprintf("blackout_delete_%d", id))))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Вернуться назад", fmt.Sprintf("channel_get_%d", channelID))))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// WindowSlot - предложение перенести публикацию на ближайшее разрешенное время, slot - unix время
+func WindowSlot(publicationId int, slot int64, slotText string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Назначить на "+slotText, fmt.Sprintf("window_accept_%d_%d", publicationId, slot))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Вернуться к публикации", fmt.Sprintf("publication_get_%d", publicationId))),
+	)
+}
+
+// QueueGap - выбор после удаления или переноса публикации из очереди, freed - unix время освободившегося слота
+func QueueGap(channelID int, freed int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Сдвинуть очередь", fmt.Sprintf("queue_shift_%d_%d", channelID, freed))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Оставить пробел", fmt.Sprintf("queue_keep_%d", channelID))),
+	)
+}
+
+// OverduePublication - решение по задержанной задаче отправки: overdue_{action}_{publication_id}_{job_id}
+func OverduePublication(job entity.Job) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Отправить сейчас", fmt.Sprintf("overdue_send_%d_%d", job.PublicationID, job.ID)))}
+	// дата основного канала переносится календарем публикации, у дополнительного канала переносится только его задача
+	reschedule := fmt.Sprintf("sent-date_update_%d", job.PublicationID)
+	if job.TargetID != nil {
+		reschedule = fmt.Sprintf("overdue_reschedule_%d_%d", job.PublicationID, job.ID)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Перенести", reschedule)))
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Отменить отправку", fmt.Sprintf("overdue_drop_%d_%d", job.PublicationID, job.ID))))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// OverdueDelete - решение по задержанной задаче удаления: overduedel_{action}_{publication_id}_{job_id},
+// перенос даты удаления календарем публикации доступен только для задачи основного канала
+func OverdueDelete(job entity.Job) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Удалить сейчас", fmt.Sprintf("overduedel_send_%d_%d", job.PublicationID, job.ID)))}
+	if job.TargetID == nil && job.OccurrenceID == nil {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Перенести удаление", fmt.Sprintf("delete-date_update_%d", job.PublicationID))))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Оставить в канале", fmt.Sprintf("overduedel_drop_%d_%d", job.PublicationID, job.ID))))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// Export - панель выгрузки публикаций, фильтр передается в данных кнопок
+func Export(filter entity.ExportFilter) tgbotapi.InlineKeyboardMarkup {
+	file := func(title string, format entity.ExportFormat) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(title, fmt.Sprintf("%s_%d", filter.Data("file"), format))
+	}
+
+	back := button.MainMenuButton
+	if filter.ChannelID != 0 {
+		back = tgbotapi.NewInlineKeyboardButtonData("Вернуться назад", fmt.Sprintf("channel_get_%d", filter.ChannelID))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Период: "+filter.PeriodText(), filter.Data("range"))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Статус: "+filter.StatusText(), filter.Data("status"))),
+		tgbotapi.NewInlineKeyboardRow(
+			file("CSV", entity.ExportCSV),
+			file("JSON", entity.ExportJSON),
+			file("iCalendar", entity.ExportICS)),
+		tgbotapi.NewInlineKeyboardRow(back),
+	)
+}
+
+// ExportRange - выбор периода выгрузки, today - текущая дата в часовом поясе администратора
+func ExportRange(filter entity.ExportFilter, today time.Time) tgbotapi.InlineKeyboardMarkup {
+	period := func(title string, from *time.Time, to *time.Time) []tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(title, filter.WithPeriod(from, to).Data("panel")))
+	}
+	days := func(n int) *time.Time {
+		day := today.AddDate(0, 0, n)
+		return &day
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		period("За все время", nil, nil),
+		period("Сегодня", days(0), days(0)),
+		period("Следующие 7 дней", days(0), days(6)),
+		period("Следующие 30 дней", days(0), days(29)),
+		period("Прошедшие 30 дней", days(-29), days(0)),
+		period("Предстоящие", days(0), nil),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Указать период", filter.Data("input"))),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Вернуться назад", filter.Data("panel"))),
+	)
+}
+
+// ExportStatus - выбор статуса публикаций для выгрузки
+func ExportStatus(filter entity.ExportFilter) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(entity.ExportStatuses)+1)
+	for i := range entity.ExportStatuses {
+		option := filter
+		option.Status = i
+
+		mark := "⬜ "
+		if i == filter.Status {
+			mark = "✅ "
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(mark+option.StatusText(), option.Data("panel"))))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Вернуться назад", filter.Data("panel"))))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
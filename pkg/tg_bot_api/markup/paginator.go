@@ -0,0 +1,76 @@
+package markup
+
+import (
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// PageSize - число элементов на странице списков
+const PageSize = 8
+
+// Paginator - постраничный вывод списка. Номер страницы передается в данных кнопок,
+// Data строит данные кнопки по номеру страницы, нумерация с 0
+type Paginator struct {
+	Page  int
+	Total int
+	// Size - элементов на странице, 0 - PageSize
+	Size int
+	Data func(page int) string
+}
+
+func (p Paginator) size() int {
+	if p.Size <= 0 {
+		return PageSize
+	}
+	return p.Size
+}
+
+// Pages returns number of pages, empty list has one page
+func (p Paginator) Pages() int {
+	if p.Total <= 0 {
+		return 1
+	}
+	return (p.Total + p.size() - 1) / p.size()
+}
+
+// Current returns page within existing pages, list could become shorter since button was created
+func (p Paginator) Current() int {
+	return max(0, min(p.Page, p.Pages()-1))
+}
+
+// Offset returns number of elements before current page
+func (p Paginator) Offset() int {
+	return p.Current() * p.size()
+}
+
+// Limit returns max number of elements on page
+func (p Paginator) Limit() int {
+	return p.size()
+}
+
+// Bounds returns bounds of current page in slice of Total elements, end is exclusive
+func (p Paginator) Bounds() (int, int) {
+	from := p.Offset()
+	return min(from, max(p.Total, 0)), min(from+p.size(), max(p.Total, 0))
+}
+
+// Row - кнопки перехода на соседние страницы и номер текущей, nil для списка из одной страницы
+func (p Paginator) Row() []tgbotapi.InlineKeyboardButton {
+	pages := p.Pages()
+	if pages == 1 {
+		return nil
+	}
+
+	current := p.Current()
+	// номер страницы нажимается так же вхолостую, как неактивные кнопки календаря
+	prev, next := tgbotapi.NewInlineKeyboardButtonData(" ", DatePickerNone), tgbotapi.NewInlineKeyboardButtonData(" ", DatePickerNone)
+	if current > 0 {
+		prev = tgbotapi.NewInlineKeyboardButtonData("‹", p.Data(current-1))
+	}
+	if current < pages-1 {
+		next = tgbotapi.NewInlineKeyboardButtonData("›", p.Data(current+1))
+	}
+	return tgbotapi.NewInlineKeyboardRow(prev,
+		tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d/%d", current+1, pages), DatePickerNone),
+		next)
+}
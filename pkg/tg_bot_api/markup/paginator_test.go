@@ -0,0 +1,51 @@
+package markup
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPaginator(t *testing.T) {
+	data := func(page int) string { return fmt.Sprintf("list_%d", page) }
+
+	tests := []struct {
+		name     string
+		pager    Paginator
+		current  int
+		from, to int
+		row      []string
+	}{
+		{"empty", Paginator{Total: 0, Data: data}, 0, 0, 0, nil},
+		{"one page", Paginator{Total: PageSize, Data: data}, 0, 0, PageSize, nil},
+		{"first", Paginator{Total: 20, Size: 8, Data: data}, 0, 0, 8, []string{DatePickerNone, DatePickerNone, "list_1"}},
+		{"middle", Paginator{Page: 1, Total: 20, Size: 8, Data: data}, 1, 8, 16, []string{"list_0", DatePickerNone, "list_2"}},
+		{"last", Paginator{Page: 2, Total: 20, Size: 8, Data: data}, 2, 16, 20, []string{"list_1", DatePickerNone, DatePickerNone}},
+		// после удаления публикаций страница могла исчезнуть
+		{"beyond", Paginator{Page: 5, Total: 9, Size: 8, Data: data}, 1, 8, 9, []string{"list_0", DatePickerNone, DatePickerNone}},
+		{"negative", Paginator{Page: -1, Total: 9, Size: 8, Data: data}, 0, 0, 8, []string{DatePickerNone, DatePickerNone, "list_1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pager.Current(); got != tt.current {
+				t.Errorf("Current() = %d; want %d", got, tt.current)
+			}
+			if from, to := tt.pager.Bounds(); from != tt.from || to != tt.to {
+				t.Errorf("Bounds() = %d, %d; want %d, %d", from, to, tt.from, tt.to)
+			}
+
+			row := tt.pager.Row()
+			if len(row) != len(tt.row) {
+				t.Fatalf("Row() has %d buttons; want %d", len(row), len(tt.row))
+			}
+			for i, button := range row {
+				if *button.CallbackData != tt.row[i] {
+					t.Errorf("button %d data = %q; want %q", i, *button.CallbackData, tt.row[i])
+				}
+			}
+		})
+	}
+
+	if got := (Paginator{Page: 1, Total: 20, Size: 8, Data: data}).Row()[1].Text; got != "2/3" {
+		t.Errorf("page title = %q; want 2/3", got)
+	}
+}
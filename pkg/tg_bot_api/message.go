@@ -1,31 +1,77 @@
 package tg_bot_api
 
 import (
+	"context"
 	"github.com/Enthreeka/tg-posting-bot/internal/entity"
 	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
-	"strings"
 )
 
 type Message interface {
 	SendNewMessage(chatID int64, markup *tgbotapi.InlineKeyboardMarkup, text string) (int, error)
 	SendEditMessage(chatID int64, messageID int, markup *tgbotapi.InlineKeyboardMarkup, text string) (int, error)
 	SendDocument(chatID int64, fileName string, fileIDBytes *[]byte, text string) (int, error)
-	SendMessageToUser(chatID int64, publication *entity.Publication) (int, error)
+	SendMessageToUser(chatID int64, publication *entity.Publication) ([]int, error)
 	SendMessageToChannel(username string, publication *entity.Publication) error
+	StoreMessage(fromChatID int64, messageID int) (int, error)
+	StoreMessages(fromChatID int64, messageIDs []int) ([]int, error)
 	DeleteMessage(chatID int64, messageID int) error
+
+	// WithPriority returns Message which shares rate limiter but sends with another priority
+	WithPriority(priority Priority) Message
 }
 
 type TelegramMsg struct {
-	log *logger.Logger
-	bot *tgbotapi.BotAPI
+	log      *logger.Logger
+	bot      *tgbotapi.BotAPI
+	limiter  Limiter
+	priority Priority
+	// storageChatID - канал-хранилище черновиков, 0 - публикации собираются из полей
+	storageChatID int64
 }
 
-func NewMessageSetting(bot *tgbotapi.BotAPI, log *logger.Logger) *TelegramMsg {
+func NewMessageSetting(bot *tgbotapi.BotAPI, log *logger.Logger, limiter Limiter, storageChatID int64) *TelegramMsg {
 	return &TelegramMsg{
-		bot: bot,
-		log: log,
+		bot:           bot,
+		log:           log,
+		limiter:       limiter,
+		priority:      PriorityLow,
+		storageChatID: storageChatID,
+	}
+}
+
+func (t *TelegramMsg) WithPriority(priority Priority) Message {
+	msg := *t
+	msg.priority = priority
+	return &msg
+}
+
+// send waits for rate limiter before request, flood-wait from telegram pauses chat
+func (t *TelegramMsg) send(chatID int64, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	if err := t.limiter.Wait(context.Background(), chatID, t.priority); err != nil {
+		return tgbotapi.Message{}, err
+	}
+
+	msg, err := t.bot.Send(c)
+	t.pauseOnFlood(chatID, err)
+	return msg, err
+}
+
+func (t *TelegramMsg) request(chatID int64, c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	if err := t.limiter.Wait(context.Background(), chatID, t.priority); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.bot.Request(c)
+	t.pauseOnFlood(chatID, err)
+	return resp, err
+}
+
+func (t *TelegramMsg) pauseOnFlood(chatID int64, err error) {
+	if retry, retryAfter := Retryable(err); retry && retryAfter > 0 {
+		t.log.Info("flood wait for chat %d: %v", chatID, retryAfter)
+		t.limiter.Pause(chatID, retryAfter)
 	}
 }
 
@@ -37,7 +83,7 @@ func (t *TelegramMsg) SendNewMessage(chatID int64, markup *tgbotapi.InlineKeyboa
 		msg.ReplyMarkup = &markup
 	}
 
-	sendMsg, err := t.bot.Send(msg)
+	sendMsg, err := t.send(chatID, msg)
 	if err != nil {
 		t.log.Error("failed to send message", zap.Error(err))
 		return 0, err
@@ -54,7 +100,7 @@ func (t *TelegramMsg) SendEditMessage(chatID int64, messageID int, markup *tgbot
 		msg.ReplyMarkup = markup
 	}
 
-	sendMsg, err := t.bot.Send(msg)
+	sendMsg, err := t.send(chatID, msg)
 	if err != nil {
 		t.log.Error("failed to send msg: %v", err)
 		return 0, err
@@ -68,10 +114,9 @@ func (t *TelegramMsg) SendDocument(chatID int64, fileName string, fileIDBytes *[
 		Name:  fileName,
 		Bytes: *fileIDBytes,
 	})
-	msg.ParseMode = tgbotapi.ModeMarkdownV2
 	msg.Caption = text
 
-	sendMsg, err := t.bot.Send(msg)
+	sendMsg, err := t.send(chatID, msg)
 	if err != nil {
 		t.log.Error("failed to send msg: %v", err)
 		return 0, err
@@ -80,115 +125,47 @@ func (t *TelegramMsg) SendDocument(chatID int64, fileName string, fileIDBytes *[
 	return sendMsg.MessageID, nil
 }
 
-func (t *TelegramMsg) SendMessageToUser(chatID int64, publication *entity.Publication) (int, error) {
-	if publication.Image != nil {
-		publicationPhotoPhoto := tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(*publication.Image))
-		msg := tgbotapi.NewPhoto(chatID, publicationPhotoPhoto.Media)
-		buttonMarkup := buttonQualifier(publication.ButtonUrl, publication.ButtonText)
-		if buttonMarkup != nil {
-			msg.ReplyMarkup = &buttonMarkup
-		}
-		if publication.Text != "" {
-			msg.Caption = publication.Text
-		}
-		msg.ParseMode = tgbotapi.ModeMarkdownV2
-		sendMsg, err := t.bot.Send(msg)
-		if err != nil {
-			t.log.Error("failed to send message: %v", err)
-			return 0, err
-		}
-		return sendMsg.MessageID, nil
-	}
-
-	msg := tgbotapi.NewMessage(chatID, "")
-	msg.DisableWebPagePreview = true
-	msg.ParseMode = tgbotapi.ModeMarkdownV2
-	buttonMarkup := buttonQualifier(publication.ButtonUrl, publication.ButtonText)
-	if buttonMarkup != nil {
-		msg.ReplyMarkup = &buttonMarkup
-	}
-	if publication.Text != "" {
-		msg.Text = publication.Text
-	}
-
-	sendMsg, err := t.bot.Send(msg)
-	if err != nil {
-		t.log.Error("failed to send message", err)
-		return 0, err
-	}
-
-	return sendMsg.MessageID, nil
+// SendMessageToUser returns ids of sent messages, album is sent as several messages.
+// On error ids of already sent part of publication are returned too
+func (t *TelegramMsg) SendMessageToUser(chatID int64, publication *entity.Publication) ([]int, error) {
+	return t.sendPublication(chatID, "", publication)
 }
 
 func (t *TelegramMsg) DeleteMessage(chatID int64, messageID int) error {
-	resp, err := t.bot.Request(tgbotapi.NewDeleteMessage(chatID, messageID))
-	if nil != err || !resp.Ok {
-		t.log.Error("failed to delete message id %d (%s): %v", messageID, string(resp.Result), err)
+	resp, err := t.request(chatID, tgbotapi.NewDeleteMessage(chatID, messageID))
+	if err != nil {
+		t.log.Error("failed to delete message id %d: %v", messageID, err)
+		return err
 	}
-	return err
+	if !resp.Ok {
+		t.log.Error("failed to delete message id %d (%s)", messageID, string(resp.Result))
+	}
+	return nil
 }
 
+// SendMessageToChannel - id канала по username неизвестен, применяется только общий лимит
 func (t *TelegramMsg) SendMessageToChannel(username string, publication *entity.Publication) error {
-	if publication.Image != nil {
-		publicationPhoto := tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(*publication.Image))
-		msg := tgbotapi.NewPhotoToChannel(username, publicationPhoto.Media)
-		msg.ParseMode = tgbotapi.ModeMarkdownV2
-		buttonMarkup := buttonQualifier(publication.ButtonUrl, publication.ButtonText)
-		if buttonMarkup != nil {
-			msg.ReplyMarkup = &buttonMarkup
-		}
-		if publication.Text != "" {
-			msg.Caption = publication.Text
-		}
+	_, err := t.sendPublication(0, username, publication)
+	return err
+}
 
-		if _, err := t.bot.Send(msg); err != nil {
-			t.log.Error("failed to send message: %v", err)
-			return err
-		}
+func buttonQualifier(buttons entity.Buttons) *tgbotapi.InlineKeyboardMarkup {
+	if buttons.Count() == 0 {
 		return nil
 	}
 
-	msg := tgbotapi.NewMessageToChannel(username, "")
-	msg.DisableWebPagePreview = true
-	msg.ParseMode = tgbotapi.ModeMarkdownV2
-	buttonMarkup := buttonQualifier(publication.ButtonUrl, publication.ButtonText)
-	if buttonMarkup != nil {
-		msg.ReplyMarkup = &buttonMarkup
-	}
-	if publication.Text != "" {
-		msg.Text = publication.Text
-	}
-
-	if _, err := t.bot.Send(msg); err != nil {
-		t.log.Error("failed to send message", err)
-		return err
-	}
-
-	return nil
-}
-
-func buttonQualifier(buttonURL *string, buttonText *string) *tgbotapi.InlineKeyboardMarkup {
-	if buttonURL != nil && buttonText != nil {
-		var (
-			btnText string
-			btnURL  string
-		)
-
-		btnText = *buttonText
-		btnURL = *buttonURL
-
-		button := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonURL(btnText, btnURL)),
-		)
-		return &button
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(buttons))
+	for _, row := range buttons {
+		if len(row) == 0 {
+			continue
+		}
+		keyboardRow := make([]tgbotapi.InlineKeyboardButton, 0, len(row))
+		for _, button := range row {
+			keyboardRow = append(keyboardRow, tgbotapi.NewInlineKeyboardButtonURL(button.Text, button.URL))
+		}
+		rows = append(rows, keyboardRow)
 	}
-	return nil
-}
 
-func escapeSpecialCharacters(text string) string {
-	specialChars := []string{"<", ">", "[", "]", "(", ")", "~", "`", "#", "+", "-", "=", "|", "{", "}", ".", "!"}
-	for _, char := range specialChars {
-		text = strings.ReplaceAll(text, char, `\`+char)
-	}
-	return text
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &markup
 }
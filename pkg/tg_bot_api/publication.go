@@ -0,0 +1,295 @@
+package tg_bot_api
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Публикации отправляются запросами с параметрами Bot API напрямую: конфиги библиотеки
+// не поддерживают protect_content, has_spoiler и link_preview_options
+
+// mediaMethods - метод отправки для каждого типа вложения, имя параметра файла совпадает с типом
+var mediaMethods = map[entity.MediaType]string{
+	entity.MediaPhoto:     "sendPhoto",
+	entity.MediaVideo:     "sendVideo",
+	entity.MediaAnimation: "sendAnimation",
+	entity.MediaDocument:  "sendDocument",
+	entity.MediaAudio:     "sendAudio",
+	entity.MediaVoice:     "sendVoice",
+	entity.MediaVideoNote: "sendVideoNote",
+}
+
+// spoilerAllowed - telegram скрывает под спойлером только фото, видео и GIF
+func spoilerAllowed(mediaType entity.MediaType) bool {
+	return mediaType == entity.MediaPhoto || mediaType == entity.MediaVideo || mediaType == entity.MediaAnimation
+}
+
+// sendPublication dispatches on type of publication media, returns ids of all sent messages
+func (t *TelegramMsg) sendPublication(chatID int64, username string, publication *entity.Publication) ([]int, error) {
+	keyboard := buttonQualifier(publication.Buttons)
+	if t.storageChatID != 0 && len(publication.StorageMessageIDs) > 0 && copyKeepsOptions(publication.Options) {
+		return t.copyPublication(chatID, username, publication, keyboard)
+	}
+
+	if publication.IsAlbum() {
+		return t.sendAlbum(chatID, username, publication, keyboard)
+	}
+
+	chunks := textChunks(publication, publication.TextLimit())
+	if len(publication.Media) == 0 {
+		return t.sendTexts(chatID, username, publication.Options, nil, chunks, keyboard)
+	}
+
+	media := publication.Media[0]
+	if media.Type.HasCaption() || publication.Text == "" {
+		// остаток разделенного текста отправляется следом, кнопки под последним сообщением
+		mediaKeyboard := keyboard
+		if len(chunks) > 1 {
+			mediaKeyboard = nil
+		}
+		msgID, err := t.sendMedia(chatID, username, publication.Options, media, chunks[0], mediaKeyboard)
+		if err != nil {
+			return nil, err
+		}
+		return t.sendTexts(chatID, username, publication.Options, []int{msgID}, chunks[1:], keyboard)
+	}
+
+	// у видеосообщения нет подписи, текст с кнопкой отправляется следом
+	msgID, err := t.sendMedia(chatID, username, publication.Options, media, richText{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return t.sendTexts(chatID, username, publication.Options, []int{msgID}, chunks, keyboard)
+}
+
+// sendTexts sends chunks of text after already sent messages, keyboard is attached to the last chunk.
+// On error returns ids of messages sent before it: publication without tail and buttons is not sent
+func (t *TelegramMsg) sendTexts(chatID int64, username string, options entity.DeliveryOptions, msgIDs []int, chunks []richText, keyboard *tgbotapi.InlineKeyboardMarkup) ([]int, error) {
+	for i, chunk := range chunks {
+		chunkOptions, chunkKeyboard := options, keyboard
+		if i > 0 {
+			// превью ссылки только у первой части текста
+			chunkOptions.Preview = entity.LinkPreview{}
+		}
+		if i < len(chunks)-1 {
+			chunkKeyboard = nil
+		}
+
+		msgID, err := t.sendText(chatID, username, chunkOptions, chunk, chunkKeyboard)
+		if err != nil {
+			return msgIDs, err
+		}
+		msgIDs = append(msgIDs, msgID)
+	}
+	return msgIDs, nil
+}
+
+func (t *TelegramMsg) sendText(chatID int64, username string, options entity.DeliveryOptions, text richText, keyboard *tgbotapi.InlineKeyboardMarkup) (int, error) {
+	params, err := publicationParams(chatID, username, options, keyboard)
+	if err != nil {
+		return 0, err
+	}
+	params["text"] = text.Text
+	if err = text.addTo(params, "parse_mode", "entities"); err != nil {
+		return 0, err
+	}
+	if err = params.AddInterface("link_preview_options", linkPreviewOptions(options.Preview)); err != nil {
+		return 0, err
+	}
+
+	var msg tgbotapi.Message
+	if err = t.makeRequest(chatID, "sendMessage", params, &msg); err != nil {
+		t.log.Error("failed to send message: %v", err)
+		return 0, err
+	}
+	return msg.MessageID, nil
+}
+
+func (t *TelegramMsg) sendMedia(chatID int64, username string, options entity.DeliveryOptions, media entity.Media, caption richText, keyboard *tgbotapi.InlineKeyboardMarkup) (int, error) {
+	method, ok := mediaMethods[media.Type]
+	if !ok {
+		method, media.Type = mediaMethods[entity.MediaPhoto], entity.MediaPhoto
+	}
+
+	params, err := publicationParams(chatID, username, options, keyboard)
+	if err != nil {
+		return 0, err
+	}
+	params[string(media.Type)] = media.FileID
+	if media.Type.HasCaption() && caption.Text != "" {
+		params["caption"] = caption.Text
+		if err = caption.addTo(params, "parse_mode", "caption_entities"); err != nil {
+			return 0, err
+		}
+	}
+	params.AddBool("has_spoiler", options.Spoiler && spoilerAllowed(media.Type))
+
+	var msg tgbotapi.Message
+	if err = t.makeRequest(chatID, method, params, &msg); err != nil {
+		t.log.Error("failed to send %s: %v", media.Type, err)
+		return 0, err
+	}
+	return msg.MessageID, nil
+}
+
+// albumButtonsText - текст сообщения с кнопками для альбома без текста: telegram не отправляет
+// сообщение без текста, а подпись кнопки в тексте повторяет саму кнопку. Стрелка указывает на альбом,
+// к которому относятся кнопки, и не зависит от языка публикации
+const albumButtonsText = "⬆️"
+
+// albumButtonsChunks returns text sent with buttons after album, albumButtonsText if publication has no text
+func albumButtonsChunks(chunks []richText) []richText {
+	if chunks[0].Text == "" {
+		return []richText{{Text: albumButtonsText}}
+	}
+	return chunks
+}
+
+// sendAlbum - у медиагруппы не может быть кнопок, поэтому при наличии кнопок
+// текст публикации с кнопками отправляется отдельным сообщением после альбома
+func (t *TelegramMsg) sendAlbum(chatID int64, username string, publication *entity.Publication, keyboard *tgbotapi.InlineKeyboardMarkup) ([]int, error) {
+	chunks := textChunks(publication, publication.TextLimit())
+	caption, rest := chunks[0], chunks[1:]
+	if keyboard != nil {
+		caption, rest = richText{}, albumButtonsChunks(chunks)
+	}
+
+	params, err := publicationParams(chatID, username, publication.Options, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = params.AddInterface("media", albumMedia(publication.Media, caption, publication.Options.Spoiler)); err != nil {
+		return nil, err
+	}
+
+	var sentMsgs []tgbotapi.Message
+	if err = t.makeRequest(chatID, "sendMediaGroup", params, &sentMsgs); err != nil {
+		t.log.Error("failed to send media group: %v", err)
+		return nil, err
+	}
+
+	msgIDs := make([]int, 0, len(sentMsgs)+len(rest))
+	for _, sentMsg := range sentMsgs {
+		msgIDs = append(msgIDs, sentMsg.MessageID)
+	}
+	return t.sendTexts(chatID, username, publication.Options, msgIDs, rest, keyboard)
+}
+
+// makeRequest waits for rate limiter and decodes result of request into result
+func (t *TelegramMsg) makeRequest(chatID int64, method string, params tgbotapi.Params, result interface{}) error {
+	if err := t.limiter.Wait(context.Background(), chatID, t.priority); err != nil {
+		return err
+	}
+
+	resp, err := t.bot.MakeRequest(method, params)
+	t.pauseOnFlood(chatID, err)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+// publicationParams - получатель и параметры доставки, общие для всех сообщений публикации
+func publicationParams(chatID int64, username string, options entity.DeliveryOptions, keyboard *tgbotapi.InlineKeyboardMarkup) (tgbotapi.Params, error) {
+	params := make(tgbotapi.Params)
+	if err := params.AddFirstValid("chat_id", chatID, username); err != nil {
+		return nil, err
+	}
+	params.AddBool("disable_notification", options.Silent)
+	params.AddBool("protect_content", options.Protected)
+	if keyboard != nil {
+		if err := params.AddInterface("reply_markup", keyboard); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
+
+type linkPreview struct {
+	IsDisabled       bool   `json:"is_disabled,omitempty"`
+	URL              string `json:"url,omitempty"`
+	PreferSmallMedia bool   `json:"prefer_small_media,omitempty"`
+	PreferLargeMedia bool   `json:"prefer_large_media,omitempty"`
+	ShowAboveText    bool   `json:"show_above_text,omitempty"`
+}
+
+func linkPreviewOptions(preview entity.LinkPreview) linkPreview {
+	if !preview.Enabled {
+		return linkPreview{IsDisabled: true}
+	}
+	return linkPreview{
+		URL:              preview.URL,
+		PreferSmallMedia: preview.Size == entity.PreviewSizeSmall,
+		PreferLargeMedia: preview.Size == entity.PreviewSizeLarge,
+		ShowAboveText:    preview.AboveText,
+	}
+}
+
+// richText - текст публикации с entities, либо с разметкой для публикаций, созданных до хранения entities
+type richText struct {
+	Text      string
+	Entities  []entity.MessageEntity
+	ParseMode string
+}
+
+// textChunks - при включенном разделении первая часть текста ограничена лимитом первого сообщения,
+// остальные отправляются отдельными сообщениями
+func textChunks(publication *entity.Publication, firstLimit int) []richText {
+	text := publicationText(publication)
+	if !publication.Options.Split {
+		return []richText{text}
+	}
+	return splitText(text, firstLimit, entity.MaxTextLength)
+}
+
+func publicationText(publication *entity.Publication) richText {
+	return richText{
+		Text:      publication.Text,
+		Entities:  publication.Entities,
+		ParseMode: publication.ParseMode,
+	}
+}
+
+// addTo sets parse mode for legacy text, otherwise entities of text
+func (r richText) addTo(params tgbotapi.Params, parseModeKey, entitiesKey string) error {
+	if r.ParseMode != "" {
+		params[parseModeKey] = r.ParseMode
+		return nil
+	}
+	if len(r.Entities) == 0 {
+		return nil
+	}
+	return params.AddInterface(entitiesKey, r.Entities)
+}
+
+type inputMedia struct {
+	Type            entity.MediaType       `json:"type"`
+	Media           string                 `json:"media"`
+	Caption         string                 `json:"caption,omitempty"`
+	ParseMode       string                 `json:"parse_mode,omitempty"`
+	CaptionEntities []entity.MessageEntity `json:"caption_entities,omitempty"`
+	HasSpoiler      bool                   `json:"has_spoiler,omitempty"`
+}
+
+// albumMedia - подпись альбома задается у первого файла. Telegram группирует фото с видео,
+// документы и аудио только между собой, поэтому альбом администратора уже допустимого состава
+func albumMedia(media []entity.Media, caption richText, spoiler bool) []inputMedia {
+	files := make([]inputMedia, 0, len(media))
+	for i, m := range media {
+		file := inputMedia{
+			Type:       m.Type,
+			Media:      m.FileID,
+			HasSpoiler: spoiler && spoilerAllowed(m.Type),
+		}
+		if i == 0 && caption.Text != "" {
+			file.Caption, file.ParseMode = caption.Text, caption.ParseMode
+			if caption.ParseMode == "" {
+				file.CaptionEntities = caption.Entities
+			}
+		}
+		files = append(files, file)
+	}
+	return files
+}
@@ -0,0 +1,172 @@
+package tg_bot_api
+
+import (
+	"context"
+	"fmt"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"github.com/Enthreeka/tg-posting-bot/pkg/logger"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeRequest struct {
+	method string
+	params url.Values
+}
+
+// fakeTelegram - HTTP клиент бота, записывает запросы к Bot API. На запрос с номером из failed (с 1)
+// отвечает ошибкой, на остальные - сообщением с message_id, равным номеру запроса
+type fakeTelegram struct {
+	requests []fakeRequest
+	failed   map[int]bool
+}
+
+func (f *fakeTelegram) Do(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	params, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+	f.requests = append(f.requests, fakeRequest{method: path.Base(req.URL.Path), params: params})
+
+	n := len(f.requests)
+	resp := fmt.Sprintf(`{"ok":true,"result":{"message_id":%d}}`, n)
+	if f.failed[n] {
+		resp = `{"ok":false,"error_code":400,"description":"Bad Request: test"}`
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(resp))}, nil
+}
+
+type noLimiter struct{}
+
+func (noLimiter) Wait(context.Context, int64, Priority) error { return nil }
+func (noLimiter) Pause(int64, time.Duration)                  {}
+
+func newTestMsg(client *fakeTelegram, storageChatID int64) *TelegramMsg {
+	bot := &tgbotapi.BotAPI{Token: "token", Client: client}
+	bot.SetAPIEndpoint(tgbotapi.APIEndpoint)
+	return NewMessageSetting(bot, logger.New(), noLimiter{}, storageChatID)
+}
+
+func TestLinkPreviewOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		preview entity.LinkPreview
+		want    linkPreview
+	}{
+		{name: "disabled by default", want: linkPreview{IsDisabled: true}},
+		{name: "enabled", preview: entity.LinkPreview{Enabled: true}, want: linkPreview{}},
+		{
+			name:    "large above text",
+			preview: entity.LinkPreview{Enabled: true, URL: "https://example.com", AboveText: true, Size: entity.PreviewSizeLarge},
+			want:    linkPreview{URL: "https://example.com", PreferLargeMedia: true, ShowAboveText: true},
+		},
+		{
+			name:    "small",
+			preview: entity.LinkPreview{Enabled: true, Size: entity.PreviewSizeSmall},
+			want:    linkPreview{PreferSmallMedia: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linkPreviewOptions(tt.preview); got != tt.want {
+				t.Errorf("linkPreviewOptions() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlbumMedia(t *testing.T) {
+	media := []entity.Media{
+		{Type: entity.MediaPhoto, FileID: "photo"},
+		{Type: entity.MediaVideo, FileID: "video"},
+		{Type: entity.MediaDocument, FileID: "document"},
+	}
+
+	caption := richText{Text: "caption", Entities: []entity.MessageEntity{{Type: "bold", Offset: 0, Length: 7}}}
+	files := albumMedia(media, caption, true)
+	if len(files) != len(media) {
+		t.Fatalf("albumMedia() returned %d files; want %d", len(files), len(media))
+	}
+	if files[0].Caption != "caption" || files[1].Caption != "" {
+		t.Errorf("caption must be set only for first file: %+v", files)
+	}
+	if len(files[0].CaptionEntities) != 1 || files[0].ParseMode != "" {
+		t.Errorf("caption must be sent with entities without parse mode: %+v", files[0])
+	}
+	if !files[0].HasSpoiler || !files[1].HasSpoiler || files[2].HasSpoiler {
+		t.Errorf("spoiler must be set only for photo and video: %+v", files)
+	}
+}
+
+func TestAlbumButtonsChunks(t *testing.T) {
+	if got := albumButtonsChunks([]richText{{}}); len(got) != 1 || got[0].Text != albumButtonsText {
+		t.Errorf("album without text must be sent with placeholder: %+v", got)
+	}
+
+	chunks := []richText{{Text: "text"}, {Text: "rest"}}
+	if got := albumButtonsChunks(chunks); len(got) != 2 || got[0].Text != "text" {
+		t.Errorf("text of album must be kept: %+v", got)
+	}
+}
+
+func TestSendStoredPublicationWithSpoiler(t *testing.T) {
+	client := &fakeTelegram{}
+	msg := newTestMsg(client, -100500)
+
+	publication := &entity.Publication{
+		Text:              "caption",
+		Media:             []entity.Media{{Type: entity.MediaPhoto, FileID: "photo"}},
+		StorageMessageIDs: []int64{7},
+		Options:           entity.DeliveryOptions{Spoiler: true},
+	}
+	if _, err := msg.sendPublication(-100100, "", publication); err != nil {
+		t.Fatalf("sendPublication() error = %v", err)
+	}
+
+	if len(client.requests) != 1 {
+		t.Fatalf("sent %d requests; want 1", len(client.requests))
+	}
+	req := client.requests[0]
+	if req.method != "sendPhoto" || req.params.Get("has_spoiler") != "true" || req.params.Get("photo") != "photo" {
+		t.Errorf("publication with spoiler must be sent from fields: %s %v", req.method, req.params)
+	}
+
+	// без спойлера и превью сохраненный черновик копируется
+	client.requests = nil
+	publication.Options.Spoiler = false
+	if _, err := msg.sendPublication(-100100, "", publication); err != nil {
+		t.Fatalf("sendPublication() error = %v", err)
+	}
+	if req := client.requests[0]; req.method != "copyMessage" || req.params.Get("message_id") != "7" {
+		t.Errorf("stored publication must be copied: %s %v", req.method, req.params)
+	}
+}
+
+func TestSendTextsChunkFails(t *testing.T) {
+	client := &fakeTelegram{failed: map[int]bool{2: true}}
+	msg := newTestMsg(client, 0)
+
+	keyboard := buttonQualifier(entity.Buttons{{{Text: "button", URL: "https://example.com"}}})
+	chunks := []richText{{Text: "first"}, {Text: "second"}, {Text: "third"}}
+	msgIDs, err := msg.sendTexts(-100100, "", entity.DeliveryOptions{}, []int{10}, chunks, keyboard)
+	if err == nil {
+		t.Fatal("sendTexts() must return error of failed chunk")
+	}
+	if len(msgIDs) != 2 || msgIDs[0] != 10 || msgIDs[1] != 1 {
+		t.Errorf("sendTexts() = %v; want ids of messages sent before error [10 1]", msgIDs)
+	}
+	if len(client.requests) != 2 {
+		t.Errorf("sent %d requests; want sending to stop after failed chunk", len(client.requests))
+	}
+}
@@ -0,0 +1,65 @@
+package tg_bot_api
+
+import (
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"unicode/utf16"
+)
+
+// splitText splits text into chunks by telegram length: first chunk is limited by first, others by rest.
+// Chunk is cut by last line break or space in second half of limit, entities are cut by chunks.
+// Text with parse mode is not split, markup could be broken
+func splitText(text richText, first, rest int) []richText {
+	units := utf16.Encode([]rune(text.Text))
+	if text.ParseMode != "" || len(units) <= first {
+		return []richText{text}
+	}
+
+	var chunks []richText
+	limit := first
+	for start := 0; start < len(units); limit = rest {
+		end, next := cutPosition(units, start, limit)
+		chunks = append(chunks, richText{
+			Text:     string(utf16.Decode(units[start:end])),
+			Entities: cutEntities(text.Entities, start, end),
+		})
+		start = next
+	}
+	return chunks
+}
+
+// cutPosition returns end of chunk which begins at start and beginning of next chunk
+func cutPosition(units []uint16, start, limit int) (int, int) {
+	if len(units)-start <= limit {
+		return len(units), len(units)
+	}
+
+	end := start + limit
+	for _, separator := range []uint16{'\n', ' '} {
+		for i := end - 1; i > start+limit/2; i-- {
+			if units[i] == separator {
+				// разделитель не переносится в начало следующей части
+				return i, i + 1
+			}
+		}
+	}
+
+	// суррогатная пара не разрывается
+	if units[end-1] >= 0xd800 && units[end-1] < 0xdc00 {
+		end--
+	}
+	return end, end
+}
+
+// cutEntities returns part of entities inside [start, end), offsets are counted from start
+func cutEntities(entities []entity.MessageEntity, start, end int) []entity.MessageEntity {
+	var cut []entity.MessageEntity
+	for _, e := range entities {
+		from, to := max(e.Offset, start), min(e.Offset+e.Length, end)
+		if from >= to {
+			continue
+		}
+		e.Offset, e.Length = from-start, to-from
+		cut = append(cut, e)
+	}
+	return cut
+}
@@ -0,0 +1,86 @@
+package tg_bot_api
+
+import (
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestSplitTextShort(t *testing.T) {
+	text := richText{Text: "short text"}
+	chunks := splitText(text, 1024, 4096)
+	if len(chunks) != 1 || chunks[0].Text != text.Text {
+		t.Fatalf("splitText() = %+v; want text as is", chunks)
+	}
+}
+
+func TestSplitTextByLimits(t *testing.T) {
+	words := strings.Repeat("word ", 50)
+	chunks := splitText(richText{Text: words}, 20, 100)
+	if len(chunks) < 3 {
+		t.Fatalf("splitText() returned %d chunks; want at least 3", len(chunks))
+	}
+	if len(chunks[0].Text) > 20 {
+		t.Errorf("first chunk %q is longer than 20", chunks[0].Text)
+	}
+
+	var joined []string
+	for _, chunk := range chunks[1:] {
+		if len(chunk.Text) > 100 {
+			t.Errorf("chunk %q is longer than 100", chunk.Text)
+		}
+	}
+	for _, chunk := range chunks {
+		joined = append(joined, chunk.Text)
+	}
+	if got := strings.Join(joined, " "); got != words {
+		t.Errorf("joined chunks = %q; want %q", got, words)
+	}
+}
+
+func TestSplitTextPrefersLineBreak(t *testing.T) {
+	chunks := splitText(richText{Text: "first line\nsecond line"}, 15, 15)
+	if len(chunks) != 2 || chunks[0].Text != "first line" || chunks[1].Text != "second line" {
+		t.Fatalf("splitText() = %+v; want split by line break", chunks)
+	}
+}
+
+func TestSplitTextKeepsSurrogatePairs(t *testing.T) {
+	text := strings.Repeat("😀", 10)
+	chunks := splitText(richText{Text: text}, 5, 5)
+	for _, chunk := range chunks {
+		if strings.ContainsRune(chunk.Text, '�') {
+			t.Fatalf("chunk %q contains broken surrogate pair", chunk.Text)
+		}
+		if n := len(utf16.Encode([]rune(chunk.Text))); n > 5 {
+			t.Errorf("chunk %q has %d UTF-16 units; want at most 5", chunk.Text, n)
+		}
+	}
+}
+
+func TestSplitTextCutsEntities(t *testing.T) {
+	text := richText{
+		Text:     "aaaa bbbb",
+		Entities: []entity.MessageEntity{{Type: "bold", Offset: 2, Length: 5}},
+	}
+	chunks := splitText(text, 5, 5)
+	if len(chunks) != 2 {
+		t.Fatalf("splitText() returned %d chunks; want 2", len(chunks))
+	}
+
+	first, second := chunks[0].Entities, chunks[1].Entities
+	if len(first) != 1 || first[0].Offset != 2 || first[0].Length != 2 {
+		t.Errorf("entities of first chunk = %+v; want bold at 2 with length 2", first)
+	}
+	if len(second) != 1 || second[0].Offset != 0 || second[0].Length != 2 {
+		t.Errorf("entities of second chunk = %+v; want bold at 0 with length 2", second)
+	}
+}
+
+func TestSplitTextKeepsParseMode(t *testing.T) {
+	text := richText{Text: strings.Repeat("a", 50), ParseMode: "MarkdownV2"}
+	if chunks := splitText(text, 10, 10); len(chunks) != 1 {
+		t.Fatalf("splitText() returned %d chunks; text with parse mode must not be split", len(chunks))
+	}
+}
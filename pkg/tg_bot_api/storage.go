@@ -0,0 +1,111 @@
+package tg_bot_api
+
+import (
+	"errors"
+	"github.com/Enthreeka/tg-posting-bot/internal/entity"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Черновик публикации хранится сообщением в приватном канале-хранилище и публикуется копией:
+// telegram сохраняет любое вложение и форматирование, а file_id не зависят от токена бота.
+// Спойлер и превью ссылки копия берет из исходного сообщения, поэтому публикация с ними отправляется из полей
+
+var ErrStorageDisabled = errors.New("storage channel is not configured")
+
+// StoreMessage copies message of admin into storage channel, returns id of stored message
+func (t *TelegramMsg) StoreMessage(fromChatID int64, messageID int) (int, error) {
+	if t.storageChatID == 0 {
+		return 0, ErrStorageDisabled
+	}
+
+	params := make(tgbotapi.Params)
+	params.AddNonZero64("chat_id", t.storageChatID)
+	params.AddNonZero64("from_chat_id", fromChatID)
+	params.AddNonZero("message_id", messageID)
+
+	var stored tgbotapi.MessageID
+	if err := t.makeRequest(t.storageChatID, "copyMessage", params, &stored); err != nil {
+		t.log.Error("failed to store message %d: %v", messageID, err)
+		return 0, err
+	}
+	return stored.MessageID, nil
+}
+
+// StoreMessages copies album of admin into storage channel with one request, so stored copies stay grouped.
+// Message ids must be in increasing order, returns ids of stored messages in the same order
+func (t *TelegramMsg) StoreMessages(fromChatID int64, messageIDs []int) ([]int, error) {
+	if t.storageChatID == 0 {
+		return nil, ErrStorageDisabled
+	}
+
+	params := make(tgbotapi.Params)
+	params.AddNonZero64("chat_id", t.storageChatID)
+	params.AddNonZero64("from_chat_id", fromChatID)
+	if err := params.AddInterface("message_ids", messageIDs); err != nil {
+		return nil, err
+	}
+
+	var stored []tgbotapi.MessageID
+	if err := t.makeRequest(t.storageChatID, "copyMessages", params, &stored); err != nil {
+		t.log.Error("failed to store messages %v: %v", messageIDs, err)
+		return nil, err
+	}
+
+	storedIDs := make([]int, 0, len(stored))
+	for _, s := range stored {
+		storedIDs = append(storedIDs, s.MessageID)
+	}
+	return storedIDs, nil
+}
+
+// copyKeepsOptions - копирование не принимает has_spoiler и link_preview_options,
+// копией отправляется только публикация без спойлера и превью
+func copyKeepsOptions(options entity.DeliveryOptions) bool {
+	return !options.Spoiler && !options.Preview.Enabled
+}
+
+// copyPublication - альбом копируется одним запросом copyMessages, кнопки к нему
+// отправляются следом отдельным сообщением, как и при отправке альбома по полям
+func (t *TelegramMsg) copyPublication(chatID int64, username string, publication *entity.Publication, keyboard *tgbotapi.InlineKeyboardMarkup) ([]int, error) {
+	if len(publication.StorageMessageIDs) == 1 {
+		params, err := publicationParams(chatID, username, publication.Options, keyboard)
+		if err != nil {
+			return nil, err
+		}
+		params.AddNonZero64("from_chat_id", t.storageChatID)
+		params.AddNonZero64("message_id", publication.StorageMessageIDs[0])
+
+		var copied tgbotapi.MessageID
+		if err = t.makeRequest(chatID, "copyMessage", params, &copied); err != nil {
+			t.log.Error("failed to copy message: %v", err)
+			return nil, err
+		}
+		return []int{copied.MessageID}, nil
+	}
+
+	params, err := publicationParams(chatID, username, publication.Options, nil)
+	if err != nil {
+		return nil, err
+	}
+	params.AddNonZero64("from_chat_id", t.storageChatID)
+	params.AddBool("remove_caption", keyboard != nil)
+	if err = params.AddInterface("message_ids", publication.StorageMessageIDs); err != nil {
+		return nil, err
+	}
+
+	var copied []tgbotapi.MessageID
+	if err = t.makeRequest(chatID, "copyMessages", params, &copied); err != nil {
+		t.log.Error("failed to copy messages: %v", err)
+		return nil, err
+	}
+
+	msgIDs := make([]int, 0, len(copied)+1)
+	for _, c := range copied {
+		msgIDs = append(msgIDs, c.MessageID)
+	}
+	if keyboard == nil {
+		return msgIDs, nil
+	}
+
+	return t.sendTexts(chatID, username, publication.Options, msgIDs, albumButtonsChunks(textChunks(publication, entity.MaxTextLength)), keyboard)
+}
@@ -0,0 +1,78 @@
+package ttl
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+var units = map[byte]time.Duration{
+	'w': Week,
+	'd': Day,
+	'h': time.Hour,
+	'm': time.Minute,
+}
+
+var ErrEmpty = errors.New("ttl is empty")
+
+// Parse parses duration like "24h", "1d12h", "2w", "90m". Units: w - week, d - day, h - hour, m - minute.
+func Parse(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+	if s == "" {
+		return 0, ErrEmpty
+	}
+
+	var total time.Duration
+	for s != "" {
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(s) {
+			return 0, fmt.Errorf("invalid ttl %q: expected number with unit", s)
+		}
+
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid ttl number %q: %w", s[:i], err)
+		}
+		unit, ok := units[s[i]]
+		if !ok {
+			return 0, fmt.Errorf("invalid ttl unit %q", s[i])
+		}
+
+		total += time.Duration(n) * unit
+		s = s[i+1:]
+	}
+
+	if total <= 0 {
+		return 0, ErrEmpty
+	}
+	return total, nil
+}
+
+// Format formats duration in the same units as Parse accepts, for example 36h - "1d12h"
+func Format(d time.Duration) string {
+	if d < time.Minute {
+		return "0m"
+	}
+
+	var b strings.Builder
+	for _, u := range []struct {
+		name  string
+		value time.Duration
+	}{{"d", Day}, {"h", time.Hour}, {"m", time.Minute}} {
+		if n := d / u.value; n > 0 {
+			b.WriteString(strconv.FormatInt(int64(n), 10) + u.name)
+			d -= n * u.value
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package ttl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{in: "24h", want: 24 * time.Hour},
+		{in: "1d12h", want: 36 * time.Hour},
+		{in: "2w", want: 14 * Day},
+		{in: " 1D 30M ", want: Day + 30*time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+		if back, err := Parse(Format(got)); err != nil || back != got {
+			t.Errorf("Format(%v) = %q is not parsed back", got, Format(got))
+		}
+	}
+
+	for _, in := range []string{"", "24", "h", "1y", "0h", "2024-08-27 15:48"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) must fail", in)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ParseSlots parses daily slot grid "09:00, 13:00, 18:00, 21:00" into sorted minutes of day
+func ParseSlots(spec string) ([]int, error) {
+	slots := make([]int, 0)
+	for _, part := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == ';' || r == ' ' || r == '\n' }) {
+		slot, err := parseClock(part)
+		if err != nil {
+			return nil, err
+		}
+		if slot == 24*60 {
+			return nil, fmt.Errorf("invalid time %q", part)
+		}
+		slots = append(slots, slot)
+	}
+	if len(slots) == 0 {
+		return nil, errors.New("no slots")
+	}
+
+	slices.Sort(slots)
+	return slices.Compact(slots), nil
+}
+
+// FormatSlots formats slots in the same form as ParseSlots accepts
+func FormatSlots(slots []int) string {
+	parts := make([]string, 0, len(slots))
+	for _, slot := range slots {
+		parts = append(parts, fmt.Sprintf("%02d:%02d", slot/60, slot%60))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// NextSlot returns first slot strictly after t, slots are minutes of day in loc
+func NextSlot(slots []int, loc *time.Location, t time.Time) time.Time {
+	local := t.In(loc)
+	for day := 0; ; day++ {
+		for _, slot := range slots {
+			candidate := time.Date(local.Year(), local.Month(), local.Day()+day, 0, slot, 0, 0, loc)
+			if candidate.After(t) {
+				return candidate
+			}
+		}
+	}
+}
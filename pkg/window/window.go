@@ -0,0 +1,248 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchLimit - max period in which nearest allowed time is searched
+const searchLimit = 366 * 24 * time.Hour
+
+var ErrNoSlot = errors.New("no allowed time for posting")
+
+var weekdays = map[string]time.Weekday{
+	"пн": time.Monday, "mo": time.Monday, "mon": time.Monday,
+	"вт": time.Tuesday, "tu": time.Tuesday, "tue": time.Tuesday,
+	"ср": time.Wednesday, "we": time.Wednesday, "wed": time.Wednesday,
+	"чт": time.Thursday, "th": time.Thursday, "thu": time.Thursday,
+	"пт": time.Friday, "fr": time.Friday, "fri": time.Friday,
+	"сб": time.Saturday, "sa": time.Saturday, "sat": time.Saturday,
+	"вс": time.Sunday, "su": time.Sunday, "sun": time.Sunday,
+}
+
+var weekdayNames = [7]string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"}
+
+// Window - allowed posting interval [From, To) on given weekdays, minutes of day in local time of channel
+type Window struct {
+	Weekdays [7]bool
+	From     int
+	To       int
+}
+
+// Blackout - period [From, To) when nothing can be posted
+type Blackout struct {
+	From time.Time
+	To   time.Time
+}
+
+// Schedule checks posting time against windows and blackouts, empty windows allow any time
+type Schedule struct {
+	windows   []Window
+	blackouts []Blackout
+	loc       *time.Location
+}
+
+func New(windows []Window, blackouts []Blackout, loc *time.Location) *Schedule {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Schedule{
+		windows:   windows,
+		blackouts: blackouts,
+		loc:       loc,
+	}
+}
+
+// Parse parses windows separated by ";" or new lines: "пн-пт 08:00-23:00; сб,вс 10:00-22:00".
+// Days are set by ranges and lists, russian and english names are accepted.
+func Parse(spec string) ([]Window, error) {
+	windows := make([]Window, 0)
+	for _, part := range strings.FieldsFunc(spec, func(r rune) bool { return r == ';' || r == '\n' }) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		days, hours, ok := strings.Cut(part, " ")
+		if !ok {
+			return nil, fmt.Errorf("window %q: expected days and time", part)
+		}
+
+		var (
+			w   Window
+			err error
+		)
+		if w.Weekdays, err = parseDays(strings.ToLower(days)); err != nil {
+			return nil, fmt.Errorf("window %q: %w", part, err)
+		}
+		if w.From, w.To, err = parseHours(strings.TrimSpace(hours)); err != nil {
+			return nil, fmt.Errorf("window %q: %w", part, err)
+		}
+		windows = append(windows, w)
+	}
+
+	if len(windows) == 0 {
+		return nil, errors.New("no windows")
+	}
+	return windows, nil
+}
+
+func parseDays(value string) ([7]bool, error) {
+	var days [7]bool
+	for _, part := range strings.Split(value, ",") {
+		low, high, isRange := strings.Cut(part, "-")
+		from, ok := weekdays[low]
+		if !ok {
+			return days, fmt.Errorf("invalid day %q", low)
+		}
+		to := from
+		if isRange {
+			if to, ok = weekdays[high]; !ok {
+				return days, fmt.Errorf("invalid day %q", high)
+			}
+		}
+
+		// диапазон может переходить через воскресенье: пт-пн
+		for d := from; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == to {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func parseHours(value string) (int, int, error) {
+	fromPart, toPart, ok := strings.Cut(strings.ReplaceAll(value, " ", ""), "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid time range %q", value)
+	}
+	from, err := parseClock(fromPart)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := parseClock(toPart)
+	if err != nil {
+		return 0, 0, err
+	}
+	if from >= to {
+		return 0, 0, fmt.Errorf("start of window must be before its end: %q", value)
+	}
+	return from, to, nil
+}
+
+// parseClock parses "08:00" into minutes of day, "24:00" is end of day
+func parseClock(value string) (int, error) {
+	hourPart, minutePart, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	hour, err := strconv.Atoi(hourPart)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	minute, err := strconv.Atoi(minutePart)
+	if err != nil || minute < 0 || minute > 59 || hour < 0 || hour > 24 || hour == 24 && minute != 0 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return hour*60 + minute, nil
+}
+
+// Format formats windows in the same form as Parse accepts
+func Format(windows []Window) string {
+	parts := make([]string, 0, len(windows))
+	for _, w := range windows {
+		days := make([]string, 0, 7)
+		// неделя начинается с понедельника
+		for i := 1; i <= 7; i++ {
+			if w.Weekdays[i%7] {
+				days = append(days, weekdayNames[i%7])
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%s %02d:%02d-%02d:%02d",
+			strings.Join(days, ","), w.From/60, w.From%60, w.To/60, w.To%60))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Allowed checks that t is inside of any window and outside of blackouts
+func (s *Schedule) Allowed(t time.Time) bool {
+	if _, ok := s.Blackout(t); ok {
+		return false
+	}
+	start, ok := s.windowStart(t)
+	return ok && start.Equal(t)
+}
+
+// Blackout returns blackout which contains t
+func (s *Schedule) Blackout(t time.Time) (Blackout, bool) {
+	for _, b := range s.blackouts {
+		if !t.Before(b.From) && t.Before(b.To) {
+			return b, true
+		}
+	}
+	return Blackout{}, false
+}
+
+// Next returns nearest allowed time not before t, false if there is no such time during searchLimit
+func (s *Schedule) Next(t time.Time) (time.Time, bool) {
+	end := t.Add(searchLimit)
+	for candidate := t; candidate.Before(end); {
+		if b, ok := s.Blackout(candidate); ok {
+			candidate = b.To
+			continue
+		}
+
+		start, ok := s.windowStart(candidate)
+		if !ok {
+			return time.Time{}, false
+		}
+		if start.Equal(candidate) {
+			return candidate, true
+		}
+		candidate = start
+	}
+	return time.Time{}, false
+}
+
+// windowStart returns first time not before t which is inside of any window
+func (s *Schedule) windowStart(t time.Time) (time.Time, bool) {
+	if len(s.windows) == 0 {
+		return t, true
+	}
+
+	local := t.In(s.loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.loc)
+	// за неделю и один день встречаются все дни недели
+	for i := 0; i <= 7; i++ {
+		var (
+			best  time.Time
+			found bool
+		)
+		for _, w := range s.windows {
+			if !w.Weekdays[day.Weekday()] {
+				continue
+			}
+			from := time.Date(day.Year(), day.Month(), day.Day(), 0, w.From, 0, 0, s.loc)
+			to := time.Date(day.Year(), day.Month(), day.Day(), 0, w.To, 0, 0, s.loc)
+			if !t.Before(to) {
+				continue
+			}
+			if from.Before(t) {
+				from = t
+			}
+			if !found || from.Before(best) {
+				best, found = from, true
+			}
+		}
+		if found {
+			return best, true
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,126 @@
+package window
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	windows, err := Parse("пн-пт 08:00-23:00; сб,вс 10:00-22:00")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	blackouts := []Blackout{{
+		From: time.Date(2024, 5, 9, 0, 0, 0, 0, msk),
+		To:   time.Date(2024, 5, 10, 0, 0, 0, 0, msk),
+	}}
+	s := New(windows, blackouts, msk)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{
+			name: "inside window",
+			t:    time.Date(2024, 5, 6, 12, 0, 0, 0, msk),
+			want: time.Date(2024, 5, 6, 12, 0, 0, 0, msk),
+		},
+		{
+			name: "before window on weekday",
+			t:    time.Date(2024, 5, 6, 7, 30, 0, 0, msk),
+			want: time.Date(2024, 5, 6, 8, 0, 0, 0, msk),
+		},
+		{
+			name: "after window on friday moves to saturday",
+			t:    time.Date(2024, 5, 10, 23, 30, 0, 0, msk),
+			want: time.Date(2024, 5, 11, 10, 0, 0, 0, msk),
+		},
+		{
+			name: "end of window is not allowed",
+			t:    time.Date(2024, 5, 11, 22, 0, 0, 0, msk),
+			want: time.Date(2024, 5, 12, 10, 0, 0, 0, msk),
+		},
+		{
+			name: "blackout moves to next day window",
+			t:    time.Date(2024, 5, 9, 12, 0, 0, 0, msk),
+			want: time.Date(2024, 5, 10, 8, 0, 0, 0, msk),
+		},
+		{
+			name: "other location",
+			t:    time.Date(2024, 5, 6, 4, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 5, 6, 8, 0, 0, 0, msk),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := s.Next(tt.t)
+			if !ok || !got.Equal(tt.want) {
+				t.Fatalf("Next(%v) = %v, %v; want %v", tt.t, got, ok, tt.want)
+			}
+			if s.Allowed(tt.t) != tt.t.Equal(tt.want) {
+				t.Fatalf("Allowed(%v) = %v", tt.t, s.Allowed(tt.t))
+			}
+		})
+	}
+}
+
+func TestNextWithoutWindows(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := New(nil, []Blackout{{From: from, To: from.Add(time.Hour)}}, time.UTC)
+
+	if got, ok := s.Next(from.Add(time.Minute)); !ok || !got.Equal(from.Add(time.Hour)) {
+		t.Fatalf("Next() = %v, %v; want end of blackout", got, ok)
+	}
+	if !s.Allowed(from.Add(2 * time.Hour)) {
+		t.Fatal("time without windows and blackouts must be allowed")
+	}
+}
+
+func TestParse(t *testing.T) {
+	windows, err := Parse("fri-mon 10:00-24:00\nср 09:30-10:00")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got, want := Format(windows), "пн,пт,сб,вс 10:00-24:00; ср 09:30-10:00"; got != want {
+		t.Fatalf("Format() = %q; want %q", got, want)
+	}
+
+	for _, spec := range []string{"", "пн", "пн 10:00", "пн 23:00-08:00", "xx 10:00-11:00", "пн 10:60-11:00"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) must fail", spec)
+		}
+	}
+}
+
+func TestSlots(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	slots, err := ParseSlots("21:00, 09:00 13:00;18:00, 09:00")
+	if err != nil {
+		t.Fatalf("ParseSlots() error = %v", err)
+	}
+	if got, want := FormatSlots(slots), "09:00, 13:00, 18:00, 21:00"; got != want {
+		t.Fatalf("FormatSlots() = %q; want %q", got, want)
+	}
+
+	after := time.Date(2024, 5, 6, 13, 0, 0, 0, msk)
+	for _, want := range []time.Time{
+		time.Date(2024, 5, 6, 18, 0, 0, 0, msk),
+		time.Date(2024, 5, 6, 21, 0, 0, 0, msk),
+		time.Date(2024, 5, 7, 9, 0, 0, 0, msk),
+	} {
+		got := NextSlot(slots, msk, after)
+		if !got.Equal(want) {
+			t.Fatalf("NextSlot(%v) = %v; want %v", after, got, want)
+		}
+		after = got
+	}
+
+	for _, spec := range []string{"", "24:00", "9"} {
+		if _, err := ParseSlots(spec); err == nil {
+			t.Errorf("ParseSlots(%q) must fail", spec)
+		}
+	}
+}